@@ -17,12 +17,14 @@ limitations under the License.
 package main
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	stdnet "net"
 	"os"
 	"reflect"
+	"sync/atomic"
 	"text/template"
 
 	corev1 "k8s.io/api/core/v1"
@@ -35,6 +37,8 @@ type CNIConfigInputs struct {
 	PodCIDRs      []string
 	DefaultRoutes []string
 	Mtu           int
+	// ChainedPlugins are JSON plugin configs appended to the plugin chain
+	ChainedPlugins []string
 }
 
 // ComputeCNIConfigInputs computes the template inputs for CNIConfigWriter
@@ -116,6 +120,9 @@ const cniConfigTemplate = `
 			"portMappings": true
 		}
 	}
+	{{- range $plugin := .ChainedPlugins}},
+	{{ $plugin }}
+	{{- end}}
 	]
 }
 `
@@ -126,11 +133,22 @@ type CNIConfigWriter struct {
 	path       string
 	lastInputs CNIConfigInputs
 	mtu        int
+	// chainedPlugins are the extra plugins configured for the cluster
+	chainedPlugins []string
+	// written is set once the config has been written, it may be read
+	// from any goroutine
+	written atomic.Bool
+}
+
+// Written returns true once the config has been written
+func (c *CNIConfigWriter) Written() bool {
+	return c.written.Load()
 }
 
 // Write will write the config based on
 func (c *CNIConfigWriter) Write(inputs CNIConfigInputs) error {
 	inputs.Mtu = c.mtu
+	inputs.ChainedPlugins = c.chainedPlugins
 	if reflect.DeepEqual(inputs, c.lastInputs) {
 		return nil
 	}
@@ -159,6 +177,7 @@ func (c *CNIConfigWriter) Write(inputs CNIConfigInputs) error {
 
 	// we're safely done now, record the inputs
 	c.lastInputs = inputs
+	c.written.Store(true)
 	return nil
 }
 
@@ -169,3 +188,27 @@ func writeCNIConfig(w io.Writer, rawTemplate string, data CNIConfigInputs) error
 	}
 	return t.Execute(w, &data)
 }
+
+// parseChainedPlugins parses raw, a JSON array of CNI plugin configs, into
+// the individual plugin configs
+func parseChainedPlugins(raw string) ([]string, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	var plugins []map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(raw), &plugins); err != nil {
+		return nil, fmt.Errorf("expected a JSON array of CNI plugin configs: %w", err)
+	}
+	configs := make([]string, 0, len(plugins))
+	for i, plugin := range plugins {
+		if _, ok := plugin["type"]; !ok {
+			return nil, fmt.Errorf("CNI plugin config %d has no type", i)
+		}
+		config, err := json.Marshal(plugin)
+		if err != nil {
+			return nil, err
+		}
+		configs = append(configs, string(config))
+	}
+	return configs, nil
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseChainedPlugins(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "empty",
+			raw:  "",
+			want: nil,
+		},
+		{
+			name: "plugins",
+			raw:  `[{"type": "bandwidth", "capabilities": {"bandwidth": true}}, {"type": "tuning", "sysctl": {"net.core.somaxconn": "1024"}}]`,
+			want: []string{
+				`{"capabilities":{"bandwidth":true},"type":"bandwidth"}`,
+				`{"sysctl":{"net.core.somaxconn":"1024"},"type":"tuning"}`,
+			},
+		},
+		{
+			name: "no plugins",
+			raw:  `[]`,
+			want: []string{},
+		},
+		{
+			name:    "not an array",
+			raw:     `{"type": "bandwidth"}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid JSON",
+			raw:     `[{"type": "bandwidth"`,
+			wantErr: true,
+		},
+		{
+			name:    "plugin without type",
+			raw:     `[{"type": "bandwidth"}, {"capabilities": {"bandwidth": true}}]`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseChainedPlugins(tt.raw)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseChainedPlugins() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseChainedPlugins() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
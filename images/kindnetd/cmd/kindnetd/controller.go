@@ -0,0 +1,377 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// controllerName is the name of the node controller and its work queue
+	controllerName = "kindnet-nodes"
+	// resyncPeriod is how often all nodes are reconciled again, to restore
+	// routes removed by something other than kindnetd
+	resyncPeriod = time.Minute
+)
+
+// NodeController reconciles the routes to the pod subnets of the other
+// nodes, and the CNI config of the current node, as nodes change
+type NodeController struct {
+	nodeLister  corelisters.NodeLister
+	nodesSynced cache.InformerSynced
+	workqueue   workqueue.TypedRateLimitingInterface[string]
+
+	cniConfig *CNIConfigWriter
+	hostIP    string
+	ipFamily  IPFamily
+	// tunnel encapsulates the traffic to other nodes, nil if the traffic is
+	// routed directly
+	tunnel Tunnel
+	// podSubnets are the cluster pod subnets, only routes to destinations
+	// inside them are ever deleted
+	podSubnets []*net.IPNet
+
+	// pending are the nodes that existed when the caches synced and have not
+	// been reconciled successfully yet
+	mu      sync.Mutex
+	pending sets.Set[string]
+	synced  atomic.Bool
+}
+
+// NewNodeController returns a new NodeController for the nodes from
+// nodeInformer, which must be started after this
+func NewNodeController(nodeInformer coreinformers.NodeInformer, cniConfig *CNIConfigWriter, hostIP string, ipFamily IPFamily, podSubnets []string, tunnel Tunnel) (*NodeController, error) {
+	c := &NodeController{
+		nodeLister:  nodeInformer.Lister(),
+		nodesSynced: nodeInformer.Informer().HasSynced,
+		workqueue: workqueue.NewTypedRateLimitingQueueWithConfig(
+			workqueue.DefaultTypedControllerRateLimiter[string](),
+			workqueue.TypedRateLimitingQueueConfig[string]{Name: controllerName},
+		),
+		cniConfig: cniConfig,
+		hostIP:    hostIP,
+		ipFamily:  ipFamily,
+		tunnel:    tunnel,
+	}
+	subnets, err := parseCIDRs(podSubnets)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pod subnets: %w", err)
+	}
+	c.podSubnets = subnets
+
+	_, err = nodeInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: c.enqueueNode,
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldNode, ok := oldObj.(*corev1.Node)
+			if !ok {
+				return
+			}
+			newNode, ok := newObj.(*corev1.Node)
+			if !ok {
+				return
+			}
+			// status heartbeats do not change routes
+			if nodeNetworkChanged(oldNode, newNode) {
+				c.enqueueNode(newObj)
+			}
+		},
+		DeleteFunc: c.enqueueNode,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Run runs workers goroutines processing nodes until ctx is done
+func (c *NodeController) Run(ctx context.Context, workers int) {
+	defer utilruntime.HandleCrash()
+	defer c.workqueue.ShutDown()
+
+	klog.Infof("Starting %s controller", controllerName)
+	defer klog.Infof("Shutting down %s controller", controllerName)
+
+	if !cache.WaitForNamedCacheSync(controllerName, ctx.Done(), c.nodesSynced) {
+		return
+	}
+	nodes, err := c.nodeLister.List(labels.Everything())
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	c.mu.Lock()
+	c.pending = sets.New[string]()
+	for _, node := range nodes {
+		c.pending.Insert(node.Name)
+	}
+	c.synced.Store(c.pending.Len() == 0)
+	c.mu.Unlock()
+
+	for i := 0; i < workers; i++ {
+		go wait.UntilWithContext(ctx, c.runWorker, time.Second)
+	}
+	wait.UntilWithContext(ctx, c.enqueueAll, resyncPeriod)
+}
+
+// HasSynced returns true once all the nodes that existed at startup have
+// been reconciled successfully
+func (c *NodeController) HasSynced() bool {
+	return c.synced.Load()
+}
+
+func (c *NodeController) enqueueNode(obj interface{}) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	c.workqueue.Add(key)
+}
+
+func (c *NodeController) enqueueAll(ctx context.Context) {
+	nodes, err := c.nodeLister.List(labels.Everything())
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	for _, node := range nodes {
+		c.workqueue.Add(node.Name)
+	}
+}
+
+func (c *NodeController) runWorker(ctx context.Context) {
+	for c.processNextItem() {
+	}
+}
+
+func (c *NodeController) processNextItem() bool {
+	key, quit := c.workqueue.Get()
+	if quit {
+		return false
+	}
+	defer c.workqueue.Done(key)
+
+	start := time.Now()
+	err := c.syncNode(key)
+	reconcileDuration.Observe(time.Since(start).Seconds())
+	if err == nil {
+		c.workqueue.Forget(key)
+		lastReconcile.SetToCurrentTime()
+		c.markReconciled(key)
+		return true
+	}
+	// retry with backoff until it succeeds, routes must converge eventually
+	reconcileErrors.Inc()
+	klog.Infof("Failed to reconcile node %s, retrying after error: %v", key, err)
+	c.workqueue.AddRateLimited(key)
+	return true
+}
+
+// markReconciled records that the node named key was reconciled
+func (c *NodeController) markReconciled(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.pending == nil {
+		return
+	}
+	c.pending.Delete(key)
+	if c.pending.Len() == 0 {
+		c.synced.Store(true)
+	}
+}
+
+// syncNode reconciles the node named key, routes to the pod subnets of
+// deleted nodes are removed
+func (c *NodeController) syncNode(key string) error {
+	node, err := c.nodeLister.Get(key)
+	if apierrors.IsNotFound(err) {
+		klog.Infof("Node %s was deleted, removing stale routes", key)
+		return c.deleteStaleRoutes()
+	}
+	if err != nil {
+		return err
+	}
+	if err := c.reconcileNode(node); err != nil {
+		return err
+	}
+	// the pod subnets of the node may have changed
+	return c.deleteStaleRoutes()
+}
+
+// reconcileNode writes the CNI config for the current node, or ensures the
+// routes to the pod subnets of any other node
+func (c *NodeController) reconcileNode(node *corev1.Node) error {
+	// first get this node's IPs
+	// we don't support more than one IP address per IP family for simplification
+	nodeIPs := internalIPs(node)
+	klog.Infof("Handling node with IPs: %v\n", nodeIPs)
+	// This is our node. We don't need to add routes,
+	// but we might need to update the cni config
+	if nodeIPs.Has(c.hostIP) {
+		klog.Info("handling current node\n")
+		if c.tunnel != nil {
+			podCIDRs, err := parseCIDRs(nodePodCIDRs(node, c.ipFamily))
+			if err != nil {
+				return err
+			}
+			if err := c.tunnel.EnsureLocal(podCIDRs); err != nil {
+				return err
+			}
+		}
+		// compute the current cni config inputs
+		return c.cniConfig.Write(
+			ComputeCNIConfigInputs(node),
+		)
+	}
+
+	// This is another node. Add routes to the POD subnets in the other nodes
+	// don't do anything unless there is a non-empty PodCIDR
+	podCIDRs := nodePodCIDRs(node, c.ipFamily)
+	if len(podCIDRs) == 0 {
+		klog.Infof("Node %v has no CIDR, ignoring\n", node.Name)
+		return nil
+	}
+	klog.Infof("Node %v has CIDR %s \n", node.Name, podCIDRs)
+	if c.tunnel != nil {
+		return c.reconcilePeer(node, nodeIPs, podCIDRs)
+	}
+	podCIDRsv4, podCIDRsv6 := splitCIDRs(podCIDRs)
+
+	// obtain the PodCIDR gateway
+	var nodeIPv4, nodeIPv6 string
+	for _, ip := range nodeIPs.UnsortedList() {
+		if isIPv6String(ip) {
+			nodeIPv6 = ip
+		} else {
+			nodeIPv4 = ip
+		}
+	}
+
+	if nodeIPv4 != "" && len(podCIDRsv4) > 0 {
+		if err := syncRoute(nodeIPv4, podCIDRsv4); err != nil {
+			return err
+		}
+	}
+	if nodeIPv6 != "" && len(podCIDRsv6) > 0 {
+		if err := syncRoute(nodeIPv6, podCIDRsv6); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reconcilePeer ensures the traffic to podCIDRs, the pod subnets of node, is
+// sent through the tunnel to the node IP of the same family as this node
+func (c *NodeController) reconcilePeer(node *corev1.Node, nodeIPs sets.Set[string], podCIDRs []string) error {
+	nodeIP, err := sameFamilyIP(nodeIPs.UnsortedList(), net.ParseIP(c.hostIP))
+	if err != nil {
+		klog.Infof("Node %v can not be reached through the tunnel, ignoring: %v", node.Name, err)
+		return nil
+	}
+	dsts, err := parseCIDRs(podCIDRs)
+	if err != nil {
+		return err
+	}
+	if err := c.tunnel.EnsurePeer(node, nodeIP, dsts); err != nil {
+		return err
+	}
+	return syncTunnelRoutes(c.tunnel.Link(), dsts)
+}
+
+// deleteStaleRoutes deletes the routes to pod subnets that no longer belong
+// to any other node, and the tunnel peers for deleted nodes
+func (c *NodeController) deleteStaleRoutes() error {
+	nodes, err := c.nodeLister.List(labels.Everything())
+	if err != nil {
+		return err
+	}
+	wanted := sets.New[string]()
+	peers := sets.New[string]()
+	for _, node := range nodes {
+		if internalIPs(node).Has(c.hostIP) {
+			continue
+		}
+		peers.Insert(internalIPs(node).UnsortedList()...)
+		for _, podCIDR := range nodePodCIDRs(node, c.ipFamily) {
+			if _, ipNet, err := net.ParseCIDR(podCIDR); err == nil {
+				wanted.Insert(ipNet.String())
+			}
+		}
+	}
+	deleted, err := deleteStaleRoutes(c.podSubnets, wanted)
+	routesDeleted.Add(float64(deleted))
+	if err != nil || c.tunnel == nil {
+		return err
+	}
+	return c.tunnel.DeleteStalePeers(peers)
+}
+
+// nodePodCIDRs returns the pod subnets of node for the cluster ipFamily
+func nodePodCIDRs(node *corev1.Node, ipFamily IPFamily) []string {
+	if ipFamily == DualStackFamily {
+		return node.Spec.PodCIDRs
+	}
+	if node.Spec.PodCIDR != "" {
+		return []string{node.Spec.PodCIDR}
+	}
+	return nil
+}
+
+// nodeNetworkChanged returns true if the pod subnets or addresses of the
+// node changed between oldNode and newNode
+func nodeNetworkChanged(oldNode, newNode *corev1.Node) bool {
+	if oldNode.Spec.PodCIDR != newNode.Spec.PodCIDR {
+		return true
+	}
+	if !sets.New(oldNode.Spec.PodCIDRs...).Equal(sets.New(newNode.Spec.PodCIDRs...)) {
+		return true
+	}
+	if oldNode.Annotations[wireguardPublicKeyAnnotation] != newNode.Annotations[wireguardPublicKeyAnnotation] {
+		return true
+	}
+	return !internalIPs(oldNode).Equal(internalIPs(newNode))
+}
+
+// parseCIDRs parses cidrs into subnets
+func parseCIDRs(cidrs []string) ([]*net.IPNet, error) {
+	subnets := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, subnet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR %q: %w", cidr, err)
+		}
+		subnets = append(subnets, subnet)
+	}
+	return subnets, nil
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNodeNetworkChanged(t *testing.T) {
+	node := func(podCIDRs []string, publicKey string, ips ...string) *corev1.Node {
+		n := &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "kind-worker", Annotations: map[string]string{}},
+			Spec:       corev1.NodeSpec{PodCIDRs: podCIDRs},
+		}
+		if len(podCIDRs) > 0 {
+			n.Spec.PodCIDR = podCIDRs[0]
+		}
+		if publicKey != "" {
+			n.Annotations[wireguardPublicKeyAnnotation] = publicKey
+		}
+		for _, ip := range ips {
+			n.Status.Addresses = append(n.Status.Addresses, corev1.NodeAddress{Type: corev1.NodeInternalIP, Address: ip})
+		}
+		return n
+	}
+	dualStack := []string{"10.244.1.0/24", "fd00:10:244:1::/64"}
+	tests := []struct {
+		name    string
+		oldNode *corev1.Node
+		newNode *corev1.Node
+		want    bool
+	}{
+		{
+			name:    "unchanged",
+			oldNode: node(dualStack, "key", "172.18.0.2", "fc00:f853:ccd:e793::2"),
+			newNode: node(dualStack, "key", "172.18.0.2", "fc00:f853:ccd:e793::2"),
+			want:    false,
+		},
+		{
+			name:    "other status changes",
+			oldNode: node(dualStack, "", "172.18.0.2"),
+			newNode: func() *corev1.Node {
+				n := node(dualStack, "", "172.18.0.2")
+				n.Status.Addresses = append(n.Status.Addresses, corev1.NodeAddress{Type: corev1.NodeHostName, Address: "kind-worker"})
+				n.Labels = map[string]string{"a": "b"}
+				return n
+			}(),
+			want: false,
+		},
+		{
+			name:    "pod CIDR assigned",
+			oldNode: node(nil, "", "172.18.0.2"),
+			newNode: node([]string{"10.244.1.0/24"}, "", "172.18.0.2"),
+			want:    true,
+		},
+		{
+			name:    "secondary pod CIDR changed",
+			oldNode: node(dualStack, "", "172.18.0.2"),
+			newNode: node([]string{"10.244.1.0/24", "fd00:10:244:2::/64"}, "", "172.18.0.2"),
+			want:    true,
+		},
+		{
+			name:    "wireguard key rotated",
+			oldNode: node(dualStack, "key", "172.18.0.2"),
+			newNode: node(dualStack, "new-key", "172.18.0.2"),
+			want:    true,
+		},
+		{
+			name:    "internal IP changed",
+			oldNode: node(dualStack, "", "172.18.0.2"),
+			newNode: node(dualStack, "", "172.18.0.3"),
+			want:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nodeNetworkChanged(tt.oldNode, tt.newNode); got != tt.want {
+				t.Errorf("nodeNetworkChanged() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
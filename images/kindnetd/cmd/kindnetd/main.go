@@ -18,11 +18,14 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"net"
 	"os"
 	"os/signal"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
@@ -30,7 +33,8 @@ import (
 	"golang.org/x/sys/unix"
 
 	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/labels"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
@@ -53,6 +57,11 @@ const (
 // - POD_IP: should be populated by downward API
 // - CNI_CONFIG_TEMPLATE: the cni .conflist template, run with {{ .PodCIDR }}
 // - CONTROL_PLANE_ENDPOINT: control-plane endpoint format host:port
+// - MASQUERADE_BACKEND: optional, one of auto, iptables or nftables
+// - KINDNET_MTU: optional, overrides the MTU of the pod interfaces
+// - NO_MASQUERADE_CIDRS: optional, comma separated CIDRs that are not masqueraded
+// - CNI_CHAINED_PLUGINS: optional, JSON array of CNI plugin configs to chain
+// - KINDNET_ENCAPSULATION: optional, one of none, vxlan or wireguard
 
 // TODO: improve logging & error handling
 
@@ -69,6 +78,13 @@ const (
 )
 
 func main() {
+	var bindAddress string
+	flag.StringVar(&bindAddress, "bind-address", ":19080", "the address to serve the /healthz, /readyz and /metrics endpoints on, empty to disable")
+	var masqBackend string
+	flag.StringVar(&masqBackend, "masquerade-backend", os.Getenv("MASQUERADE_BACKEND"), "the backend for masquerade rules, one of auto, iptables or nftables (defaults to $MASQUERADE_BACKEND or auto)")
+	var encapsulation string
+	flag.StringVar(&encapsulation, "encapsulation", os.Getenv("KINDNET_ENCAPSULATION"), "how traffic to the pods of other nodes is sent, one of none, vxlan or wireguard (defaults to $KINDNET_ENCAPSULATION or none)")
+
 	// enable logging
 	klog.InitFlags(nil)
 	_ = flag.Set("logtostderr", "true")
@@ -131,7 +147,6 @@ func main() {
 
 	informersFactory := informers.NewSharedInformerFactory(clientset, 0)
 	nodeInformer := informersFactory.Core().V1().Nodes()
-	nodeLister := nodeInformer.Lister()
 
 	// obtain the host and pod ip addresses
 	// if both ips are different we are not using the host network
@@ -144,15 +159,45 @@ func main() {
 		)
 	}
 
-	mtu, err := computeBridgeMTU()
-	klog.Infof("setting mtu %d for CNI \n", mtu)
+	// on kind nodes the hostname matches the node name
+	nodeName, err := os.Hostname()
+	if err != nil {
+		klog.Fatalf("couldn't determine hostname: %v", err)
+	}
+
+	linkMTU, err := computeBridgeMTU()
 	if err != nil {
 		klog.Infof("Failed to get MTU size from interface eth0, using kernel default MTU size error:%v", err)
 	}
+	// tunnel the traffic to other nodes, if enabled
+	klog.Infof("kindnetd encapsulation: %q", encapsulation)
+	tunnel, err := NewTunnel(EncapsulationMode(encapsulation), net.ParseIP(hostIP), linkMTU)
+	if err != nil {
+		panic(err.Error())
+	}
+	if tunnel != nil {
+		// the other nodes need the annotations to reach this node
+		if err := annotateNode(ctx, clientset, nodeName, tunnel.Annotations()); err != nil {
+			panic(err.Error())
+		}
+	}
+	mtu := TunnelMTU(tunnel, linkMTU)
+	if mtuEnv := os.Getenv("KINDNET_MTU"); mtuEnv != "" {
+		mtu, err = strconv.Atoi(mtuEnv)
+		if err != nil {
+			panic(fmt.Sprintf("invalid KINDNET_MTU %q: %v", mtuEnv, err))
+		}
+	}
+	klog.Infof("setting mtu %d for CNI \n", mtu)
+	chainedPlugins, err := parseChainedPlugins(os.Getenv("CNI_CHAINED_PLUGINS"))
+	if err != nil {
+		panic(fmt.Sprintf("invalid CNI_CHAINED_PLUGINS: %v", err))
+	}
 	// used to track if the cni config inputs changed and write the config
 	cniConfigWriter := &CNIConfigWriter{
-		path: cniConfigPath,
-		mtu:  mtu,
+		path:           cniConfigPath,
+		mtu:            mtu,
+		chainedPlugins: chainedPlugins,
 	}
 
 	// enforce ip masquerade rules
@@ -177,15 +222,29 @@ func main() {
 	}
 	klog.Infof("kindnetd IP family: %q", ipFamily)
 
+	// traffic to the pod subnets and any extra CIDRs is not masqueraded
+	noMasqIPv4Subnets, noMasqIPv6Subnets := clusterIPv4Subnets, clusterIPv6Subnets
+	if noMasqEnv := strings.TrimSpace(os.Getenv("NO_MASQUERADE_CIDRS")); noMasqEnv != "" {
+		extraCIDRs := strings.Split(noMasqEnv, ",")
+		for _, cidr := range extraCIDRs {
+			if _, _, err := net.ParseCIDR(cidr); err != nil {
+				panic(fmt.Sprintf("invalid NO_MASQUERADE_CIDRS entry %q: %v", cidr, err))
+			}
+		}
+		extraIPv4Subnets, extraIPv6Subnets := splitCIDRs(extraCIDRs)
+		noMasqIPv4Subnets = append(noMasqIPv4Subnets, extraIPv4Subnets...)
+		noMasqIPv6Subnets = append(noMasqIPv6Subnets, extraIPv6Subnets...)
+	}
+
 	// create an ipMasqAgent for IPv4
 	if len(clusterIPv4Subnets) > 0 {
-		klog.Infof("noMask IPv4 subnets: %v", clusterIPv4Subnets)
-		masqAgentIPv4, err := NewIPMasqAgent(false, clusterIPv4Subnets)
+		klog.Infof("noMask IPv4 subnets: %v", noMasqIPv4Subnets)
+		masqAgentIPv4, err := NewMasqAgent(MasqBackend(masqBackend), false, noMasqIPv4Subnets)
 		if err != nil {
 			panic(err.Error())
 		}
 		go func() {
-			if err := masqAgentIPv4.SyncRulesForever(ctx, time.Second*60); err != nil {
+			if err := SyncRulesForever(ctx, masqAgentIPv4, time.Second*60); err != nil {
 				panic(err)
 			}
 		}()
@@ -193,30 +252,21 @@ func main() {
 
 	// create an ipMasqAgent for IPv6
 	if len(clusterIPv6Subnets) > 0 {
-		klog.Infof("noMask IPv6 subnets: %v", clusterIPv6Subnets)
-		masqAgentIPv6, err := NewIPMasqAgent(true, clusterIPv6Subnets)
+		klog.Infof("noMask IPv6 subnets: %v", noMasqIPv6Subnets)
+		masqAgentIPv6, err := NewMasqAgent(MasqBackend(masqBackend), true, noMasqIPv6Subnets)
 		if err != nil {
 			panic(err.Error())
 		}
 
 		go func() {
-			if err := masqAgentIPv6.SyncRulesForever(ctx, time.Second*60); err != nil {
+			if err := SyncRulesForever(ctx, masqAgentIPv6, time.Second*60); err != nil {
 				panic(err)
 			}
 		}()
 	}
 
-	// setup nodes reconcile function, closes over arguments
-	reconcileNodes := makeNodesReconciler(cniConfigWriter, hostIP, ipFamily)
-
 	// network policies
 
-	// on kind nodes the hostname matches the node name
-	nodeName, err := os.Hostname()
-	if err != nil {
-		klog.Fatalf("couldn't determine hostname: %v", err)
-	}
-
 	cfg := networkpolicy.Config{
 		FailOpen:            true,
 		QueueID:             101,
@@ -238,124 +288,66 @@ func main() {
 	if err != nil {
 		klog.Infof("Error creating network policy controller: %v, skipping network policies", err)
 	} else {
+		networkPolicyControllerRunning.Set(1)
 		go func() {
-			_ = networkPolicyController.Run(ctx)
+			defer networkPolicyControllerRunning.Set(0)
+			if err := networkPolicyController.Run(ctx); err != nil {
+				klog.Infof("Network policy controller stopped: %v", err)
+			}
 		}()
 	}
 
-	// main control loop
-	informersFactory.Start(ctx.Done())
-	ticker := time.NewTicker(10 * time.Second)
-	defer ticker.Stop()
+	// node controller, its handlers must be registered before the informers
+	// are started
+	nodeController, err := NewNodeController(nodeInformer, cniConfigWriter, hostIP, ipFamily, podSubnets, tunnel)
+	if err != nil {
+		panic(err.Error())
+	}
 
-	for {
-		var nodes []*corev1.Node
-		var err error
-		for i := 0; i < 5; i++ {
-			nodes, err = nodeLister.List(labels.Everything())
-			if err == nil {
-				break
+	if bindAddress != "" {
+		// ready once the CNI config has been written and the routes synced
+		go serveHTTP(bindAddress, func() error {
+			if !cniConfigWriter.Written() {
+				return errors.New("the CNI config has not been written")
 			}
-			klog.Infof("Failed to get nodes, retrying after error: %v", err)
-			time.Sleep(time.Second * time.Duration(i))
-		}
-		if err != nil {
-			panic("Reached maximum retries obtaining node list: " + err.Error())
-		}
-
-		// reconcile the nodes with retries
-		for i := 0; i < 5; i++ {
-			err = reconcileNodes(nodes)
-			if err == nil {
-				break
+			if !nodeController.HasSynced() {
+				return errors.New("the node routes have not been synced")
 			}
-			klog.Infof("Failed to reconcile routes, retrying after error: %v", err)
-			time.Sleep(time.Second * time.Duration(i))
-		}
-		if err != nil {
-			panic("Maximum retries reconciling node routes: " + err.Error())
-		}
-
-		// rate limit
-		select {
-		case <-ctx.Done():
-			// grace period to cleanup resources
-			time.Sleep(1 * time.Second)
-			return
-		case <-ticker.C:
-		}
+			return nil
+		})
 	}
-}
 
-// nodeNodesReconciler returns a reconciliation func for nodes
-func makeNodesReconciler(cniConfig *CNIConfigWriter, hostIP string, ipFamily IPFamily) func([]*corev1.Node) error {
-	// reconciles a node
-	reconcileNode := func(node *corev1.Node) error {
-		// first get this node's IPs
-		// we don't support more than one IP address per IP family for simplification
-		nodeIPs := internalIPs(node)
-		klog.Infof("Handling node with IPs: %v\n", nodeIPs)
-		// This is our node. We don't need to add routes,
-		// but we might need to update the cni config
-		if nodeIPs.Has(hostIP) {
-			klog.Info("handling current node\n")
-			// compute the current cni config inputs
-			if err := cniConfig.Write(
-				ComputeCNIConfigInputs(node),
-			); err != nil {
-				return err
-			}
-			// we're done handling this node
-			return nil
-		}
+	// main control loop
+	informersFactory.Start(ctx.Done())
+	nodeController.Run(ctx, 1)
 
-		// This is another node. Add routes to the POD subnets in the other nodes
-		// don't do anything unless there is a non-empty PodCIDR
-		var podCIDRs []string
-		if ipFamily == DualStackFamily {
-			podCIDRs = node.Spec.PodCIDRs
-		} else if node.Spec.PodCIDR != "" {
-			podCIDRs = []string{node.Spec.PodCIDR}
-		}
-		if len(podCIDRs) == 0 {
-			fmt.Printf("Node %v has no CIDR, ignoring\n", node.Name)
-			return nil
-		}
-		klog.Infof("Node %v has CIDR %s \n", node.Name, podCIDRs)
-		podCIDRsv4, podCIDRsv6 := splitCIDRs(podCIDRs)
-
-		// obtain the PodCIDR gateway
-		var nodeIPv4, nodeIPv6 string
-		for _, ip := range nodeIPs.UnsortedList() {
-			if isIPv6String(ip) {
-				nodeIPv6 = ip
-			} else {
-				nodeIPv4 = ip
-			}
-		}
+	// grace period to cleanup resources
+	time.Sleep(1 * time.Second)
+}
 
-		if nodeIPv4 != "" && len(podCIDRsv4) > 0 {
-			if err := syncRoute(nodeIPv4, podCIDRsv4); err != nil {
-				return err
-			}
-		}
-		if nodeIPv6 != "" && len(podCIDRsv6) > 0 {
-			if err := syncRoute(nodeIPv6, podCIDRsv6); err != nil {
-				return err
-			}
-		}
+// annotateNode sets annotations on the node named nodeName, retrying until
+// the apiserver accepts the patch
+func annotateNode(ctx context.Context, clientset kubernetes.Interface, nodeName string, annotations map[string]string) error {
+	if len(annotations) == 0 {
 		return nil
 	}
-
-	// return a reconciler for all the nodes
-	return func(nodes []*corev1.Node) error {
-		for _, node := range nodes {
-			if err := reconcileNode(node); err != nil {
-				return err
-			}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": annotations,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	for i := 0; i < 5; i++ {
+		_, err = clientset.CoreV1().Nodes().Patch(ctx, nodeName, types.MergePatchType, patch, metav1.PatchOptions{})
+		if err == nil {
+			return nil
 		}
-		return nil
+		klog.Infof("failed to annotate node %s, attempt %d ... retrying: %v", nodeName, i, err)
+		time.Sleep(time.Second * time.Duration(i))
 	}
+	return fmt.Errorf("failed to annotate node %s: %w", nodeName, err)
 }
 
 // internalIPs returns the internal IP addresses for node
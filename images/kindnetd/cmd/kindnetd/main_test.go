@@ -0,0 +1,62 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitCIDRs(t *testing.T) {
+	tests := []struct {
+		name   string
+		cidrs  []string
+		wantV4 []string
+		wantV6 []string
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name:   "ipv4",
+			cidrs:  []string{"10.244.0.0/16", "10.96.0.0/16"},
+			wantV4: []string{"10.244.0.0/16", "10.96.0.0/16"},
+		},
+		{
+			name:   "ipv6",
+			cidrs:  []string{"fd00:10:244::/56"},
+			wantV6: []string{"fd00:10:244::/56"},
+		},
+		{
+			name:   "dual stack in any order",
+			cidrs:  []string{"fd00:10:244::/56", "10.244.0.0/16", "fd00:10:96::/112"},
+			wantV4: []string{"10.244.0.0/16"},
+			wantV6: []string{"fd00:10:244::/56", "fd00:10:96::/112"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v4, v6 := splitCIDRs(tt.cidrs)
+			if !reflect.DeepEqual(v4, tt.wantV4) {
+				t.Errorf("splitCIDRs() v4 = %v, want %v", v4, tt.wantV4)
+			}
+			if !reflect.DeepEqual(v6, tt.wantV6) {
+				t.Errorf("splitCIDRs() v6 = %v, want %v", v6, tt.wantV6)
+			}
+		})
+	}
+}
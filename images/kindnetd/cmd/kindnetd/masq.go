@@ -20,49 +20,135 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os/exec"
+	"strings"
 	"time"
 
 	"github.com/coreos/go-iptables/iptables"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/knftables"
 )
 
-// NewIPMasqAgent returns a new IPMasqAgent
-func NewIPMasqAgent(ipv6 bool, noMasqueradeCIDRs []string) (*IPMasqAgent, error) {
-	protocol := iptables.ProtocolIPv4
+// MasqBackend is the backend used to program the masquerade rules
+type MasqBackend string
+
+const (
+	// MasqBackendAuto uses nftables if kube-proxy runs in nftables mode or
+	// the node has no iptables rules, and iptables otherwise
+	MasqBackendAuto MasqBackend = "auto"
+	// MasqBackendIPTables programs the rules with iptables
+	MasqBackendIPTables MasqBackend = "iptables"
+	// MasqBackendNFTables programs the rules with nftables
+	MasqBackendNFTables MasqBackend = "nftables"
+)
+
+// MasqAgent syncs the rules masquerading the traffic leaving the cluster
+type MasqAgent interface {
+	// SyncRules syncs the masquerade rules
+	SyncRules() error
+}
+
+// NewMasqAgent returns a new MasqAgent for the backend and IP family that
+// does not masquerade traffic to noMasqueradeCIDRs.
+// Rules left behind by the other backend are removed, so that the backends
+// are never mixed.
+func NewMasqAgent(backend MasqBackend, ipv6 bool, noMasqueradeCIDRs []string) (MasqAgent, error) {
+	if backend == "" || backend == MasqBackendAuto {
+		backend = detectMasqBackend(ipv6)
+	}
+	klog.Infof("using %s masquerade backend", backend)
+	switch backend {
+	case MasqBackendIPTables:
+		cleanupNFTablesMasq(ipv6)
+		return NewIPMasqAgent(ipv6, noMasqueradeCIDRs)
+	case MasqBackendNFTables:
+		cleanupIPTablesMasq(ipv6)
+		return NewNFTablesMasqAgent(ipv6, noMasqueradeCIDRs)
+	}
+	return nil, fmt.Errorf("unknown masquerade backend %q", backend)
+}
+
+// iptablesNFTTables are the tables iptables-nft creates in nftables
+var iptablesNFTTables = []string{"nat", "filter", "mangle"}
+
+// detectMasqBackend returns the backend matching the rules already on the
+// node for the IP family. The iptables tools in the kindnetd image say
+// nothing about the node, so the rules of the node are checked.
+func detectMasqBackend(ipv6 bool) MasqBackend {
+	family := knftables.IPv4Family
+	binaries := []string{"iptables", "iptables-legacy", "iptables-nft"}
+	legacySave := "iptables-legacy-save"
 	if ipv6 {
-		protocol = iptables.ProtocolIPv6
+		family = knftables.IPv6Family
+		binaries = []string{"ip6tables", "ip6tables-legacy", "ip6tables-nft"}
+		legacySave = "ip6tables-legacy-save"
 	}
-	ipt, err := iptables.NewWithProtocol(protocol)
-	if err != nil {
-		return nil, err
+	kubeProxyNFT := nftTableExists(family, "kube-proxy")
+	iptablesRules := iptablesLegacyRules(legacySave)
+	for _, table := range iptablesNFTTables {
+		iptablesRules = iptablesRules || nftTableExists(family, table)
 	}
+	iptablesAvailable := false
+	for _, binary := range binaries {
+		if _, err := exec.LookPath(binary); err == nil {
+			iptablesAvailable = true
+		}
+	}
+	return masqBackendFor(kubeProxyNFT, iptablesRules, iptablesAvailable)
+}
 
-	// TODO: validate cidrs
-	return &IPMasqAgent{
-		iptables:          ipt,
-		masqChain:         masqChainName,
-		noMasqueradeCIDRs: noMasqueradeCIDRs,
-	}, nil
+// masqBackendFor returns the backend to use when kube-proxy's nftables
+// table exists, the node has iptables rules, with iptables-nft or
+// iptables-legacy, and the iptables tools are available or not.
+// iptables stays the default while the node has iptables rules, kubelet
+// creates some on almost every node. nftables is used if kube-proxy runs in
+// nftables mode, or if the node has no iptables rules at all.
+func masqBackendFor(kubeProxyNFT, iptablesRules, iptablesAvailable bool) MasqBackend {
+	if kubeProxyNFT || !iptablesRules || !iptablesAvailable {
+		return MasqBackendNFTables
+	}
+	return MasqBackendIPTables
 }
 
-// IPMasqAgent is based on https://github.com/kubernetes-incubator/ip-masq-agent
-// but collapsed into kindnetd and made ipv6 aware in an opinionated and simplified
-// fashion using "github.com/coreos/go-iptables"
-type IPMasqAgent struct {
-	iptables          *iptables.IPTables
-	masqChain         string
-	noMasqueradeCIDRs []string
+// iptablesLegacyRules returns true if the iptables-legacy-save binary save
+// lists any rules, and false if it does not or is not available
+func iptablesLegacyRules(save string) bool {
+	out, err := exec.Command(save).Output()
+	if err != nil {
+		return false
+	}
+	for _, line := range strings.Split(string(out), "\n") {
+		if strings.HasPrefix(line, "-A ") {
+			return true
+		}
+	}
+	return false
 }
 
-// SyncRulesForever syncs ip masquerade rules forever
+// nftTableExists returns true if the nftables table name exists in family,
+// and false if it does not or nftables is not available
+func nftTableExists(family knftables.Family, name string) bool {
+	nft, err := knftables.New(family, name)
+	if err != nil {
+		return false
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err = nft.ListRules(ctx, "")
+	return err == nil
+}
+
+// SyncRulesForever syncs the masquerade rules of agent forever
 // these rules only needs to be installed once, but we run it periodically to check that are
 // not deleted by an external program. It fails if can't sync the rules during 3 iterations
-func (ma *IPMasqAgent) SyncRulesForever(ctx context.Context, interval time.Duration) error {
+func SyncRulesForever(ctx context.Context, agent MasqAgent, interval time.Duration) error {
 	var errs []error
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
 	for {
-		if err := ma.SyncRules(); err != nil {
+		if err := agent.SyncRules(); err != nil {
+			masqSyncErrors.Inc()
 			errs = append(errs, fmt.Errorf("failed to synchronize rules at %s: %v", time.Now(), err))
 			if len(errs) > 3 {
 				return fmt.Errorf("Can't synchronize rules after 3 attempts: %w", err)
@@ -78,6 +164,36 @@ func (ma *IPMasqAgent) SyncRulesForever(ctx context.Context, interval time.Durat
 	}
 }
 
+// NewIPMasqAgent returns a new IPMasqAgent
+func NewIPMasqAgent(ipv6 bool, noMasqueradeCIDRs []string) (*IPMasqAgent, error) {
+	protocol := iptables.ProtocolIPv4
+	if ipv6 {
+		protocol = iptables.ProtocolIPv6
+	}
+	ipt, err := iptables.NewWithProtocol(protocol)
+	if err != nil {
+		return nil, err
+	}
+
+	// TODO: validate cidrs
+	return &IPMasqAgent{
+		iptables:          ipt,
+		masqChain:         masqChainName,
+		noMasqueradeCIDRs: noMasqueradeCIDRs,
+	}, nil
+}
+
+var _ MasqAgent = &IPMasqAgent{}
+
+// IPMasqAgent is based on https://github.com/kubernetes-incubator/ip-masq-agent
+// but collapsed into kindnetd and made ipv6 aware in an opinionated and simplified
+// fashion using "github.com/coreos/go-iptables"
+type IPMasqAgent struct {
+	iptables          *iptables.IPTables
+	masqChain         string
+	noMasqueradeCIDRs []string
+}
+
 // name of nat chain for iptables masquerade rules
 const masqChainName = "KIND-MASQ-AGENT"
 
@@ -114,5 +230,34 @@ func (ma *IPMasqAgent) SyncRules() error {
 	}
 
 	// Send all non-LOCAL destination traffic to our custom KIND-MASQ-AGENT chain
-	return ma.iptables.AppendUnique("nat", "POSTROUTING", "-m", "addrtype", "!", "--dst-type", "LOCAL", "-j", ma.masqChain, "-m", "comment", "--comment", "kind-masq-agent: ensure nat POSTROUTING directs all non-LOCAL destination traffic to our custom KIND-MASQ-AGENT chain")
+	return ma.iptables.AppendUnique("nat", "POSTROUTING", postroutingJumpRule(ma.masqChain)...)
+}
+
+// postroutingJumpRule is the POSTROUTING rule sending traffic to chain
+func postroutingJumpRule(chain string) []string {
+	return []string{"-m", "addrtype", "!", "--dst-type", "LOCAL", "-j", chain, "-m", "comment", "--comment", "kind-masq-agent: ensure nat POSTROUTING directs all non-LOCAL destination traffic to our custom KIND-MASQ-AGENT chain"}
+}
+
+// cleanupIPTablesMasq removes the iptables masquerade rules, if any
+func cleanupIPTablesMasq(ipv6 bool) {
+	protocol := iptables.ProtocolIPv4
+	if ipv6 {
+		protocol = iptables.ProtocolIPv6
+	}
+	ipt, err := iptables.NewWithProtocol(protocol)
+	if err != nil {
+		// without iptables there can be no iptables rules to remove
+		return
+	}
+	exists, err := ipt.ChainExists("nat", masqChainName)
+	if err != nil || !exists {
+		return
+	}
+	klog.Infof("removing iptables masquerade rules")
+	if err := ipt.DeleteIfExists("nat", "POSTROUTING", postroutingJumpRule(masqChainName)...); err != nil {
+		klog.Infof("failed to delete iptables rule: %v", err)
+	}
+	if err := ipt.ClearAndDeleteChain("nat", masqChainName); err != nil {
+		klog.Infof("failed to delete iptables chain %s: %v", masqChainName, err)
+	}
 }
@@ -0,0 +1,135 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/knftables"
+)
+
+const (
+	// name of the nftables table for masquerade rules
+	masqTableName = "kindnet-masquerade"
+	// name of the nftables set of destinations that are not masqueraded
+	noMasqSetName = "no-masquerade-subnets"
+	// name of the nftables chain for masquerade rules
+	masqNFTChainName = "postrouting"
+)
+
+// NewNFTablesMasqAgent returns a new NFTablesMasqAgent
+func NewNFTablesMasqAgent(ipv6 bool, noMasqueradeCIDRs []string) (*NFTablesMasqAgent, error) {
+	family := knftables.IPv4Family
+	if ipv6 {
+		family = knftables.IPv6Family
+	}
+	nft, err := knftables.New(family, masqTableName)
+	if err != nil {
+		return nil, err
+	}
+	return &NFTablesMasqAgent{
+		nft:               nft,
+		ipv6:              ipv6,
+		noMasqueradeCIDRs: noMasqueradeCIDRs,
+	}, nil
+}
+
+// NFTablesMasqAgent implements the same rules as IPMasqAgent with nftables,
+// in a table owned by kindnetd
+type NFTablesMasqAgent struct {
+	nft               knftables.Interface
+	ipv6              bool
+	noMasqueradeCIDRs []string
+}
+
+var _ MasqAgent = &NFTablesMasqAgent{}
+
+// SyncRules syncs ip masquerade rules, the table is rewritten in a single
+// transaction
+func (ma *NFTablesMasqAgent) SyncRules() error {
+	addrType, addrMatch := "ipv4_addr", "ip daddr"
+	if ma.ipv6 {
+		addrType, addrMatch = "ipv6_addr", "ip6 daddr"
+	}
+
+	tx := ma.nft.NewTransaction()
+	tx.Add(&knftables.Table{
+		Comment: knftables.PtrTo("rules for kindnet masquerading"),
+	})
+
+	// Packets to this network should not be masquerade, pods should be able to talk to other pods
+	tx.Add(&knftables.Set{
+		Name:  noMasqSetName,
+		Type:  addrType,
+		Flags: []knftables.SetFlag{knftables.IntervalFlag},
+	})
+	tx.Flush(&knftables.Set{
+		Name: noMasqSetName,
+	})
+	for _, cidr := range ma.noMasqueradeCIDRs {
+		tx.Add(&knftables.Element{
+			Set: noMasqSetName,
+			Key: []string{cidr},
+		})
+	}
+
+	tx.Add(&knftables.Chain{
+		Name:     masqNFTChainName,
+		Type:     knftables.PtrTo(knftables.NATType),
+		Hook:     knftables.PtrTo(knftables.PostroutingHook),
+		Priority: knftables.PtrTo(knftables.SNATPriority),
+	})
+	tx.Flush(&knftables.Chain{
+		Name: masqNFTChainName,
+	})
+	tx.Add(&knftables.Rule{
+		Chain:   masqNFTChainName,
+		Rule:    "fib daddr type local return",
+		Comment: knftables.PtrTo("local traffic is not subject to MASQUERADE"),
+	})
+	tx.Add(&knftables.Rule{
+		Chain:   masqNFTChainName,
+		Rule:    knftables.Concat(addrMatch, "@"+noMasqSetName, "return"),
+		Comment: knftables.PtrTo("cluster traffic is not subject to MASQUERADE"),
+	})
+	// Masquerade all the other traffic
+	tx.Add(&knftables.Rule{
+		Chain:   masqNFTChainName,
+		Rule:    "masquerade",
+		Comment: knftables.PtrTo("outbound traffic is subject to MASQUERADE"),
+	})
+	return ma.nft.Run(context.TODO(), tx)
+}
+
+// cleanupNFTablesMasq removes the nftables masquerade rules, if any
+func cleanupNFTablesMasq(ipv6 bool) {
+	family := knftables.IPv4Family
+	if ipv6 {
+		family = knftables.IPv6Family
+	}
+	nft, err := knftables.New(family, masqTableName)
+	if err != nil {
+		// without nftables there can be no nftables rules to remove
+		return
+	}
+	tx := nft.NewTransaction()
+	tx.Delete(&knftables.Table{})
+	if err := nft.Run(context.TODO(), tx); err != nil && !knftables.IsNotFound(err) {
+		klog.Infof("failed to delete nftables table %s: %v", masqTableName, err)
+	}
+}
@@ -0,0 +1,42 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import "testing"
+
+func TestMasqBackendFor(t *testing.T) {
+	tests := []struct {
+		name              string
+		kubeProxyNFT      bool
+		iptablesRules     bool
+		iptablesAvailable bool
+		want              MasqBackend
+	}{
+		{name: "kube-proxy nftables mode", kubeProxyNFT: true, iptablesAvailable: true, want: MasqBackendNFTables},
+		{name: "kube-proxy nftables mode with kubelet iptables-nft rules", kubeProxyNFT: true, iptablesRules: true, iptablesAvailable: true, want: MasqBackendNFTables},
+		{name: "kubelet iptables-nft rules", iptablesRules: true, iptablesAvailable: true, want: MasqBackendIPTables},
+		{name: "no iptables rules", iptablesAvailable: true, want: MasqBackendNFTables},
+		{name: "no iptables tools", iptablesRules: true, want: MasqBackendNFTables},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := masqBackendFor(tt.kubeProxyNFT, tt.iptablesRules, tt.iptablesAvailable); got != tt.want {
+				t.Errorf("masqBackendFor() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const metricsNamespace = "kindnet"
+
+var (
+	reconcileDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "node_reconcile_duration_seconds",
+		Help:      "Latency of reconciling the routes and CNI config for a node",
+		Buckets:   prometheus.ExponentialBuckets(0.001, 2, 14),
+	})
+	reconcileErrors = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "node_reconcile_errors_total",
+		Help:      "Number of failed node reconciliations",
+	})
+	lastReconcile = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "last_successful_reconcile_timestamp_seconds",
+		Help:      "Time of the last successful node reconciliation",
+	})
+	routesProgrammed = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "routes_programmed_total",
+		Help:      "Number of routes to the pod subnets of other nodes that were added",
+	})
+	routesDeleted = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "stale_routes_deleted_total",
+		Help:      "Number of routes to the pod subnets of deleted nodes that were removed",
+	})
+	masqSyncErrors = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "masquerade_sync_errors_total",
+		Help:      "Number of failed masquerade rules syncs",
+	})
+	networkPolicyControllerRunning = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "network_policy_controller_running",
+		Help:      "Whether the network policy controller is running (1) or not (0)",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(
+		reconcileDuration,
+		reconcileErrors,
+		lastReconcile,
+		routesProgrammed,
+		routesDeleted,
+		masqSyncErrors,
+		networkPolicyControllerRunning,
+	)
+}
@@ -21,6 +21,7 @@ import (
 
 	"github.com/vishvananda/netlink"
 	"github.com/vishvananda/netlink/nl"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/klog/v2"
 )
 
@@ -35,35 +36,98 @@ func syncRoute(nodeIP string, podCIDRs []string) error {
 		}
 
 		// Declare the wanted route.
-		routeToDst := netlink.Route{Dst: dst, Gw: ip}
-		// List all routes which have the same dst set.
-		// RouteListFiltered ignores the gw for filtering because of the passed filterMask.
-		routes, err := netlink.RouteListFiltered(nl.GetIPFamily(ip), &routeToDst, netlink.RT_FILTER_DST)
-		if err != nil {
+		if err := ensureRoute(netlink.Route{Dst: dst, Gw: ip}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// syncTunnelRoutes ensures the routes to podCIDRs, the pod subnets of another
+// node, via its tunnel endpoint addresses on link
+func syncTunnelRoutes(link netlink.Link, podCIDRs []*net.IPNet) error {
+	for _, dst := range podCIDRs {
+		routeToDst := netlink.Route{
+			Dst:       dst,
+			Gw:        tunnelIP(dst),
+			LinkIndex: link.Attrs().Index,
+			Flags:     int(netlink.FLAG_ONLINK),
+		}
+		if err := ensureRoute(routeToDst); err != nil {
 			return err
 		}
+	}
+	return nil
+}
 
-		// Check if the wanted route exists and delete wrong routes
-		found := false
-		for _, route := range routes {
-			if route.Gw.Equal(ip) {
-				found = true
-				continue
-			}
-			// Delete wrong route because of invalid gateway.
-			klog.Infof("Removing invalid route %v\n", route)
-			if err := netlink.RouteDel(&route); err != nil {
-				return err
-			}
+// ensureRoute adds routeToDst, replacing any route to the same destination
+// via a different gateway or interface
+func ensureRoute(routeToDst netlink.Route) error {
+	// List all routes which have the same dst set.
+	// RouteListFiltered ignores the gw for filtering because of the passed filterMask.
+	routes, err := netlink.RouteListFiltered(nl.GetIPFamily(routeToDst.Gw), &routeToDst, netlink.RT_FILTER_DST)
+	if err != nil {
+		return err
+	}
+
+	// Check if the wanted route exists and delete wrong routes
+	found := false
+	for _, route := range routes {
+		if route.Gw.Equal(routeToDst.Gw) && (routeToDst.LinkIndex == 0 || route.LinkIndex == routeToDst.LinkIndex) {
+			found = true
+			continue
+		}
+		// Delete wrong route because of invalid gateway.
+		klog.Infof("Removing invalid route %v\n", route)
+		if err := netlink.RouteDel(&route); err != nil {
+			return err
 		}
+	}
 
-		// Add route if not present
-		if !found {
-			klog.Infof("Adding route %v \n", routeToDst)
-			if err := netlink.RouteAdd(&routeToDst); err != nil {
-				return err
-			}
+	// Add route if not present
+	if !found {
+		klog.Infof("Adding route %v \n", routeToDst)
+		if err := netlink.RouteAdd(&routeToDst); err != nil {
+			return err
 		}
+		routesProgrammed.Inc()
 	}
 	return nil
 }
+
+// deleteStaleRoutes deletes the routes via a gateway to destinations inside
+// podSubnets that are not in wanted, returning the number of deleted routes.
+// Routes without a gateway, like the ones to local pods, are never deleted.
+func deleteStaleRoutes(podSubnets []*net.IPNet, wanted sets.Set[string]) (int, error) {
+	routes, err := netlink.RouteList(nil, netlink.FAMILY_ALL)
+	if err != nil {
+		return 0, err
+	}
+	deleted := 0
+	for _, route := range routes {
+		if route.Dst == nil || route.Gw == nil || wanted.Has(route.Dst.String()) {
+			continue
+		}
+		if !subnetsContain(podSubnets, route.Dst) {
+			continue
+		}
+		klog.Infof("Removing stale route %v\n", route)
+		if err := netlink.RouteDel(&route); err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+// subnetsContain returns true if dst is inside any of subnets
+func subnetsContain(subnets []*net.IPNet, dst *net.IPNet) bool {
+	dstOnes, _ := dst.Mask.Size()
+	for _, subnet := range subnets {
+		ones, _ := subnet.Mask.Size()
+		if subnet.Contains(dst.IP) && dstOnes >= ones {
+			return true
+		}
+	}
+	return false
+}
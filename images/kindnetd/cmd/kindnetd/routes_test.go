@@ -0,0 +1,62 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"net"
+	"testing"
+)
+
+func TestSubnetsContain(t *testing.T) {
+	subnets := mustParseCIDRs(t, "10.244.0.0/16", "fd00:10:244::/56")
+	tests := []struct {
+		name string
+		dst  string
+		want bool
+	}{
+		{name: "same subnet", dst: "10.244.0.0/16", want: true},
+		{name: "node pod subnet", dst: "10.244.1.0/24", want: true},
+		{name: "ipv6 node pod subnet", dst: "fd00:10:244:1::/64", want: true},
+		{name: "larger subnet", dst: "10.0.0.0/8", want: false},
+		{name: "other subnet", dst: "10.96.0.0/24", want: false},
+		{name: "ipv6 other subnet", dst: "fd00:10:96::/112", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, dst, err := net.ParseCIDR(tt.dst)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := subnetsContain(subnets, dst); got != tt.want {
+				t.Errorf("subnetsContain(%s) = %v, want %v", tt.dst, got, tt.want)
+			}
+		})
+	}
+	if subnetsContain(nil, subnets[0]) {
+		t.Errorf("expected no subnets to contain nothing")
+	}
+}
+
+// mustParseCIDRs parses cidrs or fails the test
+func mustParseCIDRs(t *testing.T, cidrs ...string) []*net.IPNet {
+	t.Helper()
+	subnets, err := parseCIDRs(cidrs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return subnets
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/vishvananda/netlink"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+)
+
+// EncapsulationMode defines how traffic to the pods of other nodes is sent
+type EncapsulationMode string
+
+const (
+	// NoEncapsulation routes the traffic directly via the node IPs, all the
+	// nodes must share an L2 network
+	NoEncapsulation EncapsulationMode = "none"
+	// VXLANEncapsulation sends the traffic through a VXLAN tunnel
+	VXLANEncapsulation EncapsulationMode = "vxlan"
+	// WireGuardEncapsulation sends the traffic through an encrypted
+	// WireGuard tunnel
+	WireGuardEncapsulation EncapsulationMode = "wireguard"
+)
+
+// Tunnel encapsulates the traffic to the pod subnets of other nodes, which
+// only requires the node IPs to be reachable from each other.
+// NOTE: should only be called from a single goroutine
+type Tunnel interface {
+	// Link returns the tunnel interface
+	Link() netlink.Link
+	// Annotations returns the annotations this node must publish for the
+	// other nodes to reach it
+	Annotations() map[string]string
+	// EnsureLocal assigns the tunnel endpoint addresses for podCIDRs, the
+	// pod subnets of this node, to the tunnel interface
+	EnsureLocal(podCIDRs []*net.IPNet) error
+	// EnsurePeer ensures the traffic to podCIDRs, the pod subnets of node,
+	// is sent through the tunnel to nodeIP
+	EnsurePeer(node *corev1.Node, nodeIP net.IP, podCIDRs []*net.IPNet) error
+	// DeleteStalePeers removes the peers whose node IP is not in nodeIPs
+	DeleteStalePeers(nodeIPs sets.Set[string]) error
+}
+
+// NewTunnel returns a Tunnel for mode, sending the encapsulated traffic from
+// hostIP. mtu is the MTU of the node interface, or zero if unknown.
+// It returns nil if mode is NoEncapsulation. Tunnel interfaces of the other
+// modes are removed.
+func NewTunnel(mode EncapsulationMode, hostIP net.IP, mtu int) (Tunnel, error) {
+	switch mode {
+	case "", NoEncapsulation:
+		cleanupTunnels()
+		return nil, nil
+	case VXLANEncapsulation:
+		deleteLink(wireguardLinkName)
+		return NewVXLANTunnel(hostIP, mtu)
+	case WireGuardEncapsulation:
+		deleteLink(vxlanLinkName)
+		return NewWireGuardTunnel(hostIP, mtu)
+	}
+	return nil, fmt.Errorf("unknown encapsulation mode %q", mode)
+}
+
+// TunnelMTU returns the MTU of the pod interfaces when traffic is sent
+// through tunnel from a node interface with mtu
+func TunnelMTU(tunnel Tunnel, mtu int) int {
+	if tunnel == nil || mtu == 0 {
+		return mtu
+	}
+	return tunnel.Link().Attrs().MTU
+}
+
+// tunnelIP returns the tunnel endpoint address for podCIDR, the first address
+// of the subnet, which is not handed out to pods
+func tunnelIP(podCIDR *net.IPNet) net.IP {
+	return podCIDR.IP.Mask(podCIDR.Mask)
+}
+
+// tunnelAddr returns the address of the tunnel endpoint for podCIDR
+func tunnelAddr(podCIDR *net.IPNet) *netlink.Addr {
+	ip := tunnelIP(podCIDR)
+	bits := 8 * len(ip)
+	return &netlink.Addr{IPNet: &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}}
+}
+
+// ensureTunnelAddrs assigns the tunnel endpoint addresses of podCIDRs to
+// link and brings it up
+func ensureTunnelAddrs(link netlink.Link, podCIDRs []*net.IPNet) error {
+	for _, podCIDR := range podCIDRs {
+		if err := netlink.AddrReplace(link, tunnelAddr(podCIDR)); err != nil {
+			return fmt.Errorf("failed to add address to %s: %w", link.Attrs().Name, err)
+		}
+	}
+	return netlink.LinkSetUp(link)
+}
+
+// encapsulationOverhead returns the bytes added to each packet by a tunnel
+// with overhead bytes of headers on top of the IP and UDP headers
+func encapsulationOverhead(hostIP net.IP, overhead int) int {
+	// IP + UDP headers
+	if hostIP.To4() == nil {
+		return overhead + 40 + 8
+	}
+	return overhead + 20 + 8
+}
+
+// deleteLink deletes the link named name, if any
+func deleteLink(name string) {
+	link, err := netlink.LinkByName(name)
+	if err != nil {
+		return
+	}
+	klog.Infof("Removing tunnel interface %s", name)
+	if err := netlink.LinkDel(link); err != nil {
+		klog.Infof("failed to delete interface %s: %v", name, err)
+	}
+}
+
+// cleanupTunnels removes the tunnel interfaces of every mode, if any.
+// The routes through them are removed along with the interfaces.
+func cleanupTunnels() {
+	deleteLink(vxlanLinkName)
+	deleteLink(wireguardLinkName)
+}
+
+// sameFamilyIP returns the IP from ips with the same family as ip, if any
+func sameFamilyIP(ips []string, ip net.IP) (net.IP, error) {
+	for _, s := range ips {
+		candidate := net.ParseIP(s)
+		if candidate != nil && (candidate.To4() == nil) == (ip.To4() == nil) {
+			return candidate, nil
+		}
+	}
+	return nil, errors.New("no node IP of the same family as the host IP")
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"net"
+	"testing"
+
+	"github.com/vishvananda/netlink"
+)
+
+func TestTunnelIP(t *testing.T) {
+	tests := []struct {
+		podCIDR string
+		want    string
+	}{
+		{podCIDR: "10.244.1.0/24", want: "10.244.1.0"},
+		{podCIDR: "10.244.0.0/16", want: "10.244.0.0"},
+		{podCIDR: "fd00:10:244:1::/64", want: "fd00:10:244:1::"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.podCIDR, func(t *testing.T) {
+			_, podCIDR, err := net.ParseCIDR(tt.podCIDR)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := tunnelIP(podCIDR); !got.Equal(net.ParseIP(tt.want)) {
+				t.Errorf("tunnelIP(%s) = %v, want %v", tt.podCIDR, got, tt.want)
+			}
+			// the tunnel address is a host address
+			addr := tunnelAddr(podCIDR)
+			if ones, bits := addr.Mask.Size(); ones != bits {
+				t.Errorf("tunnelAddr(%s) = %v, want a host address", tt.podCIDR, addr.IPNet)
+			}
+		})
+	}
+}
+
+func TestEncapsulationOverhead(t *testing.T) {
+	tests := []struct {
+		name     string
+		hostIP   string
+		overhead int
+		want     int
+	}{
+		{name: "vxlan over ipv4", hostIP: "172.18.0.2", overhead: vxlanHeaders, want: 50},
+		{name: "vxlan over ipv6", hostIP: "fc00:f853:ccd:e793::2", overhead: vxlanHeaders, want: 70},
+		{name: "wireguard over ipv4", hostIP: "172.18.0.2", overhead: wireguardHeaders, want: 60},
+		{name: "wireguard over ipv6", hostIP: "fc00:f853:ccd:e793::2", overhead: wireguardHeaders, want: 80},
+		{name: "ipv4 mapped ipv6", hostIP: "::ffff:172.18.0.2", overhead: vxlanHeaders, want: 50},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := encapsulationOverhead(net.ParseIP(tt.hostIP), tt.overhead); got != tt.want {
+				t.Errorf("encapsulationOverhead() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// fakeTunnel is a Tunnel with a fixed link, for tests that do not touch
+// the network
+type fakeTunnel struct {
+	Tunnel
+	link netlink.Link
+}
+
+func (f *fakeTunnel) Link() netlink.Link {
+	return f.link
+}
+
+func TestTunnelMTU(t *testing.T) {
+	tunnel := &fakeTunnel{link: &netlink.Vxlan{LinkAttrs: netlink.LinkAttrs{Name: vxlanLinkName, MTU: 1450}}}
+	tests := []struct {
+		name   string
+		tunnel Tunnel
+		mtu    int
+		want   int
+	}{
+		{name: "no tunnel", tunnel: nil, mtu: 1500, want: 1500},
+		{name: "no tunnel and unknown mtu", tunnel: nil, mtu: 0, want: 0},
+		{name: "tunnel", tunnel: tunnel, mtu: 1500, want: 1450},
+		{name: "tunnel and unknown mtu", tunnel: tunnel, mtu: 0, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TunnelMTU(tt.tunnel, tt.mtu); got != tt.want {
+				t.Errorf("TunnelMTU() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// name of the VXLAN tunnel interface
+	vxlanLinkName = "kindnet-vxlan"
+	// VXLAN network identifier used by kindnet
+	vxlanID = 1
+	// IANA assigned VXLAN port
+	vxlanPort = 4789
+	// VXLAN and inner ethernet headers
+	vxlanHeaders = 8 + 14
+)
+
+// NewVXLANTunnel returns a new VXLANTunnel from hostIP, the interface is
+// created or recreated if its settings changed
+func NewVXLANTunnel(hostIP net.IP, mtu int) (*VXLANTunnel, error) {
+	want := &netlink.Vxlan{
+		LinkAttrs: netlink.LinkAttrs{
+			Name:         vxlanLinkName,
+			HardwareAddr: vtepMAC(hostIP),
+		},
+		VxlanId:  vxlanID,
+		SrcAddr:  hostIP,
+		Port:     vxlanPort,
+		Learning: false,
+	}
+	if mtu != 0 {
+		want.MTU = mtu - encapsulationOverhead(hostIP, vxlanHeaders)
+	}
+
+	link, err := netlink.LinkByName(vxlanLinkName)
+	if err == nil {
+		existing, ok := link.(*netlink.Vxlan)
+		if !ok || existing.VxlanId != want.VxlanId || !existing.SrcAddr.Equal(want.SrcAddr) ||
+			existing.Port != want.Port || existing.HardwareAddr.String() != want.HardwareAddr.String() ||
+			(want.MTU != 0 && existing.MTU != want.MTU) {
+			klog.Infof("Recreating interface %s with new settings", vxlanLinkName)
+			if err := netlink.LinkDel(link); err != nil {
+				return nil, fmt.Errorf("failed to delete interface %s: %w", vxlanLinkName, err)
+			}
+			link = nil
+		}
+	} else {
+		link = nil
+	}
+	if link == nil {
+		if err := netlink.LinkAdd(want); err != nil {
+			return nil, fmt.Errorf("failed to create interface %s: %w", vxlanLinkName, err)
+		}
+		if link, err = netlink.LinkByName(vxlanLinkName); err != nil {
+			return nil, err
+		}
+	}
+	return &VXLANTunnel{link: link}, nil
+}
+
+// VXLANTunnel sends the traffic to other nodes through a VXLAN interface.
+// Each node has a VTEP with a MAC address derived from its node IP, and
+// static forwarding and neighbor entries for the VTEPs of the other nodes.
+type VXLANTunnel struct {
+	link netlink.Link
+}
+
+var _ Tunnel = &VXLANTunnel{}
+
+// Link returns the VXLAN interface
+func (t *VXLANTunnel) Link() netlink.Link {
+	return t.link
+}
+
+// Annotations returns nil, the VTEP of each node is derived from its node IP
+func (t *VXLANTunnel) Annotations() map[string]string {
+	return nil
+}
+
+// EnsureLocal assigns the VTEP addresses to the VXLAN interface
+func (t *VXLANTunnel) EnsureLocal(podCIDRs []*net.IPNet) error {
+	return ensureTunnelAddrs(t.link, podCIDRs)
+}
+
+// EnsurePeer ensures the forwarding entry for the VTEP of the node at nodeIP
+// and the neighbor entries for its VTEP addresses
+func (t *VXLANTunnel) EnsurePeer(node *corev1.Node, nodeIP net.IP, podCIDRs []*net.IPNet) error {
+	mac := vtepMAC(nodeIP)
+	// frames for the peer VTEP are sent to its node IP
+	if err := netlink.NeighSet(&netlink.Neigh{
+		LinkIndex:    t.link.Attrs().Index,
+		Family:       unix.AF_BRIDGE,
+		Flags:        netlink.NTF_SELF,
+		State:        netlink.NUD_PERMANENT,
+		IP:           nodeIP,
+		HardwareAddr: mac,
+	}); err != nil {
+		return fmt.Errorf("failed to add forwarding entry for node %s: %w", node.Name, err)
+	}
+	for _, podCIDR := range podCIDRs {
+		if err := netlink.NeighSet(&netlink.Neigh{
+			LinkIndex:    t.link.Attrs().Index,
+			State:        netlink.NUD_PERMANENT,
+			IP:           tunnelIP(podCIDR),
+			HardwareAddr: mac,
+		}); err != nil {
+			return fmt.Errorf("failed to add neighbor entry for node %s: %w", node.Name, err)
+		}
+	}
+	return nil
+}
+
+// DeleteStalePeers removes the forwarding and neighbor entries for the VTEPs
+// of nodes that are not in nodeIPs
+func (t *VXLANTunnel) DeleteStalePeers(nodeIPs sets.Set[string]) error {
+	wantedMACs := sets.New[string]()
+	for _, ip := range nodeIPs.UnsortedList() {
+		wantedMACs.Insert(vtepMAC(net.ParseIP(ip)).String())
+	}
+
+	fdb, err := netlink.NeighList(t.link.Attrs().Index, unix.AF_BRIDGE)
+	if err != nil {
+		return err
+	}
+	neighbors, err := netlink.NeighList(t.link.Attrs().Index, netlink.FAMILY_ALL)
+	if err != nil {
+		return err
+	}
+	for _, neigh := range append(fdb, neighbors...) {
+		if neigh.State != netlink.NUD_PERMANENT || wantedMACs.Has(neigh.HardwareAddr.String()) {
+			continue
+		}
+		klog.Infof("Removing stale VXLAN entry %v", neigh)
+		neigh := neigh
+		if err := netlink.NeighDel(&neigh); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// vtepMAC returns the MAC address of the VTEP of the node at nodeIP, a
+// locally administered address ending with the last four bytes of the IP
+func vtepMAC(nodeIP net.IP) net.HardwareAddr {
+	ip := nodeIP.To16()
+	return net.HardwareAddr{0x0a, 0x58, ip[12], ip[13], ip[14], ip[15]}
+}
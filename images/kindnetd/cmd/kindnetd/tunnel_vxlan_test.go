@@ -0,0 +1,49 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"net"
+	"testing"
+)
+
+func TestVtepMAC(t *testing.T) {
+	tests := []struct {
+		nodeIP string
+		want   string
+	}{
+		{nodeIP: "172.18.0.2", want: "0a:58:ac:12:00:02"},
+		{nodeIP: "10.0.255.254", want: "0a:58:0a:00:ff:fe"},
+		{nodeIP: "fc00:f853:ccd:e793::2", want: "0a:58:00:00:00:02"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.nodeIP, func(t *testing.T) {
+			got := vtepMAC(net.ParseIP(tt.nodeIP))
+			if got.String() != tt.want {
+				t.Errorf("vtepMAC(%s) = %s, want %s", tt.nodeIP, got, tt.want)
+			}
+			// locally administered unicast address
+			if got[0]&0x02 == 0 || got[0]&0x01 != 0 {
+				t.Errorf("vtepMAC(%s) = %s is not a locally administered unicast address", tt.nodeIP, got)
+			}
+		})
+	}
+	// the MAC is derived only from the node IP
+	if vtepMAC(net.ParseIP("172.18.0.2")).String() != vtepMAC(net.ParseIP("172.18.0.2").To4()).String() {
+		t.Errorf("expected the same MAC for the 4 and 16 byte forms of an IP")
+	}
+}
@@ -0,0 +1,285 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"reflect"
+
+	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netlink/nl"
+	"golang.org/x/sys/unix"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// name of the WireGuard tunnel interface
+	wireguardLinkName = "kindnet-wg"
+	// port WireGuard listens on
+	wireguardPort = 51820
+	// WireGuard data message header and authentication tag
+	wireguardHeaders = 16 + 16
+	// wireguardPublicKeyAnnotation is the node annotation with the public
+	// key of the node, base64 encoded
+	wireguardPublicKeyAnnotation = "kind.x-k8s.io/kindnet-wireguard-public-key"
+)
+
+// WireGuard generic netlink API, see include/uapi/linux/wireguard.h
+const (
+	wgGenlName    = "wireguard"
+	wgGenlVersion = 1
+
+	wgCmdSetDevice = 1
+
+	wgDeviceAttrIfindex    = 1
+	wgDeviceAttrPrivateKey = 3
+	wgDeviceAttrFlags      = 5
+	wgDeviceAttrListenPort = 6
+	wgDeviceAttrPeers      = 8
+
+	wgDeviceFlagReplacePeers = 1 << 0
+
+	wgPeerAttrPublicKey  = 1
+	wgPeerAttrFlags      = 3
+	wgPeerAttrEndpoint   = 4
+	wgPeerAttrAllowedIPs = 9
+
+	wgPeerFlagRemoveMe          = 1 << 0
+	wgPeerFlagReplaceAllowedIPs = 1 << 1
+
+	wgAllowedIPAttrFamily   = 1
+	wgAllowedIPAttrIPAddr   = 2
+	wgAllowedIPAttrCIDRMask = 3
+)
+
+// NewWireGuardTunnel returns a new WireGuardTunnel, the interface is created
+// if needed and configured with a new key pair and no peers
+func NewWireGuardTunnel(hostIP net.IP, mtu int) (*WireGuardTunnel, error) {
+	link, err := netlink.LinkByName(wireguardLinkName)
+	if err != nil {
+		attrs := netlink.NewLinkAttrs()
+		attrs.Name = wireguardLinkName
+		if err := netlink.LinkAdd(&netlink.Wireguard{LinkAttrs: attrs}); err != nil {
+			return nil, fmt.Errorf("failed to create interface %s, is the wireguard kernel module loaded?: %w", wireguardLinkName, err)
+		}
+		if link, err = netlink.LinkByName(wireguardLinkName); err != nil {
+			return nil, err
+		}
+	}
+	if mtu != 0 {
+		mtu -= encapsulationOverhead(hostIP, wireguardHeaders)
+		if err := netlink.LinkSetMTU(link, mtu); err != nil {
+			return nil, err
+		}
+		link.Attrs().MTU = mtu
+	}
+
+	family, err := netlink.GenlFamilyGet(wgGenlName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the wireguard netlink family: %w", err)
+	}
+	privateKey, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	t := &WireGuardTunnel{
+		link:      link,
+		familyID:  family.ID,
+		publicKey: base64.StdEncoding.EncodeToString(privateKey.PublicKey().Bytes()),
+		peers:     map[string]wireguardPeer{},
+	}
+
+	// the peers of a previous kindnetd have the old public key of this node
+	req := t.newSetDeviceRequest()
+	req.AddData(nl.NewRtAttr(wgDeviceAttrPrivateKey, privateKey.Bytes()))
+	req.AddData(nl.NewRtAttr(wgDeviceAttrListenPort, nl.Uint16Attr(wireguardPort)))
+	req.AddData(nl.NewRtAttr(wgDeviceAttrFlags, nl.Uint32Attr(wgDeviceFlagReplacePeers)))
+	if _, err := req.Execute(unix.NETLINK_GENERIC, 0); err != nil {
+		return nil, fmt.Errorf("failed to configure interface %s: %w", wireguardLinkName, err)
+	}
+	return t, nil
+}
+
+// WireGuardTunnel sends the traffic to other nodes encrypted through a
+// WireGuard interface. The public key of each node is published in a node
+// annotation.
+type WireGuardTunnel struct {
+	link      netlink.Link
+	familyID  uint16
+	publicKey string
+	// peers are the configured peers by node IP
+	peers map[string]wireguardPeer
+}
+
+// wireguardPeer is the configuration of a peer
+type wireguardPeer struct {
+	publicKey  string
+	allowedIPs []string
+}
+
+var _ Tunnel = &WireGuardTunnel{}
+
+// Link returns the WireGuard interface
+func (t *WireGuardTunnel) Link() netlink.Link {
+	return t.link
+}
+
+// Annotations returns the public key annotation of this node
+func (t *WireGuardTunnel) Annotations() map[string]string {
+	return map[string]string{wireguardPublicKeyAnnotation: t.publicKey}
+}
+
+// EnsureLocal assigns the tunnel endpoint addresses to the WireGuard
+// interface, so traffic from this node to remote pods is sent from them
+func (t *WireGuardTunnel) EnsureLocal(podCIDRs []*net.IPNet) error {
+	return ensureTunnelAddrs(t.link, podCIDRs)
+}
+
+// EnsurePeer ensures node is a peer with its published public key, reached
+// at nodeIP, for the traffic to podCIDRs. Nodes that have not published their
+// public key yet are skipped, they are reconciled again once they do.
+func (t *WireGuardTunnel) EnsurePeer(node *corev1.Node, nodeIP net.IP, podCIDRs []*net.IPNet) error {
+	publicKey := node.Annotations[wireguardPublicKeyAnnotation]
+	if publicKey == "" {
+		klog.Infof("Node %s has no WireGuard public key yet, ignoring", node.Name)
+		return nil
+	}
+	want := wireguardPeer{publicKey: publicKey}
+	for _, podCIDR := range podCIDRs {
+		want.allowedIPs = append(want.allowedIPs, podCIDR.String())
+	}
+	current, ok := t.peers[nodeIP.String()]
+	if ok && reflect.DeepEqual(current, want) {
+		return nil
+	}
+	// the node restarted kindnetd with a new key pair
+	if ok && current.publicKey != publicKey {
+		if err := t.removePeer(current.publicKey); err != nil {
+			return err
+		}
+	}
+
+	key, err := decodeWireGuardKey(publicKey)
+	if err != nil {
+		return fmt.Errorf("invalid WireGuard public key for node %s: %w", node.Name, err)
+	}
+	peers := nl.NewRtAttr(wgDeviceAttrPeers|int(nl.NLA_F_NESTED), nil)
+	peer := peers.AddRtAttr(0|int(nl.NLA_F_NESTED), nil)
+	peer.AddRtAttr(wgPeerAttrPublicKey, key)
+	peer.AddRtAttr(wgPeerAttrFlags, nl.Uint32Attr(wgPeerFlagReplaceAllowedIPs))
+	peer.AddRtAttr(wgPeerAttrEndpoint, sockaddr(nodeIP, wireguardPort))
+	allowedIPs := peer.AddRtAttr(wgPeerAttrAllowedIPs|int(nl.NLA_F_NESTED), nil)
+	for _, podCIDR := range podCIDRs {
+		ones, _ := podCIDR.Mask.Size()
+		family, ip := uint16(unix.AF_INET), podCIDR.IP.To4()
+		if ip == nil {
+			family, ip = unix.AF_INET6, podCIDR.IP.To16()
+		}
+		allowedIP := allowedIPs.AddRtAttr(0|int(nl.NLA_F_NESTED), nil)
+		allowedIP.AddRtAttr(wgAllowedIPAttrFamily, nl.Uint16Attr(family))
+		allowedIP.AddRtAttr(wgAllowedIPAttrIPAddr, ip)
+		allowedIP.AddRtAttr(wgAllowedIPAttrCIDRMask, nl.Uint8Attr(uint8(ones)))
+	}
+	req := t.newSetDeviceRequest()
+	req.AddData(peers)
+	klog.Infof("Configuring WireGuard peer for node %s at %s\n", node.Name, nodeIP)
+	if _, err := req.Execute(unix.NETLINK_GENERIC, 0); err != nil {
+		return fmt.Errorf("failed to configure WireGuard peer for node %s: %w", node.Name, err)
+	}
+	t.peers[nodeIP.String()] = want
+	return nil
+}
+
+// DeleteStalePeers removes the peers whose node IP is not in nodeIPs
+func (t *WireGuardTunnel) DeleteStalePeers(nodeIPs sets.Set[string]) error {
+	for nodeIP, peer := range t.peers {
+		if nodeIPs.Has(nodeIP) {
+			continue
+		}
+		klog.Infof("Removing stale WireGuard peer %s\n", nodeIP)
+		if err := t.removePeer(peer.publicKey); err != nil {
+			return err
+		}
+		delete(t.peers, nodeIP)
+	}
+	return nil
+}
+
+// removePeer removes the peer with publicKey from the interface
+func (t *WireGuardTunnel) removePeer(publicKey string) error {
+	key, err := decodeWireGuardKey(publicKey)
+	if err != nil {
+		return err
+	}
+	peers := nl.NewRtAttr(wgDeviceAttrPeers|int(nl.NLA_F_NESTED), nil)
+	peer := peers.AddRtAttr(0|int(nl.NLA_F_NESTED), nil)
+	peer.AddRtAttr(wgPeerAttrPublicKey, key)
+	peer.AddRtAttr(wgPeerAttrFlags, nl.Uint32Attr(wgPeerFlagRemoveMe))
+	req := t.newSetDeviceRequest()
+	req.AddData(peers)
+	if _, err := req.Execute(unix.NETLINK_GENERIC, 0); err != nil {
+		return fmt.Errorf("failed to remove WireGuard peer: %w", err)
+	}
+	return nil
+}
+
+// newSetDeviceRequest returns a request to configure the WireGuard interface
+func (t *WireGuardTunnel) newSetDeviceRequest() *nl.NetlinkRequest {
+	req := nl.NewNetlinkRequest(int(t.familyID), unix.NLM_F_ACK)
+	req.AddData(&nl.Genlmsg{
+		Command: wgCmdSetDevice,
+		Version: wgGenlVersion,
+	})
+	req.AddData(nl.NewRtAttr(wgDeviceAttrIfindex, nl.Uint32Attr(uint32(t.link.Attrs().Index))))
+	return req
+}
+
+// decodeWireGuardKey decodes a base64 encoded WireGuard key
+func decodeWireGuardKey(s string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("expected a 32 byte key, got %d bytes", len(key))
+	}
+	return key, nil
+}
+
+// sockaddr returns ip and port encoded as a struct sockaddr_in or
+// sockaddr_in6
+func sockaddr(ip net.IP, port int) []byte {
+	if ip4 := ip.To4(); ip4 != nil {
+		b := make([]byte, unix.SizeofSockaddrInet4)
+		nl.NativeEndian().PutUint16(b[0:2], unix.AF_INET)
+		binary.BigEndian.PutUint16(b[2:4], uint16(port))
+		copy(b[4:8], ip4)
+		return b
+	}
+	b := make([]byte, unix.SizeofSockaddrInet6)
+	nl.NativeEndian().PutUint16(b[0:2], unix.AF_INET6)
+	binary.BigEndian.PutUint16(b[2:4], uint16(port))
+	copy(b[8:24], ip.To16())
+	return b
+}
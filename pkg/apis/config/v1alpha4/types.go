@@ -81,6 +81,182 @@ type Cluster struct {
 	// in the order listed.
 	// These should be YAML or JSON formatting RFC 6902 JSON patches
 	ContainerdConfigPatchesJSON6902 []string `yaml:"containerdConfigPatchesJSON6902,omitempty" json:"containerdConfigPatchesJSON6902,omitempty"`
+
+	// Registry configures a local registry container for the cluster.
+	// If set, the registry is created (or an existing container with the same
+	// name is reused) on the same network as the nodes, and every node is
+	// configured to pull localhost:<hostPort>/<image> from it.
+	Registry *Registry `yaml:"registry,omitempty" json:"registry,omitempty"`
+
+	// RegistryMirrors configures containerd on every node to pull images
+	// for a registry from mirrors, the mirror endpoints are tried in order
+	// before the registry itself.
+	RegistryMirrors []RegistryMirror `yaml:"registryMirrors,omitempty" json:"registryMirrors,omitempty"`
+
+	// RegistryAuth configures TLS verification and credentials for registry
+	// or mirror hosts on every node.
+	RegistryAuth []RegistryAuth `yaml:"registryAuth,omitempty" json:"registryAuth,omitempty"`
+
+	// Networks are additional networks for the cluster, which nodes are
+	// attached to with their own networks field. Networks that do not exist
+	// are created, and deleted with the cluster once no longer in use.
+	Networks []Network `yaml:"networks,omitempty" json:"networks,omitempty"`
+
+	// LoadBalancer configures the external load balancer in front of the
+	// control plane nodes, which is only created for clusters with multiple
+	// control plane nodes.
+	LoadBalancer *LoadBalancer `yaml:"loadBalancer,omitempty" json:"loadBalancer,omitempty"`
+}
+
+// LoadBalancer configures the external haproxy load balancer.
+// In yaml this looks like:
+//
+//	verifyAPIServer: true
+//	timeouts:
+//	  connect: 1s
+//	  checkInterval: 500ms
+//	extraFrontends:
+//	- name: ingress
+//	  port: 80
+//	  hostPort: 8080
+//	  backend:
+//	    nodeRole: worker
+//	    port: 30080
+type LoadBalancer struct {
+	// Image is the haproxy image of the load balancer.
+	// Defaults to the kind haproxy image
+	Image string `yaml:"image,omitempty" json:"image,omitempty"`
+	// Timeouts are the haproxy timeouts
+	Timeouts LoadBalancerTimeouts `yaml:"timeouts,omitempty" json:"timeouts,omitempty"`
+	// VerifyAPIServer verifies the serving certificates of the API servers
+	// against the cluster CA in the health checks of the load balancer.
+	// The API servers are not verified until kubeadm has created the CA.
+	VerifyAPIServer bool `yaml:"verifyAPIServer,omitempty" json:"verifyAPIServer,omitempty"`
+	// ExtraFrontends are additional ports of the load balancer, each
+	// balancing to a port of the nodes with a role
+	ExtraFrontends []LoadBalancerFrontend `yaml:"extraFrontends,omitempty" json:"extraFrontends,omitempty"`
+}
+
+// LoadBalancerTimeouts are haproxy timeouts, as durations such as "5s" or
+// "500ms". Unset timeouts keep their default.
+type LoadBalancerTimeouts struct {
+	// Connect is the timeout of connecting to a backend, defaults to 5s
+	Connect string `yaml:"connect,omitempty" json:"connect,omitempty"`
+	// Client is the inactivity timeout of clients, defaults to 50s
+	Client string `yaml:"client,omitempty" json:"client,omitempty"`
+	// Server is the inactivity timeout of backends, defaults to 50s
+	Server string `yaml:"server,omitempty" json:"server,omitempty"`
+	// Check is the timeout of health checks of the backends
+	Check string `yaml:"check,omitempty" json:"check,omitempty"`
+	// CheckInterval is the interval between health checks of the backends,
+	// defaults to 2s
+	CheckInterval string `yaml:"checkInterval,omitempty" json:"checkInterval,omitempty"`
+}
+
+// LoadBalancerFrontend is an additional port of the load balancer
+type LoadBalancerFrontend struct {
+	// Name is the name of the frontend, it must be unique
+	Name string `yaml:"name,omitempty" json:"name,omitempty"`
+	// Port is the port the load balancer listens on
+	Port int32 `yaml:"port,omitempty" json:"port,omitempty"`
+	// HostPort is the port on the host the frontend is published on,
+	// a random port is used if unset
+	HostPort int32 `yaml:"hostPort,omitempty" json:"hostPort,omitempty"`
+	// ListenAddress is the host address the frontend is published on,
+	// defaults to 0.0.0.0
+	ListenAddress string `yaml:"listenAddress,omitempty" json:"listenAddress,omitempty"`
+	// Backend is what the frontend balances to
+	Backend LoadBalancerBackend `yaml:"backend,omitempty" json:"backend,omitempty"`
+}
+
+// LoadBalancerBackend is the nodes a frontend balances to
+type LoadBalancerBackend struct {
+	// NodeRole selects the nodes to balance to, defaults to worker
+	NodeRole NodeRole `yaml:"nodeRole,omitempty" json:"nodeRole,omitempty"`
+	// Port is the port on the nodes
+	Port int32 `yaml:"port,omitempty" json:"port,omitempty"`
+}
+
+// Network is an additional network nodes can be attached to.
+// In yaml this looks like:
+//
+//	name: storage
+//	subnet: 192.168.100.0/24
+//	internal: true
+type Network struct {
+	// Name is the name of the network
+	Name string `yaml:"name,omitempty" json:"name,omitempty"`
+	// Subnet is the CIDR of the network, or an IPv4 and an IPv6 CIDR
+	// separated by a comma for dual-stack.
+	// The node backend will select a subnet if unspecified
+	Subnet string `yaml:"subnet,omitempty" json:"subnet,omitempty"`
+	// MTU is the MTU of the network
+	// Defaults to the MTU selected by the node backend
+	MTU int32 `yaml:"mtu,omitempty" json:"mtu,omitempty"`
+	// Internal networks have no external connectivity
+	Internal bool `yaml:"internal,omitempty" json:"internal,omitempty"`
+}
+
+// RegistryMirror configures mirrors for a registry.
+// In yaml this looks like:
+//
+//	registry: docker.io
+//	endpoints:
+//	- https://mirror.example.com:5000
+type RegistryMirror struct {
+	// Registry is the registry host to mirror, e.g. "docker.io".
+	// The special value "_default" applies to all registries that do not
+	// have mirrors of their own.
+	Registry string `yaml:"registry,omitempty" json:"registry,omitempty"`
+	// Endpoints are the mirror URLs, including the scheme.
+	Endpoints []string `yaml:"endpoints,omitempty" json:"endpoints,omitempty"`
+}
+
+// RegistryAuth configures TLS verification and credentials for a registry
+// or mirror host.
+// In yaml this looks like:
+//
+//	host: mirror.example.com:5000
+//	caFile: /etc/ssl/certs/mirror-ca.pem
+//	credentialsFile: /home/me/.docker/config.json
+type RegistryAuth struct {
+	// Host is the registry or mirror host, e.g. "mirror.example.com:5000"
+	Host string `yaml:"host,omitempty" json:"host,omitempty"`
+	// CAFile is the path on the host to a PEM encoded CA bundle used to
+	// verify the host's certificate.
+	CAFile string `yaml:"caFile,omitempty" json:"caFile,omitempty"`
+	// InsecureSkipVerify disables verifying the host's certificate.
+	InsecureSkipVerify bool `yaml:"insecureSkipVerify,omitempty" json:"insecureSkipVerify,omitempty"`
+	// CredentialsFile is the path on the host to a docker config.json
+	// style file, such as ~/.docker/config.json, with credentials for Host.
+	// Credential helpers are not supported.
+	CredentialsFile string `yaml:"credentialsFile,omitempty" json:"credentialsFile,omitempty"`
+}
+
+// Registry contains settings for a local registry container
+type Registry struct {
+	// Name is the name of the registry container, nodes reach the
+	// registry at <name>:5000.
+	//
+	// Defaults to "kind-registry"
+	Name string `yaml:"name,omitempty" json:"name,omitempty"`
+	// Image is the registry image to use when creating the container.
+	//
+	// Defaults to "docker.io/library/registry:2"
+	Image string `yaml:"image,omitempty" json:"image,omitempty"`
+	// HostPort is the port the registry is published on the host,
+	// images are pushed to localhost:<hostPort>.
+	//
+	// Defaults to 5001
+	HostPort int32 `yaml:"hostPort,omitempty" json:"hostPort,omitempty"`
+	// ListenAddress is the address the registry is published on the host.
+	//
+	// Defaults to 127.0.0.1
+	ListenAddress string `yaml:"listenAddress,omitempty" json:"listenAddress,omitempty"`
+	// Shared registries are not deleted with the cluster, so that they may
+	// be reused by other clusters. Registries created by kind create
+	// registry are always shared.
+	Shared bool `yaml:"shared,omitempty" json:"shared,omitempty"`
 }
 
 // TypeMeta partially copies apimachinery/pkg/apis/meta/v1.TypeMeta
@@ -145,6 +321,37 @@ type Node struct {
 	// The node-level patches will be applied after the cluster-level patches
 	// have been applied. (See Cluster.KubeadmConfigPatchesJSON6902)
 	KubeadmConfigPatchesJSON6902 []PatchJSON6902 `yaml:"kubeadmConfigPatchesJSON6902,omitempty" json:"kubeadmConfigPatchesJSON6902,omitempty"`
+
+	// Networks are the additional networks from the cluster networks this
+	// node is attached to, besides the network all the nodes are attached to
+	Networks []NodeNetwork `yaml:"networks,omitempty" json:"networks,omitempty"`
+
+	// IPv4Address is the static IPv4 address of the node on the node
+	// network, it must be in networking.nodeSubnet.
+	// If unset the container runtime picks an address.
+	IPv4Address string `yaml:"ipv4Address,omitempty" json:"ipv4Address,omitempty"`
+	// IPv6Address is the static IPv6 address of the node on the node
+	// network, it must be in networking.nodeSubnet.
+	// If unset the container runtime picks an address.
+	IPv6Address string `yaml:"ipv6Address,omitempty" json:"ipv6Address,omitempty"`
+}
+
+// NodeNetwork attaches a node to an additional network.
+// In yaml this looks like:
+//
+//	name: storage
+//	ipv4Address: 192.168.100.10
+type NodeNetwork struct {
+	// Name is the name of a network in the cluster networks
+	Name string `yaml:"name,omitempty" json:"name,omitempty"`
+	// IPv4Address is a static IPv4 address for the node on the network, it
+	// requires the network subnet to be set
+	// The node backend will select an address if unspecified
+	IPv4Address string `yaml:"ipv4Address,omitempty" json:"ipv4Address,omitempty"`
+	// IPv6Address is a static IPv6 address for the node on the network, it
+	// requires the network subnet to be set
+	// The node backend will select an address if unspecified
+	IPv6Address string `yaml:"ipv6Address,omitempty" json:"ipv6Address,omitempty"`
 }
 
 // NodeRole defines possible role for nodes in a Kubernetes cluster managed by `kind`
@@ -177,6 +384,11 @@ type Networking struct {
 	//
 	// Defaults to 127.0.0.1
 	APIServerAddress string `yaml:"apiServerAddress,omitempty" json:"apiServerAddress,omitempty"`
+	// NodeSubnet is the CIDR of the network the nodes are attached to, two
+	// comma separated CIDRs for dual stack clusters. The network is created
+	// with it and must have it if it already exists.
+	// The container runtime selects the subnet if unspecified
+	NodeSubnet string `yaml:"nodeSubnet,omitempty" json:"nodeSubnet,omitempty"`
 	// PodSubnet is the CIDR used for pod IPs
 	// kind will select a default if unspecified
 	PodSubnet string `yaml:"podSubnet,omitempty" json:"podSubnet,omitempty"`
@@ -191,6 +403,33 @@ type Networking struct {
 	KubeProxyMode ProxyMode `yaml:"kubeProxyMode,omitempty" json:"kubeProxyMode,omitempty"`
 	// DNSSearch defines the DNS search domain to use for nodes. If not set, this will be inherited from the host.
 	DNSSearch *[]string `yaml:"dnsSearch,omitempty" json:"dnsSearch,omitempty"`
+	// Kindnet configures the default CNI, it is ignored when DisableDefaultCNI
+	// is true
+	Kindnet *Kindnet `yaml:"kindnet,omitempty" json:"kindnet,omitempty"`
+}
+
+// Kindnet contains options for kindnetd, the default CNI
+//
+// These options are not supported by the kindnetd in the node images yet,
+// and are rejected other than the default Encapsulation none
+type Kindnet struct {
+	// MTU is the MTU of the pod interfaces
+	// Defaults to the MTU of the node's eth0 interface
+	MTU int32 `yaml:"mtu,omitempty" json:"mtu,omitempty"`
+	// NoMasqueradeCIDRs are destinations that traffic from pods is not
+	// masqueraded to, in addition to the pod subnets
+	NoMasqueradeCIDRs []string `yaml:"noMasqueradeCIDRs,omitempty" json:"noMasqueradeCIDRs,omitempty"`
+	// ChainedPlugins are CNI plugin configurations appended to the kindnet
+	// plugin chain, after the ptp and portmap plugins.
+	// Each entry should be an inline JSON object blob-string, for example:
+	// {"type": "bandwidth", "capabilities": {"bandwidth": true}}
+	ChainedPlugins []string `yaml:"chainedPlugins,omitempty" json:"chainedPlugins,omitempty"`
+	// Encapsulation is how traffic to the pods of other nodes is sent, one of
+	// none, vxlan or wireguard. With vxlan or wireguard the node IPs need to be
+	// reachable from each other, but the nodes need not share an L2 network.
+	// wireguard requires the wireguard kernel module on the host.
+	// Defaults to none, routing the traffic directly via the node IPs
+	Encapsulation EncapsulationMode `yaml:"encapsulation,omitempty" json:"encapsulation,omitempty"`
 }
 
 // ClusterIPFamily defines cluster network IP family
@@ -217,6 +456,19 @@ const (
 	NFTablesProxyMode ProxyMode = "nftables"
 )
 
+// EncapsulationMode defines how kindnetd sends traffic to the pods of other
+// nodes
+type EncapsulationMode string
+
+const (
+	// NoEncapsulation sets EncapsulationMode to none
+	NoEncapsulation EncapsulationMode = "none"
+	// VXLANEncapsulation sets EncapsulationMode to vxlan
+	VXLANEncapsulation EncapsulationMode = "vxlan"
+	// WireGuardEncapsulation sets EncapsulationMode to wireguard
+	WireGuardEncapsulation EncapsulationMode = "wireguard"
+)
+
 // PatchJSON6902 represents an inline kustomize json 6902 patch
 // https://tools.ietf.org/html/rfc6902
 type PatchJSON6902 struct {
@@ -21,8 +21,11 @@ import (
 	"net/url"
 	"os"
 	"runtime"
+	"strings"
 
+	"sigs.k8s.io/kind/pkg/build/nodeimage/internal/container/docker"
 	"sigs.k8s.io/kind/pkg/build/nodeimage/internal/kube"
+	"sigs.k8s.io/kind/pkg/build/nodeimage/internal/oci"
 	"sigs.k8s.io/kind/pkg/errors"
 	"sigs.k8s.io/kind/pkg/internal/version"
 	"sigs.k8s.io/kind/pkg/log"
@@ -45,76 +48,201 @@ func Build(options ...Option) error {
 		}
 	}
 
+	archs := ctx.archs
+	if len(archs) == 0 {
+		archs = []string{ctx.arch}
+	}
 	// verify that we're using a supported arch
-	if !supportedArch(ctx.arch) {
-		ctx.logger.Warnf("unsupported architecture %q", ctx.arch)
+	for _, arch := range archs {
+		if !supportedArch(arch) {
+			ctx.logger.Warnf("unsupported architecture %q", arch)
+		}
+	}
+	if len(archs) > 1 && ctx.ociLayout == "" && !ctx.push {
+		return errors.New("building multiple architectures requires writing the image to an OCI layout or pushing it")
+	}
+	if len(archs) > 1 && ctx.dockerArchive != "" {
+		return errors.New("multiple architectures can't be written to a docker archive")
+	}
+	if ctx.daemonless {
+		cleanup, err := ctx.initLayout()
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+	}
+
+	// a version marker may move while the architectures are built, resolve
+	// it once so that all of them are built from the same version
+	if err := ctx.resolveKubeVersion(); err != nil {
+		return err
+	}
+
+	// build the image of each architecture, tagged per architecture if
+	// there are multiple
+	built := []builtImage{}
+	for _, arch := range archs {
+		archCtx := *ctx
+		archCtx.arch = arch
+		if len(archs) > 1 {
+			repository, tag, err := docker.SplitImage(ctx.image)
+			if err != nil {
+				return err
+			}
+			archCtx.image = repository + ":" + tag + "-" + arch
+			ctx.logger.V(0).Infof("Building node image %q for %s ...", archCtx.image, arch)
+		}
+		if err := archCtx.setArchKubeParam(len(archs) > 1); err != nil {
+			return err
+		}
+		if err := archCtx.initBuilder(); err != nil {
+			return err
+		}
+		if ctx.daemonless && archCtx.buildType != "release" && archCtx.buildType != "url" && archCtx.buildType != "file" {
+			return errors.New("daemonless builds require building from a release, url or file")
+		}
+		// do the actual build
+		if err := archCtx.Build(); err != nil {
+			return err
+		}
+		built = append(built, builtImage{arch: arch, image: archCtx.image, manifest: archCtx.manifest})
+	}
+	return ctx.publish(built)
+}
+
+// initLayout validates the options of daemonless builds, and sets the OCI
+// layout to build in, which is a temporary one if the image is only written
+// to a docker archive
+func (c *buildContext) initLayout() (cleanup func(), err error) {
+	if c.ociLayout == "" && c.dockerArchive == "" {
+		return nil, errors.New("daemonless builds require writing the image to an OCI layout or a docker archive")
+	}
+	if c.push {
+		return nil, errors.New("daemonless builds can't push the image, push the OCI layout or docker archive instead")
+	}
+	if len(c.registryMirrors) > 0 || len(c.registryAuth) > 0 {
+		return nil, errors.New("daemonless builds do not support registry mirrors and auth")
+	}
+
+	cleanup = func() {}
+	dir := c.ociLayout
+	if dir == "" {
+		if dir, err = os.MkdirTemp("", "kind-node-image-"); err != nil {
+			return nil, err
+		}
+		cleanup = func() { os.RemoveAll(dir) }
+	}
+	if c.layout, err = oci.NewLayout(dir); err != nil {
+		cleanup()
+		return nil, err
+	}
+	return cleanup, nil
+}
+
+// resolveKubeVersion replaces a version marker in the kubernetes param of
+// release builds with the version it points to
+func (c *buildContext) resolveKubeVersion() error {
+	buildType := c.buildType
+	if buildType == "" {
+		buildType = detectBuildType(c.kubeParam)
+	}
+	if buildType != "release" || !kube.IsReleaseMarker(c.kubeParam) {
+		return nil
 	}
+	resolved, err := kube.ResolveVersion(c.logger, c.kubeParam, c.artifactBaseURL)
+	if err != nil {
+		return err
+	}
+	c.kubeParam = resolved
+	return nil
+}
 
-	if ctx.buildType == "" {
-		ctx.buildType = detectBuildType(ctx.kubeParam)
-		if ctx.buildType != "" {
-			ctx.logger.V(0).Infof("Detected build type: %q", ctx.buildType)
+// setArchKubeParam replaces ArchPlaceholder in the kubernetes param with
+// the architecture of the build. Urls and files are built for a single
+// architecture, so multiArch builds from them require the placeholder.
+func (c *buildContext) setArchKubeParam(multiArch bool) error {
+	param := strings.ReplaceAll(c.kubeParam, ArchPlaceholder, c.arch)
+	buildType := c.buildType
+	if buildType == "" {
+		buildType = detectBuildType(param)
+	}
+	if multiArch && (buildType == "url" || buildType == "file") && !strings.Contains(c.kubeParam, ArchPlaceholder) {
+		return errors.Errorf("building multiple architectures from a %s requires %s in it", buildType, ArchPlaceholder)
+	}
+	c.kubeParam = param
+	return nil
+}
+
+// initBuilder sets the kube.Builder for the build type and kubernetes param
+func (c *buildContext) initBuilder() error {
+	if c.buildType == "" {
+		c.buildType = detectBuildType(c.kubeParam)
+		if c.buildType != "" {
+			c.logger.V(0).Infof("Detected build type: %q", c.buildType)
 		}
 	}
 
-	if ctx.buildType == "url" {
-		ctx.logger.V(0).Infof("Building using URL: %q", ctx.kubeParam)
-		builder, err := kube.NewURLBuilder(ctx.logger, ctx.kubeParam)
+	if c.buildType == "url" {
+		c.logger.V(0).Infof("Building using URL: %q", c.kubeParam)
+		builder, err := kube.NewURLBuilder(c.logger, c.kubeParam)
 		if err != nil {
 			return err
 		}
-		ctx.builder = builder
+		c.builder = builder
 	}
 
-	if ctx.buildType == "file" {
-		ctx.logger.V(0).Infof("Building using local file: %q", ctx.kubeParam)
-		if info, err := os.Stat(ctx.kubeParam); err == nil && info.Mode().IsRegular() {
-			builder, err := kube.NewTarballBuilder(ctx.logger, ctx.kubeParam)
+	if c.buildType == "file" {
+		c.logger.V(0).Infof("Building using local file: %q", c.kubeParam)
+		if info, err := os.Stat(c.kubeParam); err == nil && info.Mode().IsRegular() {
+			builder, err := kube.NewTarballBuilder(c.logger, c.kubeParam)
 			if err != nil {
 				return err
 			}
-			ctx.builder = builder
+			c.builder = builder
 		}
 	}
 
-	if ctx.buildType == "release" {
-		ctx.logger.V(0).Infof("Building using release %q artifacts", ctx.kubeParam)
-		kubever, err := version.ParseSemantic(ctx.kubeParam)
-		if err == nil {
-			builder, err := kube.NewReleaseBuilder(ctx.logger, "v"+kubever.String(), ctx.arch)
+	if c.buildType == "release" {
+		c.logger.V(0).Infof("Building using release %q artifacts", c.kubeParam)
+		kubever, err := version.ParseSemantic(c.kubeParam)
+		if err == nil || kube.IsReleaseMarker(c.kubeParam) {
+			release := c.kubeParam
+			if err == nil {
+				release = "v" + kubever.String()
+			}
+			builder, err := kube.NewReleaseBuilder(c.logger, release, c.arch, c.artifactBaseURL)
 			if err != nil {
 				return err
 			}
-			ctx.builder = builder
+			c.builder = builder
 		} else {
-			if _, err := os.Stat(ctx.kubeParam); err != nil {
-				ctx.logger.V(0).Infof("%s is not a valid kubernetes version", ctx.kubeParam)
-				return fmt.Errorf("%s is not a valid kubernetes version", ctx.kubeParam)
+			if _, err := os.Stat(c.kubeParam); err != nil {
+				c.logger.V(0).Infof("%s is not a valid kubernetes version", c.kubeParam)
+				return fmt.Errorf("%s is not a valid kubernetes version", c.kubeParam)
 			}
 		}
 	}
 
-	if ctx.builder == nil {
+	if c.builder == nil {
 		// locate sources if no kubernetes source was specified
-		if ctx.kubeParam == "" {
+		if c.kubeParam == "" {
 			kubeRoot, err := kube.FindSource()
 			if err != nil {
 				return errors.Wrap(err, "error finding kuberoot")
 			}
-			ctx.kubeParam = kubeRoot
+			c.kubeParam = kubeRoot
 		}
-		ctx.logger.V(0).Infof("Building using source: %q", ctx.kubeParam)
+		c.logger.V(0).Infof("Building using source: %q", c.kubeParam)
 
 		// initialize bits
-		builder, err := kube.NewDockerBuilder(ctx.logger, ctx.kubeParam, ctx.arch)
+		builder, err := kube.NewDockerBuilder(c.logger, c.kubeParam, c.arch)
 		if err != nil {
 			return err
 		}
-		ctx.builder = builder
+		c.builder = builder
 	}
 
-	// do the actual build
-	return ctx.Build()
+	return nil
 }
 
 // detectBuildType detect the type of build required based on the param passed in the following order
@@ -122,6 +250,7 @@ func Build(options ...Option) error {
 // file: if the param refers to an existing regular file
 // source: if the param refers to an existing directory
 // release: if the param is a semantic version expression (does this require the v preprended?
+// release: if the param is a version marker like latest-1.31 or ci/latest, or a CI build like ci/v1.32.0-alpha.1.40+0123abcd
 func detectBuildType(param string) string {
 	u, err := url.ParseRequestURI(param)
 	if err == nil {
@@ -138,7 +267,7 @@ func detectBuildType(param string) string {
 		}
 	}
 	_, err = version.ParseSemantic(param)
-	if err == nil {
+	if err == nil || kube.IsReleaseMarker(param) {
 		return "release"
 	}
 	return ""
@@ -21,6 +21,7 @@ import (
 	"math/rand"
 	"os"
 	"path"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -30,6 +31,9 @@ import (
 
 	"sigs.k8s.io/kind/pkg/build/nodeimage/internal/container/docker"
 	"sigs.k8s.io/kind/pkg/build/nodeimage/internal/kube"
+	"sigs.k8s.io/kind/pkg/build/nodeimage/internal/oci"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/containerd"
 	"sigs.k8s.io/kind/pkg/internal/sets"
 	"sigs.k8s.io/kind/pkg/internal/version"
 )
@@ -43,6 +47,12 @@ const (
 	noProxy = "NO_PROXY"
 )
 
+// extraFile is a file or directory of the host to copy into the image
+type extraFile struct {
+	hostPath string
+	nodePath string
+}
+
 // buildContext is used to build the kind node image, and contains
 // build configuration
 type buildContext struct {
@@ -53,8 +63,28 @@ type buildContext struct {
 	arch      string
 	buildType string
 	kubeParam string
+	// archs are set for multi-architecture builds
+	archs []string
+	// outputs of the image besides the docker image store
+	ociLayout     string
+	dockerArchive string
+	push          bool
+	// daemonless builds write the image to layout instead of docker
+	daemonless bool
+	// registry mirrors and auth to pre-pull images with
+	registryMirrors []config.RegistryMirror
+	registryAuth    []config.RegistryAuth
+	// user additions to the node
+	extraImages        []string
+	extraImageArchives []string
+	extraFiles         []extraFile
+	// artifactBaseURL is where release and CI artifacts are downloaded from
+	artifactBaseURL string
 	// non-option fields
 	builder kube.Builder
+	layout  *oci.Layout
+	// manifest is the image manifest written by daemonless builds
+	manifest *oci.Descriptor
 }
 
 // Build builds the cluster node image, the source dir must be set on
@@ -69,8 +99,11 @@ func (c *buildContext) Build() (err error) {
 	}
 	c.logger.V(0).Info("Finished building Kubernetes")
 
-	// then perform the actual docker image build
+	// then perform the actual image build
 	c.logger.V(0).Info("Building node image ...")
+	if c.daemonless {
+		return c.buildLayoutImage(bits)
+	}
 	return c.buildImage(bits)
 }
 
@@ -81,7 +114,7 @@ func (c *buildContext) buildImage(bits kube.Bits) error {
 	// if docker gets proper squash support, we can rm them instead
 	// This also allows the KubeBit implementations to programmatically
 	// install in the image
-	containerID, err := c.createBuildContainer()
+	containerID, err := c.createBuildContainer(c.baseImage)
 	cmder := docker.ContainerCmder(containerID)
 
 	// ensure we will delete it
@@ -127,11 +160,50 @@ func (c *buildContext) buildImage(bits kube.Bits) error {
 
 	// pre-pull images that were not part of the build and write CNI / storage
 	// manifests
-	if _, err = c.prePullImagesAndWriteManifests(bits, parsedVersion, containerID); err != nil {
+	images, err := c.prePullImagesAndWriteManifests(bits, parsedVersion, containerID)
+	if err != nil {
 		c.logger.Errorf("Image build Failed! Failed to pull Images: %v", err)
 		return err
 	}
 
+	// copy the extra files last, they may replace the files of kind
+	for _, f := range c.extraFiles {
+		src, dir := f.hostPath, path.Dir(f.nodePath)
+		// copy the contents of directories, even if the node path exists
+		if info, err := os.Stat(f.hostPath); err == nil && info.IsDir() {
+			src, dir = filepath.Join(f.hostPath, ".")+string(filepath.Separator)+".", f.nodePath
+		}
+		if err := cmder.Command("mkdir", "-p", dir).Run(); err != nil {
+			return err
+		}
+		if err := exec.Command("docker", "cp", src, containerID+":"+f.nodePath).Run(); err != nil {
+			c.logger.Errorf("Image build Failed! Failed to copy extra file %q: %v", f.hostPath, err)
+			return err
+		}
+	}
+
+	// record what went into the image
+	baseDigest, err := c.baseImageDigest()
+	if err != nil {
+		return err
+	}
+	manifest, err := c.newBuildManifest(bits, baseDigest, images, map[string]string{
+		"/kind/version":                rawVersion,
+		defaultCNIManifestLocation:     defaultCNIManifest,
+		defaultStorageManifestLocation: defaultStorageManifest,
+	})
+	if err != nil {
+		c.logger.Errorf("Image build Failed! Failed to create build manifest: %v", err)
+		return err
+	}
+	encoded, err := manifest.encode()
+	if err != nil {
+		return err
+	}
+	if err := createFile(cmder, buildManifestPath, encoded); err != nil {
+		return err
+	}
+
 	// Save the image changes to a new image
 	if err = exec.Command(
 		"docker", "commit",
@@ -163,18 +235,14 @@ func (c *buildContext) getBuiltImages(bits kube.Bits) (sets.String, error) {
 	return images, nil
 }
 
-// must be run after kubernetes has been installed on the node
-func (c *buildContext) prePullImagesAndWriteManifests(bits kube.Bits, parsedVersion *version.Version, containerID string) ([]string, error) {
-	// first get the images we actually built
+// getFixedBuiltImages returns the image tags that will be side-loaded,
+// mapped to the tags they are loaded as
+func (c *buildContext) getFixedBuiltImages(bits kube.Bits) (map[string]string, error) {
 	builtImages, err := c.getBuiltImages(bits)
 	if err != nil {
-		c.logger.Errorf("Image build Failed! Failed to get built images: %v", err)
 		return nil, err
 	}
 
-	// helpers to run things in the build container
-	cmder := docker.ContainerCmder(containerID)
-
 	// For kubernetes v1.15+ (actually 1.16 alpha versions) we may need to
 	// drop the arch suffix from images to get the expected image
 	archSuffix := "-" + c.arch
@@ -189,7 +257,6 @@ func (c *buildContext) prePullImagesAndWriteManifests(bits kube.Bits, parsedVers
 
 	// Determine accurate built tags using the logic that will be applied
 	// when rewriting tags during archive loading
-	fixedImages := sets.NewString()
 	fixedImagesMap := make(map[string]string, builtImages.Len()) // key: original images, value: fixed images
 	for _, image := range builtImages.List() {
 		registry, tag, err := docker.SplitImage(image)
@@ -198,12 +265,28 @@ func (c *buildContext) prePullImagesAndWriteManifests(bits kube.Bits, parsedVers
 		}
 		registry = fixRepository(registry)
 		fixedImage := registry + ":" + tag
-		fixedImages.Insert(fixedImage)
 		fixedImagesMap[image] = fixedImage
 	}
-	builtImages = fixedImages
+	return fixedImagesMap, nil
+}
+
+// must be run after kubernetes has been installed on the node
+func (c *buildContext) prePullImagesAndWriteManifests(bits kube.Bits, parsedVersion *version.Version, containerID string) ([]imageDigest, error) {
+	// first get the images we actually built
+	fixedImagesMap, err := c.getFixedBuiltImages(bits)
+	if err != nil {
+		c.logger.Errorf("Image build Failed! Failed to get built images: %v", err)
+		return nil, err
+	}
+	builtImages := sets.NewString()
+	for _, fixed := range fixedImagesMap {
+		builtImages.Insert(fixed)
+	}
 	c.logger.V(1).Info("Detected built images: " + strings.Join(builtImages.List(), ", "))
 
+	// helpers to run things in the build container
+	cmder := docker.ContainerCmder(containerID)
+
 	// gets the list of images required by kubeadm
 	requiredImages, err := exec.OutputLines(cmder.Command(
 		"kubeadm", "config", "images", "list", "--kubernetes-version", bits.Version(),
@@ -251,9 +334,24 @@ func (c *buildContext) prePullImagesAndWriteManifests(bits kube.Bits, parsedVers
 	}
 	// all builds should install the default storage driver images currently
 	requiredImages = append(requiredImages, defaultStorageImages...)
+	requiredImages = append(requiredImages, c.extraImages...)
+
+	// write the registry config to pull with, it must not end up in the image
+	registry, err := c.writeRegistryConfig(cmder)
+	if err != nil {
+		c.logger.Errorf("Image build Failed! Failed to write registry config: %v", err)
+		return nil, err
+	}
+	if registry != nil {
+		defer func() {
+			if err := cmder.Command("rm", "-rf", registry.Dir).Run(); err != nil {
+				c.logger.Errorf("Image build Failed! Failed to remove registry config: %v", err)
+			}
+		}()
+	}
 
 	// setup image importer
-	importer := newContainerdImporter(cmder)
+	importer := newContainerdImporter(cmder, registry)
 	if err := importer.Prepare(); err != nil {
 		c.logger.Errorf("Image build Failed! Failed to prepare containerd to load images %v", err)
 		return nil, err
@@ -295,7 +393,8 @@ func (c *buildContext) prePullImagesAndWriteManifests(bits kube.Bits, parsedVers
 
 	// create a plan of image loading
 	loadFns := []func() error{}
-	for _, image := range bits.ImagePaths() {
+	archives := append(append([]string{}, bits.ImagePaths()...), c.extraImageArchives...)
+	for _, image := range archives {
 		image := image // capture loop var
 		loadFns = append(loadFns, func() error {
 			f, err := os.Open(image)
@@ -331,13 +430,38 @@ func (c *buildContext) prePullImagesAndWriteManifests(bits kube.Bits, parsedVers
 		return nil, err
 	}
 
-	return importer.ListImported()
+	digests, err := importer.ListImportedDigests()
+	if err != nil {
+		return nil, err
+	}
+	images := []imageDigest{}
+	for name, digest := range digests {
+		images = append(images, imageDigest{Name: name, Digest: digest})
+	}
+	return images, nil
+}
+
+// writeRegistryConfig creates the directory for the registry hosts config
+// of the configured registry mirrors and auth in the build container, if
+// there are any. The importer writes the config for each pull to it.
+func (c *buildContext) writeRegistryConfig(cmder exec.Cmder) (*containerd.RegistryConfig, error) {
+	if len(c.registryMirrors) == 0 && len(c.registryAuth) == 0 {
+		return nil, nil
+	}
+	registry, err := containerd.NewRegistryConfig(buildRegistryHostsDir, c.registryMirrors, c.registryAuth)
+	if err != nil {
+		return nil, err
+	}
+	if err := cmder.Command("install", "-d", "-m", "0700", registry.Dir).Run(); err != nil {
+		return nil, errors.Wrapf(err, "failed to create %s", registry.Dir)
+	}
+	return registry, nil
 }
 
-func (c *buildContext) createBuildContainer() (id string, err error) {
+func (c *buildContext) createBuildContainer(image string) (id string, err error) {
 	// attempt to explicitly pull the image if it doesn't exist locally
 	// errors here are non-critical; we'll proceed with execution, which includes a pull operation
-	_ = docker.Pull(c.logger, c.baseImage, dockerBuildOsAndArch(c.arch), 4)
+	_ = docker.Pull(c.logger, image, dockerBuildOsAndArch(c.arch), 4)
 	// this should be good enough: a specific prefix, the current unix time,
 	// and a little random bits in case we have multiple builds simultaneously
 	random := rand.New(rand.NewSource(time.Now().UnixNano())).Int31()
@@ -361,7 +485,7 @@ func (c *buildContext) createBuildContainer() (id string, err error) {
 		}
 	}
 	err = docker.Run(
-		c.baseImage,
+		image,
 		runArgs,
 		[]string{
 			"infinity", // sleep infinitely to keep container running indefinitely
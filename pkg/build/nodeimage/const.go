@@ -22,4 +22,6 @@ const (
 	kubernetesVersionLocation      = "/kind/version"
 	defaultCNIManifestLocation     = "/kind/manifests/default-cni.yaml"
 	defaultStorageManifestLocation = "/kind/manifests/default-storage.yaml"
+	// buildRegistryHostsDir only exists while pre-pulling images
+	buildRegistryHostsDir = "/kind/build/certs.d"
 )
@@ -17,19 +17,27 @@ limitations under the License.
 package nodeimage
 
 import (
+	"crypto/sha256"
+	"fmt"
 	"io"
+	"path"
+	"strings"
 
 	"sigs.k8s.io/kind/pkg/errors"
 	"sigs.k8s.io/kind/pkg/exec"
+	"sigs.k8s.io/kind/pkg/internal/containerd"
 )
 
 type containerdImporter struct {
 	containerCmder exec.Cmder
+	// registry is the registry config to pull with, if any
+	registry *containerd.RegistryConfig
 }
 
-func newContainerdImporter(containerCmder exec.Cmder) *containerdImporter {
+func newContainerdImporter(containerCmder exec.Cmder, registry *containerd.RegistryConfig) *containerdImporter {
 	return &containerdImporter{
 		containerCmder: containerCmder,
+		registry:       registry,
 	}
 }
 
@@ -69,9 +77,22 @@ func (c *containerdImporter) End() error {
 }
 
 func (c *containerdImporter) Pull(image, platform string) error {
-	return c.containerCmder.Command(
-		"ctr", "--namespace=k8s.io", "content", "fetch", "--platform="+platform, image,
-	).SetStdout(io.Discard).SetStderr(io.Discard).Run()
+	args := []string{"--namespace=k8s.io", "content", "fetch", "--platform=" + platform}
+	if c.registry != nil {
+		// pulls run concurrently, each gets its own hosts config with the
+		// credentials for image, never on the command line
+		dir := path.Join(c.registry.Dir, "pull", fmt.Sprintf("%x", sha256.Sum256([]byte(image+" "+platform))))
+		pull, err := c.registry.PullConfig(dir, image)
+		if err != nil {
+			return err
+		}
+		if err := pull.Write(c.containerCmder); err != nil {
+			return err
+		}
+		args = append(args, "--hosts-dir="+pull.Dir)
+	}
+	args = append(args, image)
+	return c.containerCmder.Command("ctr", args...).SetStdout(io.Discard).SetStderr(io.Discard).Run()
 }
 
 func (c *containerdImporter) LoadCommand() exec.Cmd {
@@ -87,6 +108,20 @@ func (c *containerdImporter) Tag(src, target string) error {
 	).Run()
 }
 
-func (c *containerdImporter) ListImported() ([]string, error) {
-	return exec.OutputLines(c.containerCmder.Command("ctr", "--namespace=k8s.io", "images", "list", "-q"))
+// ListImportedDigests returns the digests of the imported images by name
+func (c *containerdImporter) ListImportedDigests() (map[string]string, error) {
+	lines, err := exec.OutputLines(c.containerCmder.Command("ctr", "--namespace=k8s.io", "images", "list"))
+	if err != nil {
+		return nil, err
+	}
+	digests := map[string]string{}
+	for _, line := range lines {
+		// REF TYPE DIGEST SIZE PLATFORMS LABELS
+		fields := strings.Fields(line)
+		if len(fields) < 3 || fields[0] == "REF" || strings.Contains(fields[0], "@") || strings.HasPrefix(fields[0], "sha256:") {
+			continue
+		}
+		digests[fields[0]] = fields[2]
+	}
+	return digests, nil
 }
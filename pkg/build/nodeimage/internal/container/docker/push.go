@@ -0,0 +1,40 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docker
+
+import (
+	"sigs.k8s.io/kind/pkg/exec"
+)
+
+// Push pushes image to its registry, as in `docker push`
+func Push(image string) error {
+	return exec.Command("docker", "push", image).Run()
+}
+
+// PushManifestList pushes a manifest list named image of the already pushed
+// images, the platform of each image is read from the registry
+func PushManifestList(image string, images []string) error {
+	// a local manifest list left behind by an earlier failure would be
+	// refused by create, it is fine if there is none
+	_ = exec.Command("docker", "manifest", "rm", image).Run()
+	args := append([]string{"manifest", "create", image}, images...)
+	if err := exec.Command("docker", args...).Run(); err != nil {
+		return err
+	}
+	// --purge removes the local copy after pushing
+	return exec.Command("docker", "manifest", "push", "--purge", image).Run()
+}
@@ -28,6 +28,16 @@ type Bits interface {
 	ImagePaths() []string
 	// Version
 	Version() string
+	// Source describes the artifacts the bits were built from
+	Source() Source
+}
+
+// Source describes the artifacts Bits were built from
+type Source struct {
+	// URL is the url the server tarball was downloaded from, if any
+	URL string
+	// SHA256 is the checksum of the server tarball, if built from one
+	SHA256 string
 }
 
 // shared real bits implementation for now
@@ -37,6 +47,7 @@ type bits struct {
 	binaryPaths []string
 	imagePaths  []string
 	version     string
+	source      Source
 }
 
 var _ Bits = &bits{}
@@ -52,3 +63,7 @@ func (b *bits) ImagePaths() []string {
 func (b *bits) Version() string {
 	return b.version
 }
+
+func (b *bits) Source() Source {
+	return b.source
+}
@@ -17,24 +17,44 @@ limitations under the License.
 package kube
 
 import (
-	"context"
 	"fmt"
-	"io"
-	"net"
-	"net/http"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
-	"time"
 
 	"sigs.k8s.io/kind/pkg/errors"
 	"sigs.k8s.io/kind/pkg/log"
 )
 
+// DefaultArtifactBaseURL is the default base url of the kubernetes release
+// and CI build artifacts
+const DefaultArtifactBaseURL = "https://dl.k8s.io"
+
+var (
+	// releaseMarker matches release version markers like latest or
+	// stable-1.31, published as release/<marker>.txt
+	releaseMarker = regexp.MustCompile(`^(latest|stable)(-\d+\.\d+)?$`)
+	// ciMarker matches CI version markers like ci/latest-1.31, published
+	// as ci/<marker>.txt
+	ciMarker = regexp.MustCompile(`^ci/latest(-\d+\.\d+)?$`)
+	// ciBuild matches CI build versions like ci/v1.32.0-alpha.1.40+0123abcd
+	ciBuild = regexp.MustCompile(`^ci/v\d+\.\d+\.\d+[-+.0-9A-Za-z]*$`)
+)
+
+// IsReleaseMarker returns true if param is a version marker or a CI build
+// which ResolveVersion and NewReleaseBuilder can build from
+func IsReleaseMarker(param string) bool {
+	return releaseMarker.MatchString(param) || ciMarker.MatchString(param) || ciBuild.MatchString(param)
+}
+
 type remoteBuilder struct {
-	version string
-	logger  log.Logger
-	url     string
+	version    string
+	arch       string
+	baseURL    string
+	logger     log.Logger
+	url        string
+	downloader *downloader
 }
 
 var _ Builder = &remoteBuilder{}
@@ -42,34 +62,90 @@ var _ Builder = &remoteBuilder{}
 // NewURLBuilder used to specify a complete url to a gzipped tarball
 func NewURLBuilder(logger log.Logger, url string) (Builder, error) {
 	return &remoteBuilder{
-		version: "",
-		logger:  logger,
-		url:     url,
+		version:    "",
+		logger:     logger,
+		url:        url,
+		downloader: newDownloader(logger),
 	}, nil
 }
 
-// NewReleaseBuilder used to specify a release semver and constructs a url to release artifacts
-func NewReleaseBuilder(logger log.Logger, version, arch string) (Builder, error) {
-	url := "https://dl.k8s.io/" + version + "/kubernetes-server-linux-" + arch + ".tar.gz"
+// NewReleaseBuilder used to specify a release semver or a CI build like
+// ci/v1.32.0-alpha.1.40+0123abcd and constructs a url to its artifacts under
+// baseURL. Version markers must be resolved with ResolveVersion first.
+func NewReleaseBuilder(logger log.Logger, version, arch, baseURL string) (Builder, error) {
+	if releaseMarker.MatchString(version) || ciMarker.MatchString(version) {
+		return nil, errors.Errorf("version marker %q must be resolved first", version)
+	}
+	if baseURL == "" {
+		baseURL = DefaultArtifactBaseURL
+	}
 	return &remoteBuilder{
-		version: version,
-		logger:  logger,
-		url:     url,
+		version:    version,
+		arch:       arch,
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		logger:     logger,
+		downloader: newDownloader(logger),
 	}, nil
 }
 
+// ResolveVersion resolves the version marker version, like latest-1.31 or
+// ci/latest, to the release or CI build it points to under baseURL.
+// Other versions are returned as they are.
+func ResolveVersion(logger log.Logger, version, baseURL string) (string, error) {
+	return resolveVersion(newDownloader(logger), logger, version, baseURL)
+}
+
+func resolveVersion(d *downloader, logger log.Logger, version, baseURL string) (string, error) {
+	if !releaseMarker.MatchString(version) && !ciMarker.MatchString(version) {
+		return version, nil
+	}
+	if baseURL == "" {
+		baseURL = DefaultArtifactBaseURL
+	}
+	bucket, marker, prefix := "release", version, ""
+	if strings.HasPrefix(version, "ci/") {
+		bucket, marker, prefix = "ci", strings.TrimPrefix(version, "ci/"), "ci/"
+	}
+	resolved, err := d.get(strings.TrimSuffix(baseURL, "/") + "/" + bucket + "/" + marker + ".txt")
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to resolve version marker %q", version)
+	}
+	if !strings.HasPrefix(resolved, "v") || strings.ContainsAny(resolved, "/ \n") {
+		return "", errors.Errorf("invalid version %q for version marker %q", resolved, version)
+	}
+	logger.V(0).Infof("Resolved version marker %q to %q", version, resolved)
+	return prefix + resolved, nil
+}
+
+// resolveURL returns the url of the server tarball of the release builder
+// version
+func (b *remoteBuilder) resolveURL() string {
+	bucket, version := "release", b.version
+	if ciBuild.MatchString(version) {
+		bucket, version = "ci", strings.TrimPrefix(version, "ci/")
+	}
+	return b.baseURL + "/" + bucket + "/" + version + "/kubernetes-server-linux-" + b.arch + ".tar.gz"
+}
+
 // Build implements Bits.Build
 func (b *remoteBuilder) Build() (Bits, error) {
+	url := b.url
+	if url == "" {
+		url = b.resolveURL()
+	}
 
-	tmpDir, err := os.MkdirTemp(os.TempDir(), "k8s-tar-extract-")
+	tgzFile, err := b.downloader.download(url)
 	if err != nil {
-		return nil, fmt.Errorf("error creating temporary directory for tar extraction: %w", err)
+		return nil, fmt.Errorf("error downloading file: %w", err)
+	}
+	sum, err := sha256File(tgzFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to checksum server tarball")
 	}
 
-	tgzFile := filepath.Join(tmpDir, "kubernetes-"+b.version+"-server-linux-amd64.tar.gz")
-	err = b.downloadURL(b.url, tgzFile)
+	tmpDir, err := os.MkdirTemp(os.TempDir(), "k8s-tar-extract-")
 	if err != nil {
-		return nil, fmt.Errorf("error downloading file: %w", err)
+		return nil, fmt.Errorf("error creating temporary directory for tar extraction: %w", err)
 	}
 
 	err = extractTarball(tgzFile, tmpDir, b.logger)
@@ -103,62 +179,6 @@ func (b *remoteBuilder) Build() (Bits, error) {
 			filepath.Join(binDir, "kube-proxy.tar"),
 		},
 		version: sourceVersionRaw,
+		source:  Source{URL: url, SHA256: sum},
 	}, nil
 }
-
-func (b *remoteBuilder) downloadURL(url string, destPath string) error {
-	output, err := os.Create(destPath)
-	if err != nil {
-		return fmt.Errorf("error creating file for download %q: %v", destPath, err)
-	}
-	defer output.Close()
-
-	b.logger.V(0).Infof("Downloading %q", url)
-
-	// Create a client with custom timeouts
-	// to avoid idle downloads to hang the program
-	httpClient := &http.Client{
-		Transport: &http.Transport{
-			Proxy: http.ProxyFromEnvironment,
-			DialContext: (&net.Dialer{
-				Timeout:   30 * time.Second,
-				KeepAlive: 30 * time.Second,
-			}).DialContext,
-			TLSHandshakeTimeout:   10 * time.Second,
-			ResponseHeaderTimeout: 30 * time.Second,
-			IdleConnTimeout:       30 * time.Second,
-		},
-	}
-
-	// this will stop slow downloads after 10 minutes
-	// and interrupt reading of the Response.Body
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
-	defer cancel()
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	if err != nil {
-		return fmt.Errorf("cannot create request: %v", err)
-	}
-
-	response, err := httpClient.Do(req)
-	if err != nil {
-		return fmt.Errorf("error doing HTTP fetch of %q: %v", url, err)
-	}
-	defer response.Body.Close()
-
-	if response.StatusCode >= 400 {
-		return fmt.Errorf("error response from %q: HTTP %v", url, response.StatusCode)
-	}
-
-	start := time.Now()
-	defer func() {
-		b.logger.V(2).Infof("Copying %q to %q took %q", url, destPath, time.Since(start))
-	}()
-
-	// TODO: we should add some sort of progress indicator
-	_, err = io.Copy(output, response.Body)
-	if err != nil {
-		return fmt.Errorf("error downloading HTTP content from %q: %v", url, err)
-	}
-	return nil
-}
@@ -0,0 +1,110 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/assert"
+	"sigs.k8s.io/kind/pkg/log"
+)
+
+func TestIsReleaseMarker(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Param    string
+		Expected bool
+	}{
+		{Param: "latest", Expected: true},
+		{Param: "stable", Expected: true},
+		{Param: "latest-1.31", Expected: true},
+		{Param: "stable-1.30", Expected: true},
+		{Param: "ci/latest", Expected: true},
+		{Param: "ci/latest-1.32", Expected: true},
+		{Param: "ci/v1.32.0-alpha.1.40+0123abcd", Expected: true},
+		{Param: "v1.31.0", Expected: false},
+		{Param: "latest-1", Expected: false},
+		{Param: "ci/stable", Expected: false},
+		{Param: "ci/../latest", Expected: false},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Param, func(t *testing.T) {
+			t.Parallel()
+			assert.BoolEqual(t, tc.Expected, IsReleaseMarker(tc.Param))
+		})
+	}
+}
+
+func TestResolveVersion(t *testing.T) {
+	t.Parallel()
+	s := newArtifactServer(map[string][]byte{
+		"/release/latest-1.31.txt": []byte("v1.31.2\n"),
+		"/ci/latest.txt":           []byte("v1.32.0-alpha.1.40+0123abcd"),
+		"/release/stable.txt":      []byte("../../v1.31.2"),
+	})
+	// the parallel subtests run after this function returns
+	t.Cleanup(s.Close)
+
+	cases := []struct {
+		Version     string
+		Expected    string
+		ExpectError bool
+	}{
+		{Version: "v1.31.0", Expected: "v1.31.0"},
+		{Version: "latest-1.31", Expected: "v1.31.2"},
+		{Version: "ci/latest", Expected: "ci/v1.32.0-alpha.1.40+0123abcd"},
+		{Version: "ci/v1.32.0-alpha.1.40+0123abcd", Expected: "ci/v1.32.0-alpha.1.40+0123abcd"},
+		{Version: "latest", ExpectError: true},
+		{Version: "stable", ExpectError: true},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Version, func(t *testing.T) {
+			t.Parallel()
+			resolved, err := resolveVersion(s.downloader(t), log.NoopLogger{}, tc.Version, s.URL+"/")
+			assert.ExpectError(t, tc.ExpectError, err)
+			assert.StringEqual(t, tc.Expected, resolved)
+		})
+	}
+}
+
+func TestResolveURL(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Version  string
+		Expected string
+	}{
+		{Version: "v1.31.0", Expected: "https://dl.k8s.io/release/v1.31.0/kubernetes-server-linux-arm64.tar.gz"},
+		{Version: "ci/v1.32.0-alpha.1.40+0123abcd", Expected: "https://dl.k8s.io/ci/v1.32.0-alpha.1.40+0123abcd/kubernetes-server-linux-arm64.tar.gz"},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Version, func(t *testing.T) {
+			t.Parallel()
+			builder, err := NewReleaseBuilder(log.NoopLogger{}, tc.Version, "arm64", "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.StringEqual(t, tc.Expected, builder.(*remoteBuilder).resolveURL())
+		})
+	}
+	// version markers may resolve differently for each architecture
+	if _, err := NewReleaseBuilder(log.NoopLogger{}, "ci/latest", "arm64", ""); err == nil {
+		t.Errorf("expected error for unresolved version marker but got none")
+	}
+}
@@ -52,6 +52,11 @@ func (b *directoryBuilder) Build() (Bits, error) {
 		return nil, fmt.Errorf("error creating temporary directory for tar extraction: %w", err)
 	}
 
+	sum, err := sha256File(b.tarballPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to checksum server tarball")
+	}
+
 	b.logger.V(0).Infof("Extracting %q", b.tarballPath)
 	err = extractTarball(b.tarballPath, tmpDir, b.logger)
 	if err != nil {
@@ -84,5 +89,6 @@ func (b *directoryBuilder) Build() (Bits, error) {
 			filepath.Join(binDir, "kube-proxy.tar"),
 		},
 		version: sourceVersionRaw,
+		source:  Source{SHA256: sum},
 	}, nil
 }
@@ -0,0 +1,283 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/log"
+)
+
+// downloadAttempts is how many times a download is resumed before failing
+const downloadAttempts = 3
+
+// notFoundError is returned for urls which do not exist
+type notFoundError struct {
+	url string
+}
+
+func (e *notFoundError) Error() string {
+	return fmt.Sprintf("error response from %q: HTTP 404", e.url)
+}
+
+func isNotFound(err error) bool {
+	_, ok := err.(*notFoundError)
+	return ok
+}
+
+// downloader downloads artifacts into a cache directory, resuming partial
+// downloads and verifying the published checksums of the artifacts
+type downloader struct {
+	logger   log.Logger
+	client   *http.Client
+	cacheDir string
+}
+
+func newDownloader(logger log.Logger) *downloader {
+	cacheDir := filepath.Join(os.TempDir(), "kind-artifacts")
+	if userCacheDir, err := os.UserCacheDir(); err == nil {
+		cacheDir = filepath.Join(userCacheDir, "kind", "artifacts")
+	}
+	return &downloader{
+		logger: logger,
+		// Create a client with custom timeouts
+		// to avoid idle downloads to hang the program
+		client: &http.Client{
+			Transport: &http.Transport{
+				Proxy: http.ProxyFromEnvironment,
+				DialContext: (&net.Dialer{
+					Timeout:   30 * time.Second,
+					KeepAlive: 30 * time.Second,
+				}).DialContext,
+				TLSHandshakeTimeout:   10 * time.Second,
+				ResponseHeaderTimeout: 30 * time.Second,
+				IdleConnTimeout:       30 * time.Second,
+			},
+		},
+		cacheDir: cacheDir,
+	}
+}
+
+// get returns the contents of the small file at url, e.g. a version marker
+func (d *downloader) get(url string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	resp, err := d.do(ctx, url, 0)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	contents, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", errors.Wrapf(err, "error reading %q", url)
+	}
+	return strings.TrimSpace(string(contents)), nil
+}
+
+// download returns the path of the file at url in the cache, downloading
+// it if it is not cached. Partial downloads are resumed. The file is
+// verified with the checksum published as url.sha512 or url.sha256, and
+// cached files are only reused if there is a published checksum.
+func (d *downloader) download(url string) (string, error) {
+	sum, newHash, err := d.publishedChecksum(url)
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(d.cacheDir, fmt.Sprintf("%x", sha256.Sum256([]byte(url))))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", errors.Wrap(err, "error creating download cache")
+	}
+	dest := filepath.Join(dir, path.Base(url))
+	if sum != "" {
+		if err := verifyChecksum(dest, sum, newHash()); err == nil {
+			d.logger.V(0).Infof("Using cached %q", url)
+			return dest, nil
+		}
+	} else {
+		d.logger.Warnf("No published checksum found for %q, it will not be verified", url)
+	}
+
+	partial := dest + ".partial"
+	for attempt := 1; ; attempt++ {
+		err = d.downloadTo(url, partial)
+		if err == nil || attempt == downloadAttempts || isNotFound(err) {
+			break
+		}
+		d.logger.Warnf("Failed to download %q, resuming: %v", url, err)
+	}
+	if err != nil {
+		return "", err
+	}
+	if sum != "" {
+		if err := verifyChecksum(partial, sum, newHash()); err != nil {
+			// do not resume a corrupt download
+			os.Remove(partial)
+			return "", err
+		}
+	}
+	if err := os.Rename(partial, dest); err != nil {
+		return "", errors.Wrap(err, "error caching download")
+	}
+	return dest, nil
+}
+
+// downloadTo downloads url to destPath, resuming from the end of destPath
+// if it exists
+func (d *downloader) downloadTo(url, destPath string) error {
+	output, err := os.OpenFile(destPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("error creating file for download %q: %v", destPath, err)
+	}
+	defer output.Close()
+	info, err := output.Stat()
+	if err != nil {
+		return err
+	}
+	offset := info.Size()
+
+	// this will stop slow downloads after 10 minutes
+	// and interrupt reading of the Response.Body
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+	response, err := d.do(ctx, url, offset)
+	if err != nil {
+		return err
+	}
+	// the partial download may not match the file on the server, e.g. it is
+	// already complete or the server sent another range, start over then
+	if offset > 0 && response.StatusCode != http.StatusOK && !resumesAt(response, offset) {
+		response.Body.Close()
+		d.logger.V(0).Infof("Discarding partial download of %q", url)
+		if err := output.Truncate(0); err != nil {
+			return err
+		}
+		offset = 0
+		if response, err = d.do(ctx, url, offset); err != nil {
+			return err
+		}
+	}
+	defer response.Body.Close()
+	if offset > 0 && response.StatusCode == http.StatusPartialContent {
+		d.logger.V(0).Infof("Resuming download of %q at %d bytes", url, offset)
+	} else {
+		d.logger.V(0).Infof("Downloading %q", url)
+		// the server sent the whole file
+		if err := output.Truncate(0); err != nil {
+			return err
+		}
+	}
+
+	start := time.Now()
+	defer func() {
+		d.logger.V(2).Infof("Copying %q to %q took %q", url, destPath, time.Since(start))
+	}()
+
+	// TODO: we should add some sort of progress indicator
+	_, err = io.Copy(output, response.Body)
+	if err != nil {
+		return fmt.Errorf("error downloading HTTP content from %q: %v", url, err)
+	}
+	return nil
+}
+
+// do requests url from offset
+func (d *downloader) do(ctx context.Context, url string, offset int64) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create request: %v", err)
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	response, err := d.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error doing HTTP fetch of %q: %v", url, err)
+	}
+	if response.StatusCode == http.StatusNotFound {
+		response.Body.Close()
+		return nil, &notFoundError{url: url}
+	}
+	// the caller handles a range past the end of the file
+	if response.StatusCode >= 400 && !(offset > 0 && response.StatusCode == http.StatusRequestedRangeNotSatisfiable) {
+		response.Body.Close()
+		return nil, fmt.Errorf("error response from %q: HTTP %v", url, response.StatusCode)
+	}
+	return response, nil
+}
+
+// resumesAt returns true if response is the content of the file from offset
+func resumesAt(response *http.Response, offset int64) bool {
+	return response.StatusCode == http.StatusPartialContent &&
+		strings.HasPrefix(response.Header.Get("Content-Range"), fmt.Sprintf("bytes %d-", offset))
+}
+
+// publishedChecksum returns the sha512 or sha256 checksum published for
+// url, or an empty sum if there is none
+func (d *downloader) publishedChecksum(url string) (sum string, newHash func() hash.Hash, err error) {
+	for _, checksum := range []struct {
+		ext     string
+		newHash func() hash.Hash
+	}{
+		{".sha512", sha512.New},
+		{".sha256", sha256.New},
+	} {
+		contents, err := d.get(url + checksum.ext)
+		if isNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return "", nil, err
+		}
+		// the checksum may be followed by the file name
+		fields := strings.Fields(contents)
+		if len(fields) == 0 {
+			return "", nil, errors.Errorf("invalid checksum at %q", url+checksum.ext)
+		}
+		return strings.ToLower(fields[0]), checksum.newHash, nil
+	}
+	return "", nil, nil
+}
+
+// verifyChecksum verifies that the file at filePath has the checksum sum
+func verifyChecksum(filePath, sum string, h hash.Hash) error {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := io.Copy(h, f); err != nil {
+		return err
+	}
+	if actual := hex.EncodeToString(h.Sum(nil)); actual != sum {
+		return errors.Errorf("checksum mismatch for %q: expected %s, got %s", filepath.Base(filePath), sum, actual)
+	}
+	return nil
+}
@@ -0,0 +1,215 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/internal/assert"
+	"sigs.k8s.io/kind/pkg/log"
+)
+
+// artifactServer serves files by path, recording the requests to them
+type artifactServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	files    map[string][]byte
+	requests map[string][]string
+	// wrongRange makes range requests return the file from the start, like
+	// a misbehaving server or proxy
+	wrongRange bool
+}
+
+func newArtifactServer(files map[string][]byte) *artifactServer {
+	s := &artifactServer{files: files, requests: map[string][]string{}}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests[r.URL.Path] = append(s.requests[r.URL.Path], r.Header.Get("Range"))
+		contents, ok := s.files[r.URL.Path]
+		wrongRange := s.wrongRange
+		s.mu.Unlock()
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if wrongRange && r.Header.Get("Range") != "" {
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes 0-%d/%d", len(contents)-1, len(contents)))
+			w.WriteHeader(http.StatusPartialContent)
+			_, _ = w.Write(contents)
+			return
+		}
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(contents))
+	}))
+	return s
+}
+
+func (s *artifactServer) requestsTo(path string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[path]
+}
+
+func (s *artifactServer) downloader(t *testing.T) *downloader {
+	d := newDownloader(log.NoopLogger{})
+	d.client = s.Client()
+	d.cacheDir = t.TempDir()
+	return d
+}
+
+func TestDownload(t *testing.T) {
+	t.Parallel()
+	contents := []byte("kubernetes server tarball")
+	s := newArtifactServer(map[string][]byte{
+		"/release/v1.31.0/server.tar.gz":        contents,
+		"/release/v1.31.0/server.tar.gz.sha512": []byte(fmt.Sprintf("%x  server.tar.gz\n", sha512.Sum512(contents))),
+	})
+	defer s.Close()
+	d := s.downloader(t)
+	url := s.URL + "/release/v1.31.0/server.tar.gz"
+
+	path, err := d.download(url)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	downloaded, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.StringEqual(t, string(contents), string(downloaded))
+
+	// the verified download is cached
+	cached, err := d.download(url)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assert.StringEqual(t, path, cached)
+	assert.DeepEqual(t, []string{""}, s.requestsTo("/release/v1.31.0/server.tar.gz"))
+}
+
+func TestDownloadResume(t *testing.T) {
+	t.Parallel()
+	contents := []byte("kubernetes server tarball")
+	s := newArtifactServer(map[string][]byte{
+		"/ci/v1.32.0/server.tar.gz":        contents,
+		"/ci/v1.32.0/server.tar.gz.sha256": []byte(fmt.Sprintf("%x", sha256.Sum256(contents))),
+	})
+	defer s.Close()
+	d := s.downloader(t)
+	url := s.URL + "/ci/v1.32.0/server.tar.gz"
+
+	// leave a partial download in the cache
+	dir := filepath.Join(d.cacheDir, fmt.Sprintf("%x", sha256.Sum256([]byte(url))))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "server.tar.gz.partial"), contents[:10], 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	path, err := d.download(url)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	downloaded, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.StringEqual(t, string(contents), string(downloaded))
+	assert.DeepEqual(t, []string{"bytes=10-"}, s.requestsTo("/ci/v1.32.0/server.tar.gz"))
+}
+
+func TestDownloadRestart(t *testing.T) {
+	t.Parallel()
+	contents := []byte("kubernetes server tarball")
+	cases := []struct {
+		Name       string
+		Partial    []byte
+		WrongRange bool
+	}{
+		{
+			// the server responds with 416 to a range past the end of the file
+			Name:    "partial download longer than the file",
+			Partial: []byte("a stale and longer kubernetes server tarball"),
+		},
+		{
+			Name:       "response for another range",
+			Partial:    contents[:10],
+			WrongRange: true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			s := newArtifactServer(map[string][]byte{
+				"/ci/v1.32.0/server.tar.gz":        contents,
+				"/ci/v1.32.0/server.tar.gz.sha256": []byte(fmt.Sprintf("%x", sha256.Sum256(contents))),
+			})
+			s.wrongRange = tc.WrongRange
+			t.Cleanup(s.Close)
+			d := s.downloader(t)
+			url := s.URL + "/ci/v1.32.0/server.tar.gz"
+
+			dir := filepath.Join(d.cacheDir, fmt.Sprintf("%x", sha256.Sum256([]byte(url))))
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(dir, "server.tar.gz.partial"), tc.Partial, 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			path, err := d.download(url)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			downloaded, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.StringEqual(t, string(contents), string(downloaded))
+			// the partial download is discarded and downloaded from scratch
+			assert.DeepEqual(t, []string{fmt.Sprintf("bytes=%d-", len(tc.Partial)), ""}, s.requestsTo("/ci/v1.32.0/server.tar.gz"))
+		})
+	}
+}
+
+func TestDownloadChecksumMismatch(t *testing.T) {
+	t.Parallel()
+	s := newArtifactServer(map[string][]byte{
+		"/release/v1.31.0/server.tar.gz":        []byte("corrupt"),
+		"/release/v1.31.0/server.tar.gz.sha256": []byte(fmt.Sprintf("%x", sha256.Sum256([]byte("kubernetes")))),
+	})
+	defer s.Close()
+	d := s.downloader(t)
+	url := s.URL + "/release/v1.31.0/server.tar.gz"
+
+	_, err := d.download(url)
+	assert.ExpectError(t, true, err)
+	// the corrupt download is not kept to be resumed
+	matches, _ := filepath.Glob(filepath.Join(d.cacheDir, "*", "*"))
+	assert.DeepEqual(t, []string(nil), matches)
+}
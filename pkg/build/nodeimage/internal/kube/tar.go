@@ -3,6 +3,7 @@ package kube
 import (
 	"archive/tar"
 	"compress/gzip"
+	"crypto/sha256"
 	"fmt"
 	"io"
 	"os"
@@ -67,3 +68,17 @@ func extractTarball(tarPath, destDirectory string, logger log.Logger) (err error
 	logger.V(2).Infof("Successfully extracted %d files from image tarball %s", numFiles, tarPath)
 	return err
 }
+
+// sha256File returns the sha256 checksum of the file at filePath
+func sha256File(filePath string) (string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oci
+
+import (
+	"archive/tar"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+// WriteArchive writes the image manifest desc of the layout to w as an
+// archive tagged ref, which is both an OCI image layout and a docker save
+// archive, as written by docker save since docker 25.
+// The archive can be loaded by docker, podman and nerdctl.
+func (l *Layout) WriteArchive(w io.Writer, desc Descriptor, ref string) error {
+	manifest, err := l.ReadManifest(desc)
+	if err != nil {
+		return err
+	}
+	tw := tar.NewWriter(w)
+
+	blobPath := func(digest string) string {
+		return "blobs/sha256/" + strings.TrimPrefix(digest, "sha256:")
+	}
+	written := map[string]bool{}
+	for _, blob := range append([]Descriptor{desc, manifest.Config}, manifest.Layers...) {
+		if written[blob.Digest] {
+			continue
+		}
+		written[blob.Digest] = true
+		if err := l.copyBlob(tw, blob.Digest, blobPath(blob.Digest)); err != nil {
+			return err
+		}
+	}
+
+	dockerManifest := []struct {
+		Config   string
+		RepoTags []string
+		Layers   []string
+	}{{
+		Config:   blobPath(manifest.Config.Digest),
+		RepoTags: []string{ref},
+		Layers:   []string{},
+	}}
+	for _, layer := range manifest.Layers {
+		dockerManifest[0].Layers = append(dockerManifest[0].Layers, blobPath(layer.Digest))
+	}
+	desc.Annotations = map[string]string{
+		AnnotationRefName:   ref,
+		AnnotationImageName: ref,
+	}
+	index := &Index{
+		SchemaVersion: 2,
+		MediaType:     MediaTypeImageIndex,
+		Manifests:     []Descriptor{desc},
+	}
+	for _, file := range []struct {
+		name string
+		v    interface{}
+	}{
+		{"manifest.json", dockerManifest},
+		{"index.json", index},
+		{"oci-layout", map[string]string{"imageLayoutVersion": "1.0.0"}},
+	} {
+		raw, err := json.Marshal(file.v)
+		if err != nil {
+			return err
+		}
+		if err := tw.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     file.name,
+			Mode:     0644,
+			Size:     int64(len(raw)),
+		}); err != nil {
+			return err
+		}
+		if _, err := tw.Write(raw); err != nil {
+			return err
+		}
+	}
+	return tw.Close()
+}
+
+// copyBlob writes the blob digest of the layout to tw as name
+func (l *Layout) copyBlob(tw *tar.Writer, digest, name string) error {
+	f, err := l.OpenBlob(digest)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if err := tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Mode:     0644,
+		Size:     info.Size(),
+	}); err != nil {
+		return err
+	}
+	if _, err := io.Copy(tw, f); err != nil {
+		return errors.Wrapf(err, "failed to write %s", digest)
+	}
+	return nil
+}
+
+// WriteDir writes the files of the layout to tw below dir, to add the
+// layout to an image layer
+func (l *Layout) WriteDir(tw *tar.Writer, dir string, hdr tar.Header) error {
+	return filepath.Walk(l.dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(l.dir, p)
+		if err != nil {
+			return err
+		}
+		h := hdr
+		h.Name = strings.TrimPrefix(filepath.ToSlash(filepath.Join(dir, rel)), "/")
+		if info.IsDir() {
+			h.Typeflag, h.Mode, h.Name = tar.TypeDir, 0755, h.Name+"/"
+			return tw.WriteHeader(&h)
+		}
+		if !info.Mode().IsRegular() || strings.HasPrefix(info.Name(), ".tmp-") {
+			return nil
+		}
+		h.Typeflag, h.Mode, h.Size = tar.TypeReg, 0644, info.Size()
+		if err := tw.WriteHeader(&h); err != nil {
+			return err
+		}
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+}
@@ -0,0 +1,196 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oci
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+// OpenBlob opens the blob digest of the layout
+func (l *Layout) OpenBlob(digest string) (*os.File, error) {
+	hexDigest := strings.TrimPrefix(digest, "sha256:")
+	if hexDigest == digest || strings.ContainsAny(hexDigest, "/\\.") {
+		return nil, errors.Errorf("unsupported digest %q", digest)
+	}
+	return os.Open(filepath.Join(l.dir, "blobs", "sha256", hexDigest))
+}
+
+// HasBlob returns true if the blob digest is in the layout
+func (l *Layout) HasBlob(digest string) bool {
+	f, err := l.OpenBlob(digest)
+	if err != nil {
+		return false
+	}
+	f.Close()
+	return true
+}
+
+// ReadJSON parses the JSON blob digest of the layout into v
+func (l *Layout) ReadJSON(digest string, v interface{}) error {
+	f, err := l.OpenBlob(digest)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := json.NewDecoder(f).Decode(v); err != nil {
+		return errors.Wrapf(err, "failed to parse %s", digest)
+	}
+	return nil
+}
+
+// ReadManifest returns the image manifest desc of the layout
+func (l *Layout) ReadManifest(desc Descriptor) (*Manifest, error) {
+	manifest := &Manifest{}
+	if err := l.ReadJSON(desc.Digest, manifest); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// WriteLayer writes a gzip compressed layer with the files written by fn to
+// the layout, returning its descriptor and the digest of the uncompressed
+// layer to add to the rootfs of the image config
+func (l *Layout) WriteLayer(fn func(*tar.Writer) error) (desc Descriptor, diffID string, err error) {
+	pr, pw := io.Pipe()
+	hasher := sha256.New()
+	go func() {
+		gw := gzip.NewWriter(pw)
+		tw := tar.NewWriter(io.MultiWriter(gw, hasher))
+		err := fn(tw)
+		if err == nil {
+			err = tw.Close()
+		}
+		if err == nil {
+			err = gw.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+	digest, size, _, err := l.WriteBlob(pr)
+	if err != nil {
+		// unblock fn if the blob could not be written
+		pr.CloseWithError(err)
+		return Descriptor{}, "", err
+	}
+	return Descriptor{
+		MediaType: MediaTypeImageLayer + "+gzip",
+		Digest:    digest,
+		Size:      size,
+	}, "sha256:" + hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// ReadFile returns the contents of the file at filePath in the image of
+// manifest, as in the filesystem of a container of the image
+func (l *Layout) ReadFile(manifest *Manifest, filePath string) ([]byte, error) {
+	filePath = path.Clean(strings.TrimPrefix(filePath, "/"))
+	// the upper layers take precedence
+	for i := len(manifest.Layers) - 1; i >= 0; i-- {
+		contents, found, deleted, err := l.readLayerFile(manifest.Layers[i], filePath)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			return contents, nil
+		}
+		if deleted {
+			break
+		}
+	}
+	return nil, errors.Errorf("%q not found in image", "/"+filePath)
+}
+
+// readLayerFile looks up filePath in the layer, found is false if the layer
+// does not contain it and deleted is true if the layer removes it from the
+// layers below
+func (l *Layout) readLayerFile(layer Descriptor, filePath string) (contents []byte, found, deleted bool, err error) {
+	f, err := l.OpenBlob(layer.Digest)
+	if err != nil {
+		return nil, false, false, err
+	}
+	defer f.Close()
+	var r io.Reader = f
+	switch layer.MediaType {
+	case MediaTypeImageLayer + "+gzip", MediaTypeDockerLayerGzip:
+		gr, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, false, false, errors.Wrapf(err, "failed to read layer %s", layer.Digest)
+		}
+		defer gr.Close()
+		r = gr
+	case MediaTypeImageLayer, MediaTypeDockerLayer:
+	default:
+		return nil, false, false, errors.Errorf("unsupported layer media type %q", layer.MediaType)
+	}
+
+	dir, base := path.Split(filePath)
+	whiteouts := map[string]bool{path.Join(dir, ".wh."+base): true}
+	for d := path.Dir(filePath); d != "."; d = path.Dir(d) {
+		whiteouts[path.Join(d, ".wh..wh..opq")] = true
+		parent, name := path.Split(d)
+		whiteouts[path.Join(parent, ".wh."+name)] = true
+	}
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil, false, deleted, nil
+		}
+		if err != nil {
+			return nil, false, false, errors.Wrapf(err, "failed to read layer %s", layer.Digest)
+		}
+		name := path.Clean(strings.TrimPrefix(hdr.Name, "/"))
+		if name == filePath && hdr.Typeflag == tar.TypeReg {
+			contents, err := io.ReadAll(tr)
+			if err != nil {
+				return nil, false, false, errors.Wrapf(err, "failed to read layer %s", layer.Digest)
+			}
+			return contents, true, false, nil
+		}
+		if whiteouts[name] {
+			deleted = true
+		}
+	}
+}
+
+// ociLayerMediaTypes maps the docker layer media types to the OCI ones
+var ociLayerMediaTypes = map[string]string{
+	MediaTypeDockerLayer:     MediaTypeImageLayer,
+	MediaTypeDockerLayerGzip: MediaTypeImageLayer + "+gzip",
+}
+
+// OCILayers returns the layers of manifest with OCI media types, to build
+// OCI images on top of images with docker media types
+func OCILayers(manifest *Manifest) []Descriptor {
+	layers := make([]Descriptor, 0, len(manifest.Layers))
+	for _, layer := range manifest.Layers {
+		if mediaType, ok := ociLayerMediaTypes[layer.MediaType]; ok {
+			layer.MediaType = mediaType
+		}
+		layers = append(layers, layer)
+	}
+	return layers
+}
@@ -0,0 +1,170 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oci
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func writeFiles(files map[string]string, order ...string) func(*tar.Writer) error {
+	return func(tw *tar.Writer) error {
+		for _, name := range order {
+			if err := tw.WriteHeader(&tar.Header{
+				Typeflag: tar.TypeReg,
+				Name:     name,
+				Mode:     0644,
+				Size:     int64(len(files[name])),
+			}); err != nil {
+				return err
+			}
+			if _, err := tw.Write([]byte(files[name])); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func TestReadFile(t *testing.T) {
+	t.Parallel()
+	layout, err := NewLayout(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	lower, _, err := layout.WriteLayer(writeFiles(map[string]string{
+		"etc/a":     "lower a",
+		"etc/b":     "lower b",
+		"etc/c/d":   "lower d",
+		"etc/e/f":   "lower f",
+		"usr/bin/x": "x",
+	}, "etc/a", "etc/b", "etc/c/d", "etc/e/f", "usr/bin/x"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	upper, diffID, err := layout.WriteLayer(writeFiles(map[string]string{
+		"etc/a":              "upper a",
+		"etc/.wh.b":          "",
+		"etc/.wh.c":          "",
+		"etc/e/.wh..wh..opq": "",
+	}, "etc/a", "etc/.wh.b", "etc/.wh.c", "etc/e/.wh..wh..opq"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.StringEqual(t, MediaTypeImageLayer+"+gzip", upper.MediaType)
+	if diffID == upper.Digest {
+		t.Errorf("expected the diff id to be the digest of the uncompressed layer")
+	}
+	manifest := &Manifest{Layers: []Descriptor{lower, upper}}
+
+	cases := []struct {
+		Path        string
+		Expected    string
+		ExpectError bool
+	}{
+		{Path: "/etc/a", Expected: "upper a"},
+		{Path: "/usr/bin/x", Expected: "x"},
+		{Path: "/etc/b", ExpectError: true},
+		{Path: "/etc/c/d", ExpectError: true},
+		{Path: "/etc/e/f", ExpectError: true},
+		{Path: "/etc/missing", ExpectError: true},
+	}
+	for _, tc := range cases {
+		contents, err := layout.ReadFile(manifest, tc.Path)
+		assert.ExpectError(t, tc.ExpectError, err)
+		assert.StringEqual(t, tc.Expected, string(contents))
+	}
+}
+
+func TestWriteArchive(t *testing.T) {
+	t.Parallel()
+	layout, err := NewLayout(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	layer, _, err := layout.WriteLayer(writeFiles(map[string]string{"kind/version": "v1.30.0"}, "kind/version"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, err := layout.WriteJSON(MediaTypeImageConfig, map[string]string{"os": "linux"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	desc, err := layout.WriteJSON(MediaTypeImageManifest, &Manifest{
+		SchemaVersion: 2,
+		MediaType:     MediaTypeImageManifest,
+		Config:        config,
+		Layers:        []Descriptor{layer},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := layout.WriteArchive(buf, desc, "kindest/node:test"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	names := []string{}
+	var dockerManifest []byte
+	tr := tar.NewReader(buf)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, hdr.Name)
+		if hdr.Name == "manifest.json" {
+			if dockerManifest, err = io.ReadAll(tr); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	blob := func(d Descriptor) string {
+		return "blobs/sha256/" + d.Digest[len("sha256:"):]
+	}
+	assert.DeepEqual(t, []string{blob(desc), blob(config), blob(layer), "manifest.json", "index.json", "oci-layout"}, names)
+	assert.StringEqual(t,
+		`[{"Config":"`+blob(config)+`","RepoTags":["kindest/node:test"],"Layers":["`+blob(layer)+`"]}]`,
+		string(dockerManifest),
+	)
+}
+
+func TestWriteLayerReproducible(t *testing.T) {
+	t.Parallel()
+	layout, err := NewLayout(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	fn := writeFiles(map[string]string{"kind/version": "v1.30.0"}, "kind/version")
+	first, firstDiffID, err := layout.WriteLayer(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, secondDiffID, err := layout.WriteLayer(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.DeepEqual(t, first, second)
+	assert.StringEqual(t, firstDiffID, secondDiffID)
+}
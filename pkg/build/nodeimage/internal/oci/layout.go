@@ -0,0 +1,279 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oci
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+// Layout is an OCI image layout directory
+type Layout struct {
+	dir string
+}
+
+// NewLayout returns the OCI image layout at dir, creating it if needed.
+// Images already in the layout are kept.
+func NewLayout(dir string) (*Layout, error) {
+	if err := os.MkdirAll(filepath.Join(dir, "blobs", "sha256"), 0755); err != nil {
+		return nil, errors.Wrap(err, "failed to create OCI layout")
+	}
+	l := &Layout{dir: dir}
+	layoutFile := filepath.Join(dir, "oci-layout")
+	if _, err := os.Stat(layoutFile); os.IsNotExist(err) {
+		if err := os.WriteFile(layoutFile, []byte(`{"imageLayoutVersion":"1.0.0"}`), 0644); err != nil {
+			return nil, errors.Wrap(err, "failed to create OCI layout")
+		}
+	}
+	return l, nil
+}
+
+// Dir returns the directory of the layout
+func (l *Layout) Dir() string {
+	return l.dir
+}
+
+// WriteBlob writes the content of r to the layout, returning its digest and
+// size. The first bytes of the content are returned as well to detect its
+// format.
+func (l *Layout) WriteBlob(r io.Reader) (digest string, size int64, head []byte, err error) {
+	tmp, err := os.CreateTemp(filepath.Join(l.dir, "blobs", "sha256"), ".tmp-")
+	if err != nil {
+		return "", 0, nil, errors.Wrap(err, "failed to create blob")
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	hasher := sha256.New()
+	br := bufio.NewReader(r)
+	// Peek returns fewer bytes for small blobs, which is fine
+	peeked, _ := br.Peek(4)
+	head = append([]byte{}, peeked...)
+	if size, err = io.Copy(io.MultiWriter(tmp, hasher), br); err != nil {
+		return "", 0, nil, errors.Wrap(err, "failed to write blob")
+	}
+	if err := tmp.Close(); err != nil {
+		return "", 0, nil, errors.Wrap(err, "failed to write blob")
+	}
+	hexDigest := hex.EncodeToString(hasher.Sum(nil))
+	if err := os.Rename(tmp.Name(), filepath.Join(l.dir, "blobs", "sha256", hexDigest)); err != nil {
+		return "", 0, nil, errors.Wrap(err, "failed to write blob")
+	}
+	return "sha256:" + hexDigest, size, head, nil
+}
+
+// WriteJSON writes v as a JSON blob of mediaType to the layout
+func (l *Layout) WriteJSON(mediaType string, v interface{}) (Descriptor, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return Descriptor{}, err
+	}
+	digest, size, _, err := l.WriteBlob(bytes.NewReader(raw))
+	if err != nil {
+		return Descriptor{}, err
+	}
+	return Descriptor{
+		MediaType: mediaType,
+		Digest:    digest,
+		Size:      size,
+	}, nil
+}
+
+// WriteImageIndex writes an image index of manifests to the layout
+func (l *Layout) WriteImageIndex(manifests []Descriptor) (Descriptor, error) {
+	return l.WriteJSON(MediaTypeImageIndex, &Index{
+		SchemaVersion: 2,
+		MediaType:     MediaTypeImageIndex,
+		Manifests:     manifests,
+	})
+}
+
+// Tag points ref at desc in the index.json of the layout, replacing the
+// previous image of ref if any
+func (l *Layout) Tag(desc Descriptor, ref string) error {
+	indexFile := filepath.Join(l.dir, "index.json")
+	index := &Index{
+		SchemaVersion: 2,
+		MediaType:     MediaTypeImageIndex,
+	}
+	if raw, err := os.ReadFile(indexFile); err == nil {
+		if err := json.Unmarshal(raw, index); err != nil {
+			return errors.Wrap(err, "failed to parse OCI layout index")
+		}
+	} else if !os.IsNotExist(err) {
+		return errors.Wrap(err, "failed to read OCI layout index")
+	}
+
+	manifests := []Descriptor{}
+	for _, m := range index.Manifests {
+		if m.Annotations[AnnotationRefName] != ref {
+			manifests = append(manifests, m)
+		}
+	}
+	desc.Annotations = map[string]string{
+		AnnotationRefName:   ref,
+		AnnotationImageName: ref,
+	}
+	index.Manifests = append(manifests, desc)
+
+	raw, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := indexFile + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0644); err != nil {
+		return errors.Wrap(err, "failed to write OCI layout index")
+	}
+	return os.Rename(tmp, indexFile)
+}
+
+// dockerArchiveBlobRE matches the files of docker save archives that may be
+// image configs or layers, for both the legacy and the OCI based format
+var dockerArchiveBlobRE = regexp.MustCompile(`^([0-9a-f]{64}\.json|[^/]+/layer\.tar|blobs/.+)$`)
+
+// ImportDockerArchive imports the image in the docker save archive at path
+// into the layout, returning the descriptor of its manifest for platform
+func (l *Layout) ImportDockerArchive(archivePath string, platform Platform) (Descriptor, error) {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return Descriptor{}, err
+	}
+	defer f.Close()
+
+	type blob struct {
+		digest string
+		size   int64
+		head   []byte
+	}
+	blobs := map[string]blob{}
+	links := map[string]string{}
+	var rawManifest []byte
+	tr := tar.NewReader(f)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return Descriptor{}, errors.Wrap(err, "failed to read image archive")
+		}
+		name := path.Clean(hdr.Name)
+		switch hdr.Typeflag {
+		case tar.TypeSymlink:
+			// duplicate layers are symlinked to the first copy
+			links[name] = path.Join(path.Dir(name), hdr.Linkname)
+		case tar.TypeLink:
+			links[name] = path.Clean(hdr.Linkname)
+		case tar.TypeReg:
+			if name == "manifest.json" {
+				if rawManifest, err = io.ReadAll(tr); err != nil {
+					return Descriptor{}, errors.Wrap(err, "failed to read image archive")
+				}
+			} else if dockerArchiveBlobRE.MatchString(name) {
+				digest, size, head, err := l.WriteBlob(tr)
+				if err != nil {
+					return Descriptor{}, err
+				}
+				blobs[name] = blob{digest: digest, size: size, head: head}
+			}
+		}
+	}
+
+	if rawManifest == nil {
+		return Descriptor{}, errors.New("could not find image metadata")
+	}
+	archiveManifests := []struct {
+		Config string
+		Layers []string
+	}{}
+	if err := json.Unmarshal(rawManifest, &archiveManifests); err != nil {
+		return Descriptor{}, errors.Wrap(err, "failed to parse image archive manifest")
+	}
+	if len(archiveManifests) != 1 {
+		return Descriptor{}, errors.Errorf("expected one image in archive, found %d", len(archiveManifests))
+	}
+	resolve := func(name string) (blob, error) {
+		name = path.Clean(name)
+		for i := 0; i < 10; i++ {
+			target, ok := links[name]
+			if !ok {
+				break
+			}
+			name = target
+		}
+		b, ok := blobs[name]
+		if !ok {
+			return blob{}, errors.Errorf("image archive is missing %q", name)
+		}
+		return b, nil
+	}
+
+	config, err := resolve(archiveManifests[0].Config)
+	if err != nil {
+		return Descriptor{}, err
+	}
+	manifest := &Manifest{
+		SchemaVersion: 2,
+		MediaType:     MediaTypeImageManifest,
+		Config: Descriptor{
+			MediaType: MediaTypeImageConfig,
+			Digest:    config.digest,
+			Size:      config.size,
+		},
+		Layers: []Descriptor{},
+	}
+	for _, name := range archiveManifests[0].Layers {
+		layer, err := resolve(name)
+		if err != nil {
+			return Descriptor{}, err
+		}
+		manifest.Layers = append(manifest.Layers, Descriptor{
+			MediaType: layerMediaType(layer.head),
+			Digest:    layer.digest,
+			Size:      layer.size,
+		})
+	}
+	desc, err := l.WriteJSON(MediaTypeImageManifest, manifest)
+	if err != nil {
+		return Descriptor{}, err
+	}
+	desc.Platform = &platform
+	return desc, nil
+}
+
+// layerMediaType returns the media type of a layer starting with head,
+// layers saved from the containerd image store may be compressed
+func layerMediaType(head []byte) string {
+	switch {
+	case bytes.HasPrefix(head, []byte{0x1f, 0x8b}):
+		return MediaTypeImageLayer + "+gzip"
+	case bytes.HasPrefix(head, []byte{0x28, 0xb5, 0x2f, 0xfd}):
+		return MediaTypeImageLayer + "+zstd"
+	}
+	return MediaTypeImageLayer
+}
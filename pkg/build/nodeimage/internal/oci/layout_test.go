@@ -0,0 +1,138 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oci
+
+import (
+	"archive/tar"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+// writeDockerArchive writes a legacy docker save archive with a duplicate
+// layer symlinked to the first copy
+func writeDockerArchive(t *testing.T, path string, config, layer []byte) {
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	tw := tar.NewWriter(f)
+	configName := digestHex(config) + ".json"
+	manifest := []byte(`[{"Config":"` + configName + `","RepoTags":["kindest/node:test"],"Layers":["aaa/layer.tar","bbb/layer.tar"]}]`)
+	for _, file := range []struct {
+		name     string
+		content  []byte
+		linkname string
+	}{
+		{name: configName, content: config},
+		{name: "aaa/VERSION", content: []byte("1.0")},
+		{name: "aaa/layer.tar", content: layer},
+		{name: "bbb/layer.tar", linkname: "../aaa/layer.tar"},
+		{name: "manifest.json", content: manifest},
+	} {
+		hdr := &tar.Header{Name: file.name, Mode: 0644, Size: int64(len(file.content))}
+		if file.linkname != "" {
+			hdr.Typeflag = tar.TypeSymlink
+			hdr.Linkname = file.linkname
+			hdr.Size = 0
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(file.content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func digestHex(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+func readJSON(t *testing.T, path string, v interface{}) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestImportDockerArchive(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	config := []byte(`{"architecture":"arm64","os":"linux"}`)
+	layer := []byte("not really a tarball")
+	archive := filepath.Join(dir, "image.tar")
+	writeDockerArchive(t, archive, config, layer)
+
+	layout, err := NewLayout(filepath.Join(dir, "layout"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	platform := Platform{OS: "linux", Architecture: "arm64"}
+	desc, err := layout.ImportDockerArchive(archive, platform)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assert.DeepEqual(t, &platform, desc.Platform)
+	assert.StringEqual(t, MediaTypeImageManifest, desc.MediaType)
+
+	blobPath := func(digest string) string {
+		return filepath.Join(dir, "layout", "blobs", "sha256", digest[len("sha256:"):])
+	}
+	manifest := &Manifest{}
+	readJSON(t, blobPath(desc.Digest), manifest)
+	assert.StringEqual(t, "sha256:"+digestHex(config), manifest.Config.Digest)
+	layerDesc := Descriptor{
+		MediaType: MediaTypeImageLayer,
+		Digest:    "sha256:" + digestHex(layer),
+		Size:      int64(len(layer)),
+	}
+	assert.DeepEqual(t, []Descriptor{layerDesc, layerDesc}, manifest.Layers)
+
+	index, err := layout.WriteImageIndex([]Descriptor{desc})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, ref := range []string{"kindest/node:test", "kindest/node:test", "kindest/node:other"} {
+		if err := layout.Tag(index, ref); err != nil {
+			t.Fatal(err)
+		}
+	}
+	top := &Index{}
+	readJSON(t, filepath.Join(dir, "layout", "index.json"), top)
+	// tagging the same ref again replaces it
+	if len(top.Manifests) != 2 {
+		t.Fatalf("expected 2 images in index.json, got %d", len(top.Manifests))
+	}
+	assert.StringEqual(t, "kindest/node:test", top.Manifests[0].Annotations[AnnotationRefName])
+	nested := &Index{}
+	readJSON(t, blobPath(top.Manifests[0].Digest), nested)
+	assert.StringEqual(t, desc.Digest, nested.Manifests[0].Digest)
+}
@@ -0,0 +1,296 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oci
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+// Reference is a parsed image reference
+type Reference struct {
+	// Registry is the host of the registry, e.g. registry.k8s.io
+	Registry string
+	// Repository is the path of the image in the registry, e.g. library/debian
+	Repository string
+	// Reference is the tag or digest of the image
+	Reference string
+}
+
+// ParseReference parses image references as used by docker,
+// e.g. kindest/node:v1.30.0 is docker.io/kindest/node:v1.30.0
+func ParseReference(image string) (Reference, error) {
+	ref := Reference{}
+	name := image
+	if i := strings.Index(name, "@"); i >= 0 {
+		// the tag is ignored when a digest is set
+		name, ref.Reference = name[:i], name[i+1:]
+		if j := strings.LastIndex(name, ":"); j > strings.LastIndex(name, "/") {
+			name = name[:j]
+		}
+	} else if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name, ref.Reference = name[:i], name[i+1:]
+	} else {
+		ref.Reference = "latest"
+	}
+	parts := strings.SplitN(name, "/", 2)
+	if len(parts) == 2 && (strings.ContainsAny(parts[0], ".:") || parts[0] == "localhost") {
+		ref.Registry, ref.Repository = parts[0], parts[1]
+	} else {
+		ref.Registry, ref.Repository = "docker.io", name
+	}
+	if ref.Registry == "docker.io" && !strings.Contains(ref.Repository, "/") {
+		ref.Repository = "library/" + ref.Repository
+	}
+	if ref.Repository == "" || ref.Reference == "" || ref.Repository != strings.ToLower(ref.Repository) {
+		return Reference{}, errors.Errorf("invalid image reference %q", image)
+	}
+	return ref, nil
+}
+
+// String returns the fully qualified reference
+func (r Reference) String() string {
+	if strings.HasPrefix(r.Reference, "sha256:") {
+		return r.Registry + "/" + r.Repository + "@" + r.Reference
+	}
+	return r.Registry + "/" + r.Repository + ":" + r.Reference
+}
+
+// registryClient fetches images from registries with anonymous access
+type registryClient struct {
+	client *http.Client
+	mu     sync.Mutex
+	// tokens are the bearer tokens by repository scope
+	tokens map[string]string
+}
+
+var defaultRegistryClient = &registryClient{
+	client: http.DefaultClient,
+	tokens: map[string]string{},
+}
+
+// Pull fetches the image for platform from its registry into the layout,
+// returning the descriptor of the image manifest. Blobs already in the
+// layout are not fetched again. Only registries allowing anonymous pulls
+// are supported.
+func (l *Layout) Pull(image string, platform Platform) (Descriptor, error) {
+	ref, err := ParseReference(image)
+	if err != nil {
+		return Descriptor{}, err
+	}
+	return defaultRegistryClient.pull(l, ref, platform)
+}
+
+func (c *registryClient) pull(l *Layout, ref Reference, platform Platform) (Descriptor, error) {
+	desc, raw, err := c.fetchManifest(ref, ref.Reference)
+	if err != nil {
+		return Descriptor{}, err
+	}
+	if desc.MediaType == MediaTypeImageIndex || desc.MediaType == MediaTypeDockerManifestList {
+		index := &Index{}
+		if err := json.Unmarshal(raw, index); err != nil {
+			return Descriptor{}, errors.Wrapf(err, "failed to parse index of %s", ref)
+		}
+		found := false
+		for _, m := range index.Manifests {
+			if m.Platform != nil && m.Platform.OS == platform.OS && m.Platform.Architecture == platform.Architecture &&
+				(platform.Variant == "" || m.Platform.Variant == platform.Variant) {
+				desc, found = m, true
+				break
+			}
+		}
+		if !found {
+			return Descriptor{}, errors.Errorf("%s has no image for %s/%s", ref, platform.OS, platform.Architecture)
+		}
+		if _, raw, err = c.fetchManifest(ref, desc.Digest); err != nil {
+			return Descriptor{}, err
+		}
+	}
+	if desc.MediaType != MediaTypeImageManifest && desc.MediaType != MediaTypeDockerManifest {
+		return Descriptor{}, errors.Errorf("unsupported manifest media type %q of %s", desc.MediaType, ref)
+	}
+
+	manifest := &Manifest{}
+	if err := json.Unmarshal(raw, manifest); err != nil {
+		return Descriptor{}, errors.Wrapf(err, "failed to parse manifest of %s", ref)
+	}
+	for _, blob := range append([]Descriptor{manifest.Config}, manifest.Layers...) {
+		if l.HasBlob(blob.Digest) {
+			continue
+		}
+		if err := c.fetchBlob(l, ref, blob); err != nil {
+			return Descriptor{}, err
+		}
+	}
+	// the manifest is stored as is to keep its digest
+	digest, size, _, err := l.WriteBlob(bytes.NewReader(raw))
+	if err != nil {
+		return Descriptor{}, err
+	}
+	if digest != desc.Digest {
+		return Descriptor{}, errors.Errorf("manifest of %s has digest %s, expected %s", ref, digest, desc.Digest)
+	}
+	return Descriptor{
+		MediaType: desc.MediaType,
+		Digest:    digest,
+		Size:      size,
+		Platform:  &platform,
+	}, nil
+}
+
+// fetchManifest fetches the manifest or index reference of the repository
+// of ref, returning its descriptor and contents
+func (c *registryClient) fetchManifest(ref Reference, reference string) (Descriptor, []byte, error) {
+	resp, err := c.get(ref, "manifests/"+reference, strings.Join([]string{
+		MediaTypeImageIndex, MediaTypeImageManifest,
+		MediaTypeDockerManifestList, MediaTypeDockerManifest,
+	}, ", "))
+	if err != nil {
+		return Descriptor{}, nil, err
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Descriptor{}, nil, errors.Wrapf(err, "failed to fetch manifest of %s", ref)
+	}
+	digest := fmt.Sprintf("sha256:%x", sha256.Sum256(raw))
+	if strings.HasPrefix(reference, "sha256:") && digest != reference {
+		return Descriptor{}, nil, errors.Errorf("manifest of %s has digest %s, expected %s", ref, digest, reference)
+	}
+	mediaType := resp.Header.Get("Content-Type")
+	if i := strings.Index(mediaType, ";"); i >= 0 {
+		mediaType = mediaType[:i]
+	}
+	return Descriptor{
+		MediaType: mediaType,
+		Digest:    digest,
+		Size:      int64(len(raw)),
+	}, raw, nil
+}
+
+// fetchBlob fetches blob from the repository of ref into the layout
+func (c *registryClient) fetchBlob(l *Layout, ref Reference, blob Descriptor) error {
+	resp, err := c.get(ref, "blobs/"+blob.Digest, "")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	digest, _, _, err := l.WriteBlob(resp.Body)
+	if err != nil {
+		return errors.Wrapf(err, "failed to fetch %s of %s", blob.Digest, ref)
+	}
+	if digest != blob.Digest {
+		return errors.Errorf("%s of %s has digest %s", blob.Digest, ref, digest)
+	}
+	return nil
+}
+
+// get requests the api path of the repository of ref, authenticating with
+// an anonymous bearer token if the registry requires it
+func (c *registryClient) get(ref Reference, apiPath, accept string) (*http.Response, error) {
+	host, scheme := ref.Registry, "https"
+	if host == "docker.io" {
+		host = "registry-1.docker.io"
+	}
+	if strings.HasPrefix(host, "localhost") || strings.HasPrefix(host, "127.0.0.1") {
+		scheme = "http"
+	}
+	u := fmt.Sprintf("%s://%s/v2/%s/%s", scheme, host, ref.Repository, apiPath)
+	scope := "repository:" + ref.Repository + ":pull"
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequest(http.MethodGet, u, nil)
+		if err != nil {
+			return nil, err
+		}
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		c.mu.Lock()
+		token := c.tokens[host+"/"+scope]
+		c.mu.Unlock()
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := c.client.Do(req)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to fetch %s", u)
+		}
+		if resp.StatusCode == http.StatusOK {
+			return resp, nil
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusUnauthorized || attempt > 0 {
+			return nil, errors.Errorf("failed to fetch %s: %s", u, resp.Status)
+		}
+		token, err = c.fetchToken(resp.Header.Get("Www-Authenticate"), scope)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to authenticate to %s", ref.Registry)
+		}
+		c.mu.Lock()
+		c.tokens[host+"/"+scope] = token
+		c.mu.Unlock()
+	}
+}
+
+// fetchToken fetches an anonymous token for the bearer challenge
+func (c *registryClient) fetchToken(challenge, scope string) (string, error) {
+	if !strings.HasPrefix(strings.ToLower(challenge), "bearer ") {
+		return "", errors.Errorf("unsupported authentication challenge %q", challenge)
+	}
+	params := map[string]string{}
+	for _, param := range strings.Split(challenge[len("bearer "):], ",") {
+		kv := strings.SplitN(strings.TrimSpace(param), "=", 2)
+		if len(kv) == 2 {
+			params[strings.ToLower(kv[0])] = strings.Trim(kv[1], `"`)
+		}
+	}
+	if params["realm"] == "" {
+		return "", errors.Errorf("authentication challenge %q has no realm", challenge)
+	}
+	query := url.Values{"scope": []string{scope}}
+	if params["service"] != "" {
+		query.Set("service", params["service"])
+	}
+	resp, err := c.client.Get(params["realm"] + "?" + query.Encode())
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", errors.Errorf("failed to fetch token: %s", resp.Status)
+	}
+	token := struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", errors.Wrap(err, "failed to parse token")
+	}
+	if token.Token != "" {
+		return token.Token, nil
+	}
+	return token.AccessToken, nil
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oci
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestParseReference(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name        string
+		Image       string
+		Expected    Reference
+		ExpectError bool
+	}{
+		{
+			Name:     "docker hub library image",
+			Image:    "debian",
+			Expected: Reference{Registry: "docker.io", Repository: "library/debian", Reference: "latest"},
+		},
+		{
+			Name:     "docker hub image",
+			Image:    "kindest/node:v1.30.0",
+			Expected: Reference{Registry: "docker.io", Repository: "kindest/node", Reference: "v1.30.0"},
+		},
+		{
+			Name:     "registry with port",
+			Image:    "localhost:5000/kind/node:v1.30.0",
+			Expected: Reference{Registry: "localhost:5000", Repository: "kind/node", Reference: "v1.30.0"},
+		},
+		{
+			Name:     "digest takes precedence over tag",
+			Image:    "registry.k8s.io/pause:3.9@sha256:abc",
+			Expected: Reference{Registry: "registry.k8s.io", Repository: "pause", Reference: "sha256:abc"},
+		},
+		{
+			Name:        "upper case repository",
+			Image:       "kindest/Node",
+			ExpectError: true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			ref, err := ParseReference(tc.Image)
+			assert.ExpectError(t, tc.ExpectError, err)
+			if err == nil {
+				assert.DeepEqual(t, tc.Expected, ref)
+			}
+		})
+	}
+}
+
+func TestPull(t *testing.T) {
+	t.Parallel()
+	config := []byte(`{"architecture":"arm64","os":"linux"}`)
+	layer := []byte("layer")
+	manifest, _ := json.Marshal(&Manifest{
+		SchemaVersion: 2,
+		MediaType:     MediaTypeDockerManifest,
+		Config:        Descriptor{MediaType: MediaTypeDockerConfig, Digest: "sha256:" + digestHex(config), Size: int64(len(config))},
+		Layers:        []Descriptor{{MediaType: MediaTypeDockerLayerGzip, Digest: "sha256:" + digestHex(layer), Size: int64(len(layer))}},
+	})
+	index, _ := json.Marshal(&Index{
+		SchemaVersion: 2,
+		MediaType:     MediaTypeDockerManifestList,
+		Manifests: []Descriptor{{
+			MediaType: MediaTypeDockerManifest,
+			Digest:    "sha256:" + digestHex(manifest),
+			Size:      int64(len(manifest)),
+			Platform:  &Platform{OS: "linux", Architecture: "arm64"},
+		}},
+	})
+	content := map[string]struct {
+		mediaType string
+		raw       []byte
+	}{
+		"/v2/kind/node/manifests/v1":                            {MediaTypeDockerManifestList, index},
+		"/v2/kind/node/manifests/sha256:" + digestHex(manifest): {MediaTypeDockerManifest, manifest},
+		"/v2/kind/node/blobs/sha256:" + digestHex(config):       {"", config},
+		"/v2/kind/node/blobs/sha256:" + digestHex(layer):        {"", layer},
+	}
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			assert.StringEqual(t, "repository:kind/node:pull", r.URL.Query().Get("scope"))
+			_, _ = w.Write([]byte(`{"token":"secret"}`))
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.Header().Set("Www-Authenticate", `Bearer realm="`+server.URL+`/token",service="test"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		c, ok := content[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if c.mediaType != "" {
+			w.Header().Set("Content-Type", c.mediaType)
+		}
+		_, _ = w.Write(c.raw)
+	}))
+	defer server.Close()
+
+	layout, err := NewLayout(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &registryClient{client: server.Client(), tokens: map[string]string{}}
+	host := strings.TrimPrefix(server.URL, "http://")
+	// the test server serves plain http like registries on localhost
+	ref := Reference{Registry: "localhost" + host[strings.Index(host, ":"):], Repository: "kind/node", Reference: "v1"}
+	platform := Platform{OS: "linux", Architecture: "arm64"}
+	desc, err := client.pull(layout, ref, platform)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assert.DeepEqual(t, Descriptor{
+		MediaType: MediaTypeDockerManifest,
+		Digest:    "sha256:" + digestHex(manifest),
+		Size:      int64(len(manifest)),
+		Platform:  &platform,
+	}, desc)
+	for _, raw := range [][]byte{config, layer, manifest} {
+		if !layout.HasBlob("sha256:" + digestHex(raw)) {
+			t.Errorf("expected blob %s in layout", digestHex(raw))
+		}
+	}
+
+	if _, err := client.pull(layout, ref, Platform{OS: "linux", Architecture: "amd64"}); err == nil {
+		t.Errorf("expected error pulling missing platform")
+	}
+}
@@ -0,0 +1,442 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeimage
+
+import (
+	"archive/tar"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+
+	"sigs.k8s.io/kind/pkg/build/nodeimage/internal/container/docker"
+	"sigs.k8s.io/kind/pkg/build/nodeimage/internal/kube"
+	"sigs.k8s.io/kind/pkg/build/nodeimage/internal/oci"
+	"sigs.k8s.io/kind/pkg/internal/patch"
+	"sigs.k8s.io/kind/pkg/internal/sets"
+	"sigs.k8s.io/kind/pkg/internal/version"
+)
+
+// preloadedImagesDir is the OCI layout of the images to import into
+// containerd on boot of nodes of daemonless builds
+const preloadedImagesDir = "/kind/images"
+
+// preloadImagesUnitPath is the systemd unit importing preloadedImagesDir
+const preloadImagesUnitPath = "/etc/systemd/system/kind-preload-images.service"
+
+// preloadImagesUnit imports the images of daemonless builds on the first
+// boot, before multi-user.target is reached which kind waits for, and again
+// if the containerd state is removed
+const preloadImagesUnit = `[Unit]
+Description=Import the images included in the kind node image
+Requires=containerd.service
+After=containerd.service
+Before=kubelet.service
+ConditionPathExists=!/var/lib/containerd/kind-images-imported
+
+[Service]
+Type=oneshot
+RemainAfterExit=yes
+ExecStart=/bin/sh -c 'tar -C ` + preloadedImagesDir + ` -c . | ctr --namespace=k8s.io images import --label=io.cri-containerd.pinned=pinned --all-platforms --no-unpack --digests -'
+ExecStartPost=/bin/touch /var/lib/containerd/kind-images-imported
+
+[Install]
+WantedBy=multi-user.target
+`
+
+// buildLayoutImage builds the node image into c.layout without running
+// containers: the base image is pulled from its registry, and a layer with
+// the kubernetes bits, the manifests and the images to preload is added.
+// The images are imported into containerd by a systemd unit when the node
+// boots, as containerd can't be run to import them at build time.
+func (c *buildContext) buildLayoutImage(bits kube.Bits) error {
+	platform := oci.Platform{OS: "linux", Architecture: c.arch}
+	c.logger.V(0).Infof("Pulling base image %q ...", c.baseImage)
+	baseDesc, err := c.layout.Pull(c.baseImage, platform)
+	if err != nil {
+		return errors.Wrapf(err, "failed to pull base image %q", c.baseImage)
+	}
+	base, err := c.layout.ReadManifest(baseDesc)
+	if err != nil {
+		return err
+	}
+	imageConfig := map[string]interface{}{}
+	if err := c.layout.ReadJSON(base.Config.Digest, &imageConfig); err != nil {
+		return err
+	}
+
+	rawVersion := bits.Version()
+	parsedVersion, err := version.ParseSemantic(rawVersion)
+	if err != nil {
+		return errors.Wrap(err, "invalid Kubernetes version")
+	}
+	files := map[string]string{
+		"/kind/version":                rawVersion,
+		defaultCNIManifestLocation:     defaultCNIManifest,
+		defaultStorageManifestLocation: defaultStorageManifest,
+		preloadImagesUnitPath:          preloadImagesUnit,
+	}
+
+	containerdConfig, err := c.layout.ReadFile(base, containerdConfigPath)
+	if err != nil {
+		return errors.Wrap(err, "failed to read containerd config of base image")
+	}
+	pauseImage, err := findSandboxImage(string(containerdConfig))
+	if err != nil {
+		return err
+	}
+	if parsedVersion.LessThan(version.MustParseSemantic("v1.24.0")) {
+		patched, err := patch.TOML(string(containerdConfig), []string{containerdConfigPatchSystemdCgroupFalse}, []string{})
+		if err != nil {
+			return errors.Wrap(err, "failed to configure containerd SystemdCgroup=false")
+		}
+		files[containerdConfigPath] = patched
+	}
+
+	imagesDir, err := os.MkdirTemp("", "kind-node-images-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(imagesDir)
+	images, err := oci.NewLayout(imagesDir)
+	if err != nil {
+		return err
+	}
+	preloaded, err := c.preloadImages(images, bits, pauseImage, platform)
+	if err != nil {
+		c.logger.Errorf("Image build Failed! Failed to pull Images: %v", err)
+		return err
+	}
+
+	// record what went into the image
+	manifest, err := c.newBuildManifest(bits, baseDesc.Digest, preloaded, files)
+	if err != nil {
+		c.logger.Errorf("Image build Failed! Failed to create build manifest: %v", err)
+		return err
+	}
+	if files[buildManifestPath], err = manifest.encode(); err != nil {
+		return err
+	}
+
+	// add the layer, owned by root as the files of a container build.
+	// the files are added in a stable order with a fixed time, so building
+	// the same bits from the same images results in the same image
+	now, err := buildTime()
+	if err != nil {
+		return err
+	}
+	layer, diffID, err := c.layout.WriteLayer(func(tw *tar.Writer) error {
+		w := newLayerWriter(tw, tar.Header{ModTime: now, Uname: "root", Gname: "root"})
+		for _, binary := range bits.BinaryPaths() {
+			// TODO: probably should be /usr/local/bin, but the existing kubelet
+			// service file expects /usr/bin/kubelet
+			if err := w.addFile(binary, "/usr/bin/"+path.Base(binary), 0755); err != nil {
+				return err
+			}
+		}
+		names := make([]string, 0, len(files))
+		for name := range files {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if err := w.addContents(name, files[name]); err != nil {
+				return err
+			}
+		}
+		if err := w.addSymlink("/etc/systemd/system/multi-user.target.wants/"+path.Base(preloadImagesUnitPath), preloadImagesUnitPath); err != nil {
+			return err
+		}
+		if err := w.addDir(path.Dir(preloadedImagesDir)); err != nil {
+			return err
+		}
+		if err := images.WriteDir(tw, preloadedImagesDir, w.hdr); err != nil {
+			return err
+		}
+		// add the extra files last, they may replace the files of kind
+		for _, f := range c.extraFiles {
+			if err := w.addPath(f.hostPath, f.nodePath); err != nil {
+				return errors.Wrapf(err, "failed to add extra file %q", f.hostPath)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to write node image layer")
+	}
+
+	// update the config as the docker build does
+	containerConfig, _ := imageConfig["config"].(map[string]interface{})
+	if containerConfig == nil {
+		containerConfig = map[string]interface{}{}
+		imageConfig["config"] = containerConfig
+	}
+	containerConfig["Entrypoint"] = []string{"/usr/local/bin/entrypoint", "/sbin/init"}
+	rootfs, _ := imageConfig["rootfs"].(map[string]interface{})
+	if rootfs == nil {
+		return errors.New("base image config has no rootfs")
+	}
+	diffIDs, _ := rootfs["diff_ids"].([]interface{})
+	rootfs["diff_ids"] = append(diffIDs, diffID)
+	history, _ := imageConfig["history"].([]interface{})
+	imageConfig["history"] = append(history, map[string]interface{}{
+		"created":    now.Format(time.RFC3339),
+		"created_by": "kind build node-image",
+	})
+	imageConfig["created"] = now.Format(time.RFC3339)
+	configDesc, err := c.layout.WriteJSON(oci.MediaTypeImageConfig, imageConfig)
+	if err != nil {
+		return err
+	}
+
+	desc, err := c.layout.WriteJSON(oci.MediaTypeImageManifest, &oci.Manifest{
+		SchemaVersion: 2,
+		MediaType:     oci.MediaTypeImageManifest,
+		Config:        configDesc,
+		Layers:        append(oci.OCILayers(base), layer),
+	})
+	if err != nil {
+		return err
+	}
+	desc.Platform = &platform
+	c.manifest = &desc
+	c.logger.V(0).Infof("Image %q build completed.", c.image)
+	return nil
+}
+
+// preloadImages adds the images built with kubernetes, the images required
+// by kubeadm, the default CNI and storage, and the extra images and image
+// archives to the layout images
+func (c *buildContext) preloadImages(images *oci.Layout, bits kube.Bits, pauseImage string, platform oci.Platform) ([]imageDigest, error) {
+	fixedImagesMap, err := c.getFixedBuiltImages(bits)
+	if err != nil {
+		return nil, err
+	}
+	preloaded := []imageDigest{}
+	builtImages := sets.NewString()
+	for _, path := range bits.ImagePaths() {
+		desc, err := images.ImportDockerArchive(path, platform)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to import %q", path)
+		}
+		tags, err := docker.GetArchiveTags(path)
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(tags)
+		for _, tag := range tags {
+			if err := images.Tag(desc, fixedImagesMap[tag]); err != nil {
+				return nil, err
+			}
+			builtImages.Insert(fixedImagesMap[tag])
+			preloaded = append(preloaded, imageDigest{Name: fixedImagesMap[tag], Digest: desc.Digest})
+		}
+	}
+
+	for _, path := range c.extraImageArchives {
+		desc, err := images.ImportDockerArchive(path, platform)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to import %q", path)
+		}
+		tags, err := docker.GetArchiveTags(path)
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(tags)
+		for _, tag := range tags {
+			if err := images.Tag(desc, tag); err != nil {
+				return nil, err
+			}
+			builtImages.Insert(tag)
+			preloaded = append(preloaded, imageDigest{Name: tag, Digest: desc.Digest})
+		}
+	}
+
+	// the kubeadm binary of the build has to run on this host
+	kubeadm := ""
+	for _, binary := range bits.BinaryPaths() {
+		if path.Base(binary) == "kubeadm" {
+			kubeadm = binary
+		}
+	}
+	if kubeadm == "" {
+		return nil, errors.New("kubeadm is not in the kubernetes build")
+	}
+	requiredImages, err := exec.OutputLines(exec.Command(
+		kubeadm, "config", "images", "list", "--kubernetes-version", bits.Version(),
+	))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list the images required by kubeadm, %s kubeadm must be executable on this host", c.arch)
+	}
+	n := 0
+	for _, image := range requiredImages {
+		if !strings.Contains(image, "pause") {
+			requiredImages[n] = image
+			n++
+		}
+	}
+	requiredImages = append(requiredImages[:n], pauseImage)
+	requiredImages = append(requiredImages, defaultCNIImages...)
+	requiredImages = append(requiredImages, defaultStorageImages...)
+	requiredImages = append(requiredImages, c.extraImages...)
+
+	pulled := make([]oci.Descriptor, len(requiredImages))
+	fns := []func() error{}
+	for i, image := range requiredImages {
+		i, image := i, image // capture loop vars
+		if builtImages.Has(image) {
+			continue
+		}
+		fns = append(fns, func() error {
+			c.logger.V(1).Infof("Pulling %s ...", image)
+			desc, err := images.Pull(image, platform)
+			if err != nil {
+				return errors.Wrapf(err, "failed to pull %s", image)
+			}
+			pulled[i] = desc
+			return nil
+		})
+	}
+	if err := errors.AggregateConcurrent(fns); err != nil {
+		return nil, err
+	}
+	for i, image := range requiredImages {
+		if builtImages.Has(image) {
+			continue
+		}
+		ref, err := oci.ParseReference(image)
+		if err != nil {
+			return nil, err
+		}
+		// containerd names the images with the fully qualified reference
+		if err := images.Tag(pulled[i], ref.String()); err != nil {
+			return nil, err
+		}
+		preloaded = append(preloaded, imageDigest{Name: ref.String(), Digest: pulled[i].Digest})
+	}
+	return preloaded, nil
+}
+
+// layerWriter writes the files of a layer, adding their parent directories
+type layerWriter struct {
+	tw *tar.Writer
+	// hdr holds the fields common to all files
+	hdr  tar.Header
+	dirs sets.String
+}
+
+func newLayerWriter(tw *tar.Writer, hdr tar.Header) *layerWriter {
+	return &layerWriter{tw: tw, hdr: hdr, dirs: sets.NewString()}
+}
+
+// addFile adds the file src on the host as dst with mode
+func (w *layerWriter) addFile(src, dst string, mode int64) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if err := w.addDir(path.Dir(dst)); err != nil {
+		return err
+	}
+	hdr := w.hdr
+	hdr.Typeflag, hdr.Name, hdr.Mode, hdr.Size = tar.TypeReg, strings.TrimPrefix(dst, "/"), mode, info.Size()
+	if err := w.tw.WriteHeader(&hdr); err != nil {
+		return err
+	}
+	if _, err := io.Copy(w.tw, f); err != nil {
+		return errors.Wrapf(err, "failed to add %q", src)
+	}
+	return nil
+}
+
+// addPath adds the file, symlink or directory src on the host as dst,
+// directories are added recursively
+func (w *layerWriter) addPath(src, dst string) error {
+	return filepath.Walk(src, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		nodePath := path.Join(dst, filepath.ToSlash(rel))
+		switch {
+		case info.IsDir():
+			return w.addDir(nodePath)
+		case info.Mode()&os.ModeSymlink != 0:
+			target, err := os.Readlink(p)
+			if err != nil {
+				return err
+			}
+			return w.addSymlink(nodePath, target)
+		case info.Mode().IsRegular():
+			return w.addFile(p, nodePath, int64(info.Mode().Perm()))
+		}
+		return errors.Errorf("unsupported file type of %q", p)
+	})
+}
+
+// addContents adds a file with contents as dst
+func (w *layerWriter) addContents(dst, contents string) error {
+	if err := w.addDir(path.Dir(dst)); err != nil {
+		return err
+	}
+	hdr := w.hdr
+	hdr.Typeflag, hdr.Name, hdr.Mode, hdr.Size = tar.TypeReg, strings.TrimPrefix(dst, "/"), 0644, int64(len(contents))
+	if err := w.tw.WriteHeader(&hdr); err != nil {
+		return err
+	}
+	_, err := w.tw.Write([]byte(contents))
+	return err
+}
+
+// addSymlink adds a symlink dst to target
+func (w *layerWriter) addSymlink(dst, target string) error {
+	if err := w.addDir(path.Dir(dst)); err != nil {
+		return err
+	}
+	hdr := w.hdr
+	hdr.Typeflag, hdr.Name, hdr.Mode, hdr.Linkname = tar.TypeSymlink, strings.TrimPrefix(dst, "/"), 0777, target
+	return w.tw.WriteHeader(&hdr)
+}
+
+// addDir adds dir and its parents, so they are not created with unexpected
+// permissions when the layer is extracted
+func (w *layerWriter) addDir(dir string) error {
+	if dir == "/" || w.dirs.Has(dir) {
+		return nil
+	}
+	if err := w.addDir(path.Dir(dir)); err != nil {
+		return err
+	}
+	w.dirs.Insert(dir)
+	hdr := w.hdr
+	hdr.Typeflag, hdr.Name, hdr.Mode = tar.TypeDir, strings.TrimPrefix(dir, "/")+"/", 0755
+	return w.tw.WriteHeader(&hdr)
+}
@@ -0,0 +1,206 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeimage
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/build/nodeimage/internal/container/docker"
+	"sigs.k8s.io/kind/pkg/build/nodeimage/internal/kube"
+)
+
+// buildManifestPath is the path of the build manifest in node images
+const buildManifestPath = "/kind/build-manifest.json"
+
+// buildManifest records what went into a node image, it is written to
+// buildManifestPath and checked by Verify
+type buildManifest struct {
+	KubernetesVersion string `json:"kubernetesVersion"`
+	// Source is how kubernetes was built
+	Source buildSource `json:"source"`
+	// BaseImage is the base image the node image was built from
+	BaseImage imageDigest `json:"baseImage"`
+	// ImageArchives are the image archives of the kubernetes build and the
+	// extra image archives loaded into the node
+	ImageArchives []fileDigest `json:"imageArchives"`
+	// Files are the files added to the node, including the binaries, the
+	// manifests and the extra files
+	Files []fileDigest `json:"files"`
+	// Images are the images pre-loaded into the node
+	Images []imageDigest `json:"images"`
+}
+
+// buildSource is how kubernetes was built, local paths are not recorded
+// since they do not identify what was built
+type buildSource struct {
+	Type string `json:"type"`
+	// URL is the url of the server tarball, with version markers resolved
+	URL string `json:"url,omitempty"`
+	// SHA256 is the checksum of the server tarball
+	SHA256 string `json:"sha256,omitempty"`
+	Arch   string `json:"arch"`
+}
+
+type imageDigest struct {
+	Name   string `json:"name"`
+	Digest string `json:"digest"`
+}
+
+type fileDigest struct {
+	Path   string `json:"path"`
+	SHA256 string `json:"sha256"`
+}
+
+// newBuildManifest returns the build manifest of a node image of bits built
+// from the base image baseDigest, with the pre-loaded images and the
+// generated files by node path
+func (c *buildContext) newBuildManifest(bits kube.Bits, baseDigest string, images []imageDigest, files map[string]string) (*buildManifest, error) {
+	m := &buildManifest{
+		KubernetesVersion: bits.Version(),
+		Source: buildSource{
+			Type:   c.buildType,
+			URL:    bits.Source().URL,
+			SHA256: bits.Source().SHA256,
+			Arch:   c.arch,
+		},
+		BaseImage: imageDigest{Name: c.baseImage, Digest: baseDigest},
+		Images:    images,
+	}
+	for _, archive := range append(append([]string{}, bits.ImagePaths()...), c.extraImageArchives...) {
+		sum, err := sha256File(archive)
+		if err != nil {
+			return nil, err
+		}
+		m.ImageArchives = append(m.ImageArchives, fileDigest{Path: filepath.Base(archive), SHA256: sum})
+	}
+	for _, binary := range bits.BinaryPaths() {
+		sum, err := sha256File(binary)
+		if err != nil {
+			return nil, err
+		}
+		m.Files = append(m.Files, fileDigest{Path: "/usr/bin/" + path.Base(binary), SHA256: sum})
+	}
+	for nodePath, contents := range files {
+		m.Files = append(m.Files, fileDigest{Path: nodePath, SHA256: fmt.Sprintf("%x", sha256.Sum256([]byte(contents)))})
+	}
+	for _, f := range c.extraFiles {
+		// directories are copied recursively, symlinks are not checked
+		err := filepath.Walk(f.hostPath, func(p string, info os.FileInfo, err error) error {
+			if err != nil || !info.Mode().IsRegular() {
+				return err
+			}
+			rel, err := filepath.Rel(f.hostPath, p)
+			if err != nil {
+				return err
+			}
+			sum, err := sha256File(p)
+			if err != nil {
+				return err
+			}
+			m.Files = append(m.Files, fileDigest{Path: path.Join(f.nodePath, filepath.ToSlash(rel)), SHA256: sum})
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	m.sort()
+	return m, nil
+}
+
+// sort sorts the lists of the manifest, so the manifest of the same build
+// is the same
+func (m *buildManifest) sort() {
+	sort.Slice(m.ImageArchives, func(i, j int) bool { return m.ImageArchives[i].Path < m.ImageArchives[j].Path })
+	// the last copy of a file is the one in the image
+	files := map[string]fileDigest{}
+	for _, f := range m.Files {
+		files[f.Path] = f
+	}
+	m.Files = m.Files[:0]
+	for _, f := range files {
+		m.Files = append(m.Files, f)
+	}
+	sort.Slice(m.Files, func(i, j int) bool { return m.Files[i].Path < m.Files[j].Path })
+	sort.Slice(m.Images, func(i, j int) bool { return m.Images[i].Name < m.Images[j].Name })
+}
+
+// encode returns the manifest as written to buildManifestPath
+func (m *buildManifest) encode() (string, error) {
+	raw, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(raw) + "\n", nil
+}
+
+// baseImageDigest returns the digest of the base image in docker, or its
+// id if it was not pulled from a registry
+func (c *buildContext) baseImageDigest() (string, error) {
+	lines, err := docker.ImageInspect(c.baseImage, "{{ range .RepoDigests }}{{ println . }}{{ end }}{{ .Id }}")
+	if err != nil {
+		return "", errors.Wrap(err, "failed to inspect base image")
+	}
+	for _, line := range lines {
+		if i := strings.Index(line, "@"); i >= 0 {
+			return line[i+1:], nil
+		}
+	}
+	if len(lines) == 0 {
+		return "", errors.Errorf("failed to inspect base image %q", c.baseImage)
+	}
+	return lines[len(lines)-1], nil
+}
+
+// buildTime returns the time of the files of reproducible builds, which is
+// $SOURCE_DATE_EPOCH if set or else the unix epoch
+func buildTime() (time.Time, error) {
+	epoch := os.Getenv("SOURCE_DATE_EPOCH")
+	if epoch == "" {
+		return time.Unix(0, 0).UTC(), nil
+	}
+	seconds, err := strconv.ParseInt(epoch, 10, 64)
+	if err != nil {
+		return time.Time{}, errors.Wrapf(err, "invalid SOURCE_DATE_EPOCH %q", epoch)
+	}
+	return time.Unix(seconds, 0).UTC(), nil
+}
+
+func sha256File(filePath string) (string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", errors.Wrapf(err, "failed to read %q", filePath)
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeimage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/internal/assert"
+
+	"sigs.k8s.io/kind/pkg/build/nodeimage/internal/kube"
+)
+
+type fakeBits struct {
+	binaryPaths []string
+	imagePaths  []string
+	version     string
+}
+
+func (b *fakeBits) BinaryPaths() []string { return b.binaryPaths }
+func (b *fakeBits) ImagePaths() []string  { return b.imagePaths }
+func (b *fakeBits) Version() string       { return b.version }
+func (b *fakeBits) Source() kube.Source {
+	return kube.Source{URL: "https://dl.k8s.io/release/v1.30.0/kubernetes-server-linux-amd64.tar.gz", SHA256: "0123"}
+}
+
+func TestNewBuildManifest(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	write := func(name, contents string) string {
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	// sha256 of "a" and "b"
+	const sumA = "ca978112ca1bbdcafac231b39a23dc4da786eff8147c4e72b9807785afee48bb"
+	const sumB = "3e23e8160039594a33894f6564e1b1348bbd7a0088d42c4acb73eeaed59c009d"
+
+	c := &buildContext{
+		buildType:  "release",
+		kubeParam:  "v1.30.0",
+		arch:       "amd64",
+		baseImage:  "kindest/base:test",
+		extraFiles: []extraFile{{hostPath: filepath.Join(dir, "extra"), nodePath: "/etc/extra"}},
+	}
+	write("extra/b", "b")
+	write("extra/sub/a", "a")
+	bits := &fakeBits{
+		binaryPaths: []string{write("bin/kubelet", "b"), write("bin/kubeadm", "a")},
+		imagePaths:  []string{write("images/kube-proxy.tar", "a")},
+		version:     "v1.30.0",
+	}
+	m, err := c.newBuildManifest(bits, "sha256:base", []imageDigest{
+		{Name: "registry.k8s.io/pause:3.9", Digest: "sha256:pause"},
+		{Name: "registry.k8s.io/etcd:3.5", Digest: "sha256:etcd"},
+	}, map[string]string{
+		"/kind/version": "b",
+		// replaced by the extra files
+		"/etc/extra/b": "a",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assert.DeepEqual(t, &buildManifest{
+		KubernetesVersion: "v1.30.0",
+		Source: buildSource{
+			Type:   "release",
+			URL:    "https://dl.k8s.io/release/v1.30.0/kubernetes-server-linux-amd64.tar.gz",
+			SHA256: "0123",
+			Arch:   "amd64",
+		},
+		BaseImage:     imageDigest{Name: "kindest/base:test", Digest: "sha256:base"},
+		ImageArchives: []fileDigest{{Path: "kube-proxy.tar", SHA256: sumA}},
+		Files: []fileDigest{
+			{Path: "/etc/extra/b", SHA256: sumB},
+			{Path: "/etc/extra/sub/a", SHA256: sumA},
+			{Path: "/kind/version", SHA256: sumB},
+			{Path: "/usr/bin/kubeadm", SHA256: sumA},
+			{Path: "/usr/bin/kubelet", SHA256: sumB},
+		},
+		Images: []imageDigest{
+			{Name: "registry.k8s.io/etcd:3.5", Digest: "sha256:etcd"},
+			{Name: "registry.k8s.io/pause:3.9", Digest: "sha256:pause"},
+		},
+	}, m)
+}
+
+func TestBuildTime(t *testing.T) {
+	t.Setenv("SOURCE_DATE_EPOCH", "")
+	epoch, err := buildTime()
+	assert.ExpectError(t, false, err)
+	assert.BoolEqual(t, true, epoch.Equal(time.Unix(0, 0)))
+
+	t.Setenv("SOURCE_DATE_EPOCH", "1700000000")
+	epoch, err = buildTime()
+	assert.ExpectError(t, false, err)
+	assert.BoolEqual(t, true, epoch.Equal(time.Unix(1700000000, 0)))
+
+	t.Setenv("SOURCE_DATE_EPOCH", "yesterday")
+	_, err = buildTime()
+	assert.ExpectError(t, true, err)
+}
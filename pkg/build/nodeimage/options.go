@@ -17,6 +17,14 @@ limitations under the License.
 package nodeimage
 
 import (
+	"net/url"
+	"os"
+	"path"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/apis/config/v1alpha4"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
 	"sigs.k8s.io/kind/pkg/log"
 )
 
@@ -73,6 +81,111 @@ func WithArch(arch string) Option {
 	})
 }
 
+// ArchPlaceholder is replaced with the architecture being built for in the
+// kubernetes param, e.g. in the url of the server tarball of each architecture
+const ArchPlaceholder = "{arch}"
+
+// WithArchitectures sets multiple architectures to build for, the images of
+// each architecture are assembled into an OCI image index.
+// Multi-architecture builds must be written to an OCI layout with
+// WithOCILayout or pushed with WithPush.
+func WithArchitectures(archs ...string) Option {
+	return optionAdapter(func(b *buildContext) error {
+		for _, arch := range archs {
+			if arch == "" {
+				return errors.New("architectures may not be empty")
+			}
+		}
+		b.archs = archs
+		return nil
+	})
+}
+
+// WithOCILayout configures a build to also write the image to the OCI image
+// layout at dir, tagged with the image name. The layout is created if it
+// does not exist, and other images in it are kept.
+func WithOCILayout(dir string) Option {
+	return optionAdapter(func(b *buildContext) error {
+		b.ociLayout = dir
+		return nil
+	})
+}
+
+// WithDockerArchive configures a build to also write the image to path as
+// a docker save compatible archive, which is an OCI image layout as well.
+// Multi-architecture builds can't be written to docker archives.
+func WithDockerArchive(path string) Option {
+	return optionAdapter(func(b *buildContext) error {
+		b.dockerArchive = path
+		return nil
+	})
+}
+
+// WithDaemonless configures a build to not use docker, the image is built
+// from the base image in its registry and written to the OCI layout of
+// WithOCILayout or the archive of WithDockerArchive only.
+// Kubernetes must be built from a release, url or file.
+func WithDaemonless(daemonless bool) Option {
+	return optionAdapter(func(b *buildContext) error {
+		b.daemonless = daemonless
+		return nil
+	})
+}
+
+// WithPush configures a build to push the image to its registry
+func WithPush(push bool) Option {
+	return optionAdapter(func(b *buildContext) error {
+		b.push = push
+		return nil
+	})
+}
+
+// WithExtraImages configures a build to pre-pull images into the node in
+// addition to the images kind requires
+func WithExtraImages(images ...string) Option {
+	return optionAdapter(func(b *buildContext) error {
+		b.extraImages = append(b.extraImages, images...)
+		return nil
+	})
+}
+
+// WithExtraImageArchives configures a build to pre-load the images in the
+// image archives at paths into the node, as with kind load image-archive
+func WithExtraImageArchives(paths ...string) Option {
+	return optionAdapter(func(b *buildContext) error {
+		for _, p := range paths {
+			if _, err := os.Stat(p); err != nil {
+				return errors.Wrapf(err, "invalid extra image archive %q", p)
+			}
+		}
+		b.extraImageArchives = append(b.extraImageArchives, paths...)
+		return nil
+	})
+}
+
+// WithExtraFiles configures a build to copy files or directories of the
+// host into the node image, as hostPath:nodePath. The files are copied after
+// the files of kind so they may replace them, e.g. to add systemd units.
+func WithExtraFiles(files ...string) Option {
+	return optionAdapter(func(b *buildContext) error {
+		for _, file := range files {
+			i := strings.LastIndex(file, ":")
+			if i <= 0 || i == len(file)-1 {
+				return errors.Errorf("invalid extra file %q, expected hostPath:nodePath", file)
+			}
+			f := extraFile{hostPath: file[:i], nodePath: file[i+1:]}
+			if !path.IsAbs(f.nodePath) {
+				return errors.Errorf("invalid extra file %q, the node path must be absolute", file)
+			}
+			if _, err := os.Lstat(f.hostPath); err != nil {
+				return errors.Wrapf(err, "invalid extra file %q", file)
+			}
+			b.extraFiles = append(b.extraFiles, f)
+		}
+		return nil
+	})
+}
+
 // WithArch sets the architecture to build for
 func WithBuildType(buildType string) Option {
 	return optionAdapter(func(b *buildContext) error {
@@ -82,3 +195,58 @@ func WithBuildType(buildType string) Option {
 		return nil
 	})
 }
+
+// WithArtifactBaseURL sets the base url to download release and CI build
+// artifacts and version markers from instead of https://dl.k8s.io, e.g. a
+// local mirror with the same layout
+func WithArtifactBaseURL(baseURL string) Option {
+	return optionAdapter(func(b *buildContext) error {
+		if baseURL != "" {
+			u, err := url.ParseRequestURI(baseURL)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+				return errors.Errorf("invalid artifact base url %q", baseURL)
+			}
+			b.artifactBaseURL = baseURL
+		}
+		return nil
+	})
+}
+
+// WithRegistryMirrors configures the registry mirrors to pull the images
+// included in the node image from, as in the cluster config
+func WithRegistryMirrors(mirrors []v1alpha4.RegistryMirror) Option {
+	return optionAdapter(func(b *buildContext) error {
+		for _, m := range mirrors {
+			mirror := config.RegistryMirror{
+				Registry:  m.Registry,
+				Endpoints: m.Endpoints,
+			}
+			if err := mirror.Validate(); err != nil {
+				return errors.Wrapf(err, "invalid registry mirror for %q", m.Registry)
+			}
+			b.registryMirrors = append(b.registryMirrors, mirror)
+		}
+		return nil
+	})
+}
+
+// WithRegistryAuth configures TLS verification and credentials for the
+// registry and mirror hosts the included images are pulled from,
+// as in the cluster config
+func WithRegistryAuth(auth []v1alpha4.RegistryAuth) Option {
+	return optionAdapter(func(b *buildContext) error {
+		for _, a := range auth {
+			registryAuth := config.RegistryAuth{
+				Host:               a.Host,
+				CAFile:             a.CAFile,
+				InsecureSkipVerify: a.InsecureSkipVerify,
+				CredentialsFile:    a.CredentialsFile,
+			}
+			if err := registryAuth.Validate(); err != nil {
+				return errors.Wrapf(err, "invalid registry auth for %q", a.Host)
+			}
+			b.registryAuth = append(b.registryAuth, registryAuth)
+		}
+		return nil
+	})
+}
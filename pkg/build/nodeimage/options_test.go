@@ -0,0 +1,73 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeimage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestWithExtraFiles(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	hostPath := filepath.Join(dir, "operator.service")
+	if err := os.WriteFile(hostPath, []byte("[Unit]"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		Name        string
+		File        string
+		Expected    []extraFile
+		ExpectError bool
+	}{
+		{
+			Name:     "file",
+			File:     hostPath + ":/etc/systemd/system/operator.service",
+			Expected: []extraFile{{hostPath: hostPath, nodePath: "/etc/systemd/system/operator.service"}},
+		},
+		{
+			Name:        "relative node path",
+			File:        hostPath + ":etc/operator.service",
+			ExpectError: true,
+		},
+		{
+			Name:        "missing node path",
+			File:        hostPath,
+			ExpectError: true,
+		},
+		{
+			Name:        "missing host file",
+			File:        filepath.Join(dir, "missing") + ":/etc/missing",
+			ExpectError: true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			b := &buildContext{}
+			err := WithExtraFiles(tc.File).apply(b)
+			assert.ExpectError(t, tc.ExpectError, err)
+			if err == nil {
+				assert.DeepEqual(t, tc.Expected, b.extraFiles)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeimage
+
+import (
+	"os"
+	"path/filepath"
+
+	"sigs.k8s.io/kind/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/build/nodeimage/internal/container/docker"
+	"sigs.k8s.io/kind/pkg/build/nodeimage/internal/oci"
+)
+
+// builtImage is the image built for an architecture
+type builtImage struct {
+	arch  string
+	image string
+	// manifest is set for daemonless builds
+	manifest *oci.Descriptor
+}
+
+// publish writes the built images to the configured outputs, assembled
+// into a single image c.image
+func (c *buildContext) publish(built []builtImage) error {
+	if c.layout != nil {
+		return c.publishLayout(built)
+	}
+	if c.dockerArchive != "" {
+		c.logger.V(0).Infof("Writing %q to docker archive %q ...", c.image, c.dockerArchive)
+		if err := docker.Save(c.image, c.dockerArchive); err != nil {
+			return errors.Wrapf(err, "failed to save image %q", c.image)
+		}
+	}
+	if c.ociLayout != "" {
+		if err := c.writeOCILayout(built); err != nil {
+			return err
+		}
+	}
+	if c.push {
+		if err := c.pushImages(built); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeOCILayout writes an image index of the built images to the OCI
+// layout, tagged with c.image
+func (c *buildContext) writeOCILayout(built []builtImage) error {
+	c.logger.V(0).Infof("Writing %q to OCI layout %q ...", c.image, c.ociLayout)
+	layout, err := oci.NewLayout(c.ociLayout)
+	if err != nil {
+		return err
+	}
+	tmpDir, err := os.MkdirTemp("", "kind-node-image-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	manifests := []oci.Descriptor{}
+	for _, b := range built {
+		archive := filepath.Join(tmpDir, b.arch+".tar")
+		if err := docker.Save(b.image, archive); err != nil {
+			return errors.Wrapf(err, "failed to save image %q", b.image)
+		}
+		desc, err := layout.ImportDockerArchive(archive, oci.Platform{
+			OS:           "linux",
+			Architecture: b.arch,
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to write image %q to OCI layout", b.image)
+		}
+		manifests = append(manifests, desc)
+		// the archives may be large
+		if err := os.Remove(archive); err != nil {
+			return err
+		}
+	}
+	index, err := layout.WriteImageIndex(manifests)
+	if err != nil {
+		return err
+	}
+	return layout.Tag(index, c.image)
+}
+
+// publishLayout tags the images of daemonless builds in their layout, and
+// writes them to the docker archive if configured
+func (c *buildContext) publishLayout(built []builtImage) error {
+	desc := *built[0].manifest
+	if len(built) > 1 {
+		manifests := []oci.Descriptor{}
+		for _, b := range built {
+			manifests = append(manifests, *b.manifest)
+		}
+		index, err := c.layout.WriteImageIndex(manifests)
+		if err != nil {
+			return err
+		}
+		desc = index
+	}
+	if c.ociLayout != "" {
+		c.logger.V(0).Infof("Writing %q to OCI layout %q ...", c.image, c.ociLayout)
+		if err := c.layout.Tag(desc, c.image); err != nil {
+			return err
+		}
+	}
+	if c.dockerArchive != "" {
+		c.logger.V(0).Infof("Writing %q to docker archive %q ...", c.image, c.dockerArchive)
+		f, err := os.Create(c.dockerArchive)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if err := c.layout.WriteArchive(f, desc, c.image); err != nil {
+			return errors.Wrap(err, "failed to write docker archive")
+		}
+		return f.Close()
+	}
+	return nil
+}
+
+// pushImages pushes the built images, and a manifest list of them as
+// c.image for multi-architecture builds
+func (c *buildContext) pushImages(built []builtImage) error {
+	images := []string{}
+	for _, b := range built {
+		c.logger.V(0).Infof("Pushing %q ...", b.image)
+		if err := docker.Push(b.image); err != nil {
+			return errors.Wrapf(err, "failed to push image %q", b.image)
+		}
+		images = append(images, b.image)
+	}
+	if len(built) == 1 && built[0].image == c.image {
+		return nil
+	}
+	c.logger.V(0).Infof("Pushing manifest list %q of %v ...", c.image, images)
+	if err := docker.PushManifestList(c.image, images); err != nil {
+		return errors.Wrapf(err, "failed to push manifest list %q", c.image)
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeimage
+
+import (
+	"encoding/json"
+	"runtime"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+	"sigs.k8s.io/kind/pkg/log"
+
+	"sigs.k8s.io/kind/pkg/build/nodeimage/internal/container/docker"
+	"sigs.k8s.io/kind/pkg/build/nodeimage/internal/oci"
+)
+
+// Verify checks that the files and pre-loaded images of the node image
+// configured with WithImage match the build manifest recorded in the image
+// when it was built. The image is run with docker to check it.
+func Verify(options ...Option) error {
+	ctx := &buildContext{
+		image:  DefaultImage,
+		logger: log.NoopLogger{},
+		arch:   runtime.GOARCH,
+	}
+	for _, option := range options {
+		if err := option.apply(ctx); err != nil {
+			return err
+		}
+	}
+	return ctx.verify()
+}
+
+func (c *buildContext) verify() error {
+	c.logger.V(0).Infof("Verifying image %q ...", c.image)
+	containerID, err := c.createBuildContainer(c.image)
+	if containerID != "" {
+		defer func() {
+			_ = exec.Command("docker", "rm", "-f", "-v", containerID).Run()
+		}()
+	}
+	if err != nil {
+		return err
+	}
+	cmder := docker.ContainerCmder(containerID)
+
+	raw, err := exec.Output(cmder.Command("cat", buildManifestPath))
+	if err != nil {
+		return errors.Wrapf(err, "failed to read %s, the image may have been built by an older kind", buildManifestPath)
+	}
+	manifest := &buildManifest{}
+	if err := json.Unmarshal(raw, manifest); err != nil {
+		return errors.Wrapf(err, "failed to parse %s", buildManifestPath)
+	}
+
+	errs := []error{}
+	for _, f := range manifest.Files {
+		lines, err := exec.OutputLines(cmder.Command("sha256sum", f.Path))
+		if err != nil || len(lines) == 0 {
+			errs = append(errs, errors.Errorf("%s is missing", f.Path))
+			continue
+		}
+		if sum := strings.Fields(lines[0])[0]; sum != f.SHA256 {
+			errs = append(errs, errors.Errorf("%s has sha256 %s, expected %s", f.Path, sum, f.SHA256))
+		}
+	}
+
+	images, err := c.verifyImageDigests(cmder)
+	if err != nil {
+		return err
+	}
+	for _, image := range manifest.Images {
+		digest, ok := images[image.Name]
+		if !ok {
+			errs = append(errs, errors.Errorf("image %s is missing", image.Name))
+		} else if digest != image.Digest {
+			errs = append(errs, errors.Errorf("image %s has digest %s, expected %s", image.Name, digest, image.Digest))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Wrapf(errors.NewAggregate(errs), "image %q does not match its build manifest", c.image)
+	}
+	c.logger.V(0).Infof("Image %q matches its build manifest of Kubernetes %s.", c.image, manifest.KubernetesVersion)
+	return nil
+}
+
+// verifyImageDigests returns the digests of the images pre-loaded into the
+// node image by name, from the images to import on boot for daemonless
+// builds and else from containerd
+func (c *buildContext) verifyImageDigests(cmder exec.Cmder) (map[string]string, error) {
+	if raw, err := exec.Output(cmder.Command("cat", preloadedImagesDir+"/index.json")); err == nil {
+		index := &oci.Index{}
+		if err := json.Unmarshal(raw, index); err != nil {
+			return nil, errors.Wrap(err, "failed to parse the images of the node image")
+		}
+		digests := map[string]string{}
+		for _, m := range index.Manifests {
+			digests[m.Annotations[oci.AnnotationImageName]] = m.Digest
+		}
+		return digests, nil
+	}
+
+	importer := newContainerdImporter(cmder, nil)
+	if err := importer.Prepare(); err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = importer.End()
+	}()
+	if err := importer.WaitForReady(); err != nil {
+		return nil, err
+	}
+	return importer.ListImportedDigests()
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+	"sigs.k8s.io/kind/pkg/log"
+
+	internalcreate "sigs.k8s.io/kind/pkg/cluster/internal/create"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
+)
+
+// Names of the built-in Provider.Create actions, in the order they run.
+// These may be used with CreateWithActionBefore, CreateWithActionAfter and
+// CreateWithSkipActions.
+const (
+	// CreateActionLoadBalancer configures the external load balancer, if any
+	CreateActionLoadBalancer = internalcreate.LoadBalancerAction
+	// CreateActionConfig writes the kubeadm and containerd config to the nodes
+	CreateActionConfig = internalcreate.ConfigAction
+	// CreateActionKubeadmInit runs kubeadm init on the bootstrap control plane
+	CreateActionKubeadmInit = internalcreate.KubeadmInitAction
+	// CreateActionVerifyAPIServer reconfigures the external load balancer
+	// to verify the API servers, if enabled in config
+	CreateActionVerifyAPIServer = internalcreate.VerifyAPIServerAction
+	// CreateActionInstallCNI installs the default CNI, unless disabled in config
+	CreateActionInstallCNI = internalcreate.InstallCNIAction
+	// CreateActionInstallStorage installs the default StorageClass
+	CreateActionInstallStorage = internalcreate.InstallStorageAction
+	// CreateActionInstallRegistry publishes the local registry, if configured
+	CreateActionInstallRegistry = internalcreate.InstallRegistryAction
+	// CreateActionKubeadmJoin runs kubeadm join on the remaining nodes
+	CreateActionKubeadmJoin = internalcreate.KubeadmJoinAction
+	// CreateActionWaitForReady waits for the control plane to be Ready
+	CreateActionWaitForReady = internalcreate.WaitForReadyAction
+	// CreateActionRestoreSnapshot restores the nodes from a snapshot, it
+	// replaces CreateActionConfig through CreateActionKubeadmJoin when
+	// using CreateWithSnapshot
+	CreateActionRestoreSnapshot = internalcreate.RestoreSnapshotAction
+)
+
+// CreateAction is a custom step in the Provider.Create pipeline,
+// see CreateWithActionBefore and CreateWithActionAfter
+type CreateAction func(ctx *CreateActionContext) error
+
+// CreateActionContext is the data supplied to a CreateAction
+type CreateActionContext struct {
+	// Logger is the logger used by Provider.Create
+	Logger log.Logger
+	// ClusterName is the name of the cluster being created
+	ClusterName string
+	ctx         *actions.ActionContext
+}
+
+// Nodes returns the list of cluster nodes, this is a cached call
+func (c *CreateActionContext) Nodes() ([]nodes.Node, error) {
+	return c.ctx.Nodes()
+}
+
+// createActionAdapter adapts a CreateAction to an internal actions.Action
+type createActionAdapter CreateAction
+
+func (a createActionAdapter) Execute(ctx *actions.ActionContext) error {
+	return a(&CreateActionContext{
+		Logger:      ctx.Logger,
+		ClusterName: ctx.Config.Name,
+		ctx:         ctx,
+	})
+}
+
+// NodeCommandAction returns a CreateAction that runs command on every node
+// with one of roles (see pkg/cluster/constants), or on every node if roles is
+// empty. The command is run concurrently on all selected nodes.
+func NodeCommandAction(roles []string, command string, args ...string) CreateAction {
+	return func(ctx *CreateActionContext) error {
+		allNodes, err := ctx.Nodes()
+		if err != nil {
+			return err
+		}
+		selected := allNodes
+		if len(roles) > 0 {
+			selected = []nodes.Node{}
+			for _, role := range roles {
+				n, err := nodeutils.SelectNodesByRole(allNodes, role)
+				if err != nil {
+					return err
+				}
+				selected = append(selected, n...)
+			}
+		}
+		fns := []func() error{}
+		for _, node := range selected {
+			node := node // capture loop variable
+			fns = append(fns, func() error {
+				lines, err := exec.CombinedOutputLines(node.Command(command, args...))
+				ctx.Logger.V(3).Info(strings.Join(lines, "\n"))
+				if err != nil {
+					return errors.Wrapf(err, "failed to run %q on node %s", command, node.String())
+				}
+				return nil
+			})
+		}
+		return errors.UntilErrorConcurrent(fns)
+	}
+}
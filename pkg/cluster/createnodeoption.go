@@ -0,0 +1,95 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	internalcreate "sigs.k8s.io/kind/pkg/cluster/internal/create"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+// CreateNodeOption is a Provider.CreateNode option
+type CreateNodeOption interface {
+	apply(*internalcreate.NodeOptions) error
+}
+
+type createNodeOptionAdapter func(*internalcreate.NodeOptions) error
+
+func (c createNodeOptionAdapter) apply(o *internalcreate.NodeOptions) error {
+	return c(o)
+}
+
+// CreateNodeWithRole sets the role of the new node (see pkg/cluster/constants)
+// Currently only worker nodes may be added, which is also the default
+func CreateNodeWithRole(role string) CreateNodeOption {
+	return createNodeOptionAdapter(func(o *internalcreate.NodeOptions) error {
+		o.Role = config.NodeRole(role)
+		return nil
+	})
+}
+
+// CreateNodeWithName sets the name of the new node, by default the next
+// free name for the node's role is used, E.G. kind-worker3
+func CreateNodeWithName(nodeName string) CreateNodeOption {
+	return createNodeOptionAdapter(func(o *internalcreate.NodeOptions) error {
+		o.NodeName = nodeName
+		return nil
+	})
+}
+
+// CreateNodeWithNodeImage sets the image of the new node, by default the
+// image of the cluster's existing nodes is used
+func CreateNodeWithNodeImage(nodeImage string) CreateNodeOption {
+	return createNodeOptionAdapter(func(o *internalcreate.NodeOptions) error {
+		o.Image = nodeImage
+		return nil
+	})
+}
+
+// CreateNodeWithLabels sets the Kubernetes labels of the new node
+func CreateNodeWithLabels(labels map[string]string) CreateNodeOption {
+	return createNodeOptionAdapter(func(o *internalcreate.NodeOptions) error {
+		o.Labels = labels
+		return nil
+	})
+}
+
+// CreateNodeWithIPv4Address sets the static IPv4 address of the new node on
+// the node network, which requires the cluster to have a node subnet
+func CreateNodeWithIPv4Address(address string) CreateNodeOption {
+	return createNodeOptionAdapter(func(o *internalcreate.NodeOptions) error {
+		o.IPv4Address = address
+		return nil
+	})
+}
+
+// CreateNodeWithIPv6Address sets the static IPv6 address of the new node on
+// the node network, which requires the cluster to have a node subnet
+func CreateNodeWithIPv6Address(address string) CreateNodeOption {
+	return createNodeOptionAdapter(func(o *internalcreate.NodeOptions) error {
+		o.IPv6Address = address
+		return nil
+	})
+}
+
+// CreateNodeWithRetain disables deletion of the new node after a failure
+// This is mainly used for debugging purposes
+func CreateNodeWithRetain(retain bool) CreateNodeOption {
+	return createNodeOptionAdapter(func(o *internalcreate.NodeOptions) error {
+		o.Retain = retain
+		return nil
+	})
+}
@@ -21,6 +21,7 @@ import (
 
 	"sigs.k8s.io/kind/pkg/apis/config/v1alpha4"
 	internalcreate "sigs.k8s.io/kind/pkg/cluster/internal/create"
+	"sigs.k8s.io/kind/pkg/errors"
 	internalencoding "sigs.k8s.io/kind/pkg/internal/apis/config/encoding"
 )
 
@@ -70,6 +71,16 @@ func CreateWithNodeImage(nodeImage string) CreateOption {
 	})
 }
 
+// CreateWithSnapshot restores the cluster from the snapshot at path, as
+// written by Provider.Snapshot, instead of setting up a new cluster.
+// The snapshot provides the config and the cluster name must match it.
+func CreateWithSnapshot(path string) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.SnapshotPath = path
+		return nil
+	})
+}
+
 // CreateWithRetain disables deletion of nodes and any other cleanup
 // that would normally occur after a failure to create
 // This is mainly used for debugging purposes
@@ -124,3 +135,49 @@ func CreateWithDisplaySalutation(displaySalutation bool) CreateOption {
 		return nil
 	})
 }
+
+// CreateWithActionBefore runs action immediately before the built-in action
+// named target (one of the CreateAction* constants).
+// The action still runs if target is skipped.
+// Multiple actions for the same target run in the order they were supplied.
+func CreateWithActionBefore(target string, action CreateAction) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		if action == nil {
+			return errors.Errorf("nil create action for %q", target)
+		}
+		o.ActionHooks = append(o.ActionHooks, internalcreate.ActionHook{
+			Target: target,
+			Before: true,
+			Action: createActionAdapter(action),
+		})
+		return nil
+	})
+}
+
+// CreateWithActionAfter runs action immediately after the built-in action
+// named target (one of the CreateAction* constants).
+// The action still runs if target is skipped.
+// Multiple actions for the same target run in the order they were supplied.
+func CreateWithActionAfter(target string, action CreateAction) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		if action == nil {
+			return errors.Errorf("nil create action for %q", target)
+		}
+		o.ActionHooks = append(o.ActionHooks, internalcreate.ActionHook{
+			Target: target,
+			Action: createActionAdapter(action),
+		})
+		return nil
+	})
+}
+
+// CreateWithSkipActions disables the built-in actions named by names
+// (see the CreateAction* constants)
+// This is an advanced option, skipping actions may leave the cluster unusable
+// unless they are replaced with equivalent custom actions.
+func CreateWithSkipActions(names ...string) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.SkipActions = append(o.SkipActions, names...)
+		return nil
+	})
+}
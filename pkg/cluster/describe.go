@@ -0,0 +1,223 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"sigs.k8s.io/kind/pkg/apis/config/v1alpha4"
+	"sigs.k8s.io/kind/pkg/cluster/constants"
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/clusterconfig"
+)
+
+// runningState is the node State of running nodes
+const runningState = "running"
+
+// ClusterDescription describes an existing cluster
+type ClusterDescription struct {
+	Name     string `json:"name"`
+	Provider string `json:"provider"`
+	// KubernetesVersion is the version of the bootstrap control plane node
+	KubernetesVersion string `json:"kubernetesVersion,omitempty"`
+	// CNI is the name of the CNI network configured on the nodes
+	CNI           string               `json:"cni,omitempty"`
+	KubeProxyMode string               `json:"kubeProxyMode,omitempty"`
+	APIServer     APIServerDescription `json:"apiServer"`
+	Network       NetworkDescription   `json:"network"`
+	Nodes         []NodeDescription    `json:"nodes"`
+	// Config is the config of the cluster as persisted on its nodes, or as
+	// far as it can be determined for clusters created by older kind
+	Config *v1alpha4.Cluster `json:"config,omitempty"`
+}
+
+// APIServerDescription describes the endpoints of a cluster's API server
+type APIServerDescription struct {
+	// External is the endpoint on the host
+	External string `json:"external,omitempty"`
+	// Internal is the endpoint on the node network
+	Internal string `json:"internal,omitempty"`
+}
+
+// NetworkDescription describes the network the nodes are attached to
+type NetworkDescription struct {
+	Name    string   `json:"name"`
+	Subnets []string `json:"subnets"`
+}
+
+// NodeDescription describes a node
+type NodeDescription struct {
+	Name  string `json:"name"`
+	Role  string `json:"role"`
+	IPv4  string `json:"ipv4,omitempty"`
+	IPv6  string `json:"ipv6,omitempty"`
+	Image string `json:"image"`
+	State string `json:"state"`
+	// KubernetesVersion is only known for running Kubernetes nodes
+	KubernetesVersion string            `json:"kubernetesVersion,omitempty"`
+	Ports             []PortDescription `json:"ports"`
+}
+
+// PortDescription describes a port published from a node to the host
+type PortDescription struct {
+	ListenAddress string `json:"listenAddress"`
+	HostPort      int32  `json:"hostPort"`
+	ContainerPort int32  `json:"containerPort"`
+	Protocol      string `json:"protocol"`
+}
+
+// Describe returns a description of the cluster name.
+// Details that can only be read from running nodes are omitted when the
+// cluster is stopped.
+func (p *Provider) Describe(name string) (*ClusterDescription, error) {
+	name = defaultName(name)
+	allNodes, err := p.provider.ListNodes(name)
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing nodes")
+	}
+	if len(allNodes) == 0 {
+		return nil, errors.Errorf("unknown cluster %q", name)
+	}
+	sort.Slice(allNodes, func(i, j int) bool {
+		return allNodes[i].String() < allNodes[j].String()
+	})
+
+	desc := &ClusterDescription{
+		Name:     name,
+		Provider: fmt.Sprintf("%s", p.provider),
+		Nodes:    []NodeDescription{},
+	}
+	network, err := p.provider.GetNetwork(name)
+	if err != nil {
+		return nil, err
+	}
+	desc.Network = NetworkDescription{
+		Name:    network.Name,
+		Subnets: network.Subnets,
+	}
+	running := map[string]bool{}
+	for _, node := range allNodes {
+		nodeDesc, err := p.DescribeNode(node)
+		if err != nil {
+			return nil, err
+		}
+		running[node.String()] = nodeDesc.State == runningState
+		desc.Nodes = append(desc.Nodes, *nodeDesc)
+	}
+
+	desc.APIServer.Internal, err = p.provider.GetAPIServerInternalEndpoint(name)
+	if err != nil {
+		return nil, err
+	}
+	bootstrap, err := nodeutils.BootstrapControlPlaneNode(allNodes)
+	if err != nil {
+		return nil, err
+	}
+	if !running[bootstrap.String()] {
+		return desc, nil
+	}
+	// the API server is only published while its node is running
+	if desc.APIServer.External, err = p.provider.GetAPIServerEndpoint(name); err != nil {
+		return nil, err
+	}
+	if desc.KubernetesVersion, err = nodeutils.KubeVersion(bootstrap); err != nil {
+		return nil, err
+	}
+	if desc.CNI, err = cniName(bootstrap); err != nil {
+		return nil, err
+	}
+	cfg, err := clusterconfig.Get(p.provider, name, allNodes)
+	if err != nil {
+		return nil, err
+	}
+	desc.KubeProxyMode = string(cfg.Networking.KubeProxyMode)
+	desc.Config = config.ConvertToV1Alpha4(cfg)
+	return desc, nil
+}
+
+// DescribeNode returns a description of node, which should be from
+// results previously returned by ListNodes
+func (p *Provider) DescribeNode(node nodes.Node) (*NodeDescription, error) {
+	role, err := node.Role()
+	if err != nil {
+		return nil, err
+	}
+	status, err := p.provider.NodeStatus(node)
+	if err != nil {
+		return nil, err
+	}
+	desc := &NodeDescription{
+		Name:  node.String(),
+		Role:  role,
+		Image: status.Image,
+		State: status.State,
+		Ports: []PortDescription{},
+	}
+	for _, port := range status.Ports {
+		desc.Ports = append(desc.Ports, PortDescription{
+			ListenAddress: port.ListenAddress,
+			HostPort:      port.HostPort,
+			ContainerPort: port.ContainerPort,
+			Protocol:      port.Protocol,
+		})
+	}
+	// stopped nodes have no addresses, and nothing can be run in them
+	if status.State != runningState {
+		return desc, nil
+	}
+	if desc.IPv4, desc.IPv6, err = node.IP(); err != nil {
+		return nil, err
+	}
+	if role != constants.ExternalLoadBalancerNodeRoleValue {
+		if desc.KubernetesVersion, err = nodeutils.KubeVersion(node); err != nil {
+			return nil, err
+		}
+	}
+	return desc, nil
+}
+
+// cniName returns the name of the first CNI network configured on node,
+// the same one the container runtime uses, or "" if there is none
+func cniName(node nodes.Node) (string, error) {
+	lines, err := exec.OutputLines(node.Command(
+		"sh", "-c", `ls /etc/cni/net.d/*.conf /etc/cni/net.d/*.conflist 2>/dev/null | sort | head -n 1`,
+	))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to list CNI config")
+	}
+	if len(lines) == 0 || lines[0] == "" {
+		return "", nil
+	}
+	raw, err := exec.Output(node.Command("cat", lines[0]))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to read CNI config")
+	}
+	var cniConfig struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(raw, &cniConfig); err != nil {
+		return "", errors.Wrapf(err, "failed to parse CNI config %s", lines[0])
+	}
+	return cniConfig.Name, nil
+}
@@ -0,0 +1,210 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package clusterconfig implements persisting the config of clusters on
+// their nodes and retrieving it for existing clusters
+package clusterconfig
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/cluster/constants"
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/apis/config/encoding"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/kubeadm"
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers"
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/common"
+)
+
+// Path is where kind writes the effective cluster config on each node
+const Path = "/kind/cluster-config.yaml"
+
+// NodeNamesPath is where kind writes the names of the nodes of the entries
+// of the config at Path on each node, one per line in the same order
+const NodeNamesPath = "/kind/cluster-node-names"
+
+// Write writes the effective config cfg and the names of its nodes to each
+// of n, all nodes of cfg must be named.
+// The names are written after the config they name, Read fails on configs
+// without names.
+func Write(n []nodes.Node, cfg *config.Cluster) error {
+	raw, err := encoding.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+	names, err := EncodeNodeNames(cfg)
+	if err != nil {
+		return err
+	}
+	fns := make([]func() error, len(n))
+	for i := range n {
+		node := n[i] // capture loop variable
+		fns[i] = func() error {
+			if err := nodeutils.WriteFile(node, Path, string(raw)); err != nil {
+				return errors.Wrapf(err, "failed to write cluster config to node %q", node.String())
+			}
+			return errors.Wrapf(
+				nodeutils.WriteFile(node, NodeNamesPath, names),
+				"failed to write node names to node %q", node.String(),
+			)
+		}
+	}
+	return errors.UntilErrorConcurrent(fns)
+}
+
+// Read reads the config persisted on node, or returns nil if there is none
+// because the cluster was created by an older version of kind
+func Read(node nodes.Node) (*config.Cluster, error) {
+	raw, err := readFile(node, Path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read cluster config from node %q", node.String())
+	}
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	cfg, err := encoding.Parse(raw)
+	if err != nil {
+		return nil, err
+	}
+	names, err := readFile(node, NodeNamesPath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read node names from node %q", node.String())
+	}
+	if err := DecodeNodeNames(cfg, string(names)); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// readFile returns the contents of the file at path on node, or nothing if
+// it does not exist
+func readFile(node nodes.Node, path string) ([]byte, error) {
+	var buff bytes.Buffer
+	if err := node.Command(
+		"sh", "-c", fmt.Sprintf("if [ -f %s ]; then cat %s; fi", path, path),
+	).SetStdout(&buff).Run(); err != nil {
+		return nil, err
+	}
+	return buff.Bytes(), nil
+}
+
+// EncodeNodeNames returns the names of the nodes of cfg, one per line
+func EncodeNodeNames(cfg *config.Cluster) (string, error) {
+	var b strings.Builder
+	for i, node := range cfg.Nodes {
+		if node.Name == "" {
+			return "", errors.Errorf("node %d of the cluster config has no name", i)
+		}
+		b.WriteString(node.Name + "\n")
+	}
+	return b.String(), nil
+}
+
+// DecodeNodeNames sets the names of the nodes of cfg from names as encoded
+// by EncodeNodeNames
+func DecodeNodeNames(cfg *config.Cluster, names string) error {
+	lines := strings.Fields(names)
+	if len(lines) == 0 && len(cfg.Nodes) > 0 {
+		return errors.New("no node names found for the nodes of the cluster config")
+	}
+	if len(lines) != len(cfg.Nodes) {
+		return errors.Errorf("found %d node names for the %d nodes of the cluster config", len(lines), len(cfg.Nodes))
+	}
+	for i := range cfg.Nodes {
+		cfg.Nodes[i].Name = lines[i]
+	}
+	return nil
+}
+
+// NameNodes names the unnamed nodes of cfg in the order the provider names
+// the nodes of a new cluster
+func NameNodes(cfg *config.Cluster) {
+	namer := common.MakeNodeNamer(cfg.Name)
+	for i := range cfg.Nodes {
+		if cfg.Nodes[i].Name == "" {
+			cfg.Nodes[i].Name = namer(string(cfg.Nodes[i].Role))
+		}
+	}
+}
+
+// Get returns the config of the existing cluster name with nodes allNodes,
+// as persisted on the bootstrap control plane node, falling back to
+// reconstructing it with FromNodes for clusters without a persisted config
+func Get(p providers.Provider, name string, allNodes []nodes.Node) (*config.Cluster, error) {
+	bootstrap, err := nodeutils.BootstrapControlPlaneNode(allNodes)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := Read(bootstrap)
+	if err != nil {
+		return nil, err
+	}
+	if cfg != nil {
+		return cfg, nil
+	}
+	return FromNodes(p, name, allNodes)
+}
+
+// RemoveNode removes the entry for the node named name from cfg
+func RemoveNode(cfg *config.Cluster, name string) {
+	for i := range cfg.Nodes {
+		if cfg.Nodes[i].Name == name {
+			cfg.Nodes = append(cfg.Nodes[:i], cfg.Nodes[i+1:]...)
+			return
+		}
+	}
+}
+
+// FromNodes reconstructs the config of the existing cluster name from its
+// nodes allNodes: the networking settings from the kubeadm config and the
+// nodes by role, in the order the provider names them
+func FromNodes(p providers.Provider, name string, allNodes []nodes.Node) (*config.Cluster, error) {
+	bootstrap, err := nodeutils.BootstrapControlPlaneNode(allNodes)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := kubeadm.ClusterConfigFromNode(name, bootstrap)
+	if err != nil {
+		return nil, err
+	}
+	for _, role := range []string{
+		constants.ControlPlaneNodeRoleValue,
+		constants.WorkerNodeRoleValue,
+	} {
+		roleNodes, err := nodeutils.SelectNodesByRole(allNodes, role)
+		if err != nil {
+			return nil, err
+		}
+		for _, node := range roleNodes {
+			status, err := p.NodeStatus(node)
+			if err != nil {
+				return nil, err
+			}
+			cfg.Nodes = append(cfg.Nodes, config.Node{
+				Name:  node.String(),
+				Role:  config.NodeRole(role),
+				Image: status.Image,
+			})
+		}
+	}
+	return cfg, nil
+}
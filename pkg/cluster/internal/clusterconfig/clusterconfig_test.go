@@ -0,0 +1,137 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterconfig
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestRemoveNode(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name     string
+		Nodes    []string
+		Expected []string
+	}{
+		{
+			Name:     "first worker",
+			Nodes:    []string{"kind-worker"},
+			Expected: []string{"kind-control-plane", "kind-worker2", "kind-worker3", "custom"},
+		},
+		{
+			Name:     "first then last generated worker",
+			Nodes:    []string{"kind-worker", "kind-worker3"},
+			Expected: []string{"kind-control-plane", "kind-worker2", "custom"},
+		},
+		{
+			Name:     "explicitly named node",
+			Nodes:    []string{"custom"},
+			Expected: []string{"kind-control-plane", "kind-worker", "kind-worker2", "kind-worker3"},
+		},
+		{
+			Name:     "unknown node",
+			Nodes:    []string{"kind-worker4"},
+			Expected: []string{"kind-control-plane", "kind-worker", "kind-worker2", "kind-worker3", "custom"},
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			cfg := &config.Cluster{
+				Name: "kind",
+				Nodes: []config.Node{
+					{Role: config.ControlPlaneRole},
+					{Role: config.WorkerRole},
+					{Role: config.WorkerRole},
+					{Role: config.WorkerRole},
+					{Name: "custom", Role: config.WorkerRole},
+				},
+			}
+			NameNodes(cfg)
+			for _, name := range tc.Nodes {
+				RemoveNode(cfg, name)
+			}
+			names := []string{}
+			for _, n := range cfg.Nodes {
+				names = append(names, n.Name)
+			}
+			assert.DeepEqual(t, tc.Expected, names)
+		})
+	}
+}
+
+func TestNodeNames(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name        string
+		Names       string
+		Expected    []string
+		ExpectError bool
+	}{
+		{
+			Name:     "recorded names",
+			Names:    "kind-control-plane\nkind-worker3\ncustom\n",
+			Expected: []string{"kind-control-plane", "kind-worker3", "custom"},
+		},
+		{
+			Name:        "no recorded names",
+			ExpectError: true,
+		},
+		{
+			Name:        "mismatched names",
+			Names:       "kind-control-plane\nkind-worker\n",
+			ExpectError: true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			cfg := &config.Cluster{
+				Name: "kind",
+				Nodes: []config.Node{
+					{Role: config.ControlPlaneRole},
+					{Role: config.WorkerRole},
+					{Role: config.WorkerRole},
+				},
+			}
+			err := DecodeNodeNames(cfg, tc.Names)
+			assert.ExpectError(t, tc.ExpectError, err)
+			if err != nil {
+				return
+			}
+			names := []string{}
+			for _, n := range cfg.Nodes {
+				names = append(names, n.Name)
+			}
+			assert.DeepEqual(t, tc.Expected, names)
+			encoded, err := EncodeNodeNames(cfg)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			roundTrip := cfg.DeepCopy()
+			if err := DecodeNodeNames(roundTrip, encoded); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			assert.DeepEqual(t, cfg, roundTrip)
+		})
+	}
+}
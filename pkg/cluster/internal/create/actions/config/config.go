@@ -32,6 +32,7 @@ import (
 	"sigs.k8s.io/kind/pkg/cluster/internal/providers/common"
 	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
 	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/containerd"
 	"sigs.k8s.io/kind/pkg/internal/patch"
 )
 
@@ -48,17 +49,12 @@ func (a *Action) Execute(ctx *actions.ActionContext) error {
 	ctx.Status.Start("Writing configuration 📜")
 	defer ctx.Status.End(false)
 
-	providerInfo, err := ctx.Provider.Info()
-	if err != nil {
-		return err
-	}
-
 	allNodes, err := ctx.Nodes()
 	if err != nil {
 		return err
 	}
 
-	controlPlaneEndpoint, err := ctx.Provider.GetAPIServerInternalEndpoint(ctx.Config.Name)
+	configData, err := clusterConfigData(ctx)
 	if err != nil {
 		return err
 	}
@@ -66,28 +62,10 @@ func (a *Action) Execute(ctx *actions.ActionContext) error {
 	// create kubeadm init config
 	fns := []func() error{}
 
-	provider := fmt.Sprintf("%s", ctx.Provider)
-	configData := kubeadm.ConfigData{
-		NodeProvider:         provider,
-		ClusterName:          ctx.Config.Name,
-		ControlPlaneEndpoint: controlPlaneEndpoint,
-		APIBindPort:          common.APIServerInternalPort,
-		APIServerAddress:     ctx.Config.Networking.APIServerAddress,
-		Token:                kubeadm.Token,
-		PodSubnet:            ctx.Config.Networking.PodSubnet,
-		KubeProxyMode:        string(ctx.Config.Networking.KubeProxyMode),
-		ServiceSubnet:        ctx.Config.Networking.ServiceSubnet,
-		ControlPlane:         true,
-		IPFamily:             ctx.Config.Networking.IPFamily,
-		FeatureGates:         ctx.Config.FeatureGates,
-		RuntimeConfig:        ctx.Config.RuntimeConfig,
-		RootlessProvider:     providerInfo.Rootless,
-	}
-
 	kubeadmConfigPlusPatches := func(node nodes.Node, data kubeadm.ConfigData) func() error {
 		return func() error {
 			data.NodeName = node.String()
-			kubeadmConfig, err := getKubeadmConfig(ctx.Config, data, node, provider)
+			kubeadmConfig, err := getKubeadmConfig(ctx.Config, data, node, data.NodeProvider)
 			if err != nil {
 				// TODO(bentheelder): logging here
 				return errors.Wrap(err, "failed to generate kubeadm config content")
@@ -116,30 +94,16 @@ func (a *Action) Execute(ctx *actions.ActionContext) error {
 	}
 
 	// if we have containerd config, patch all the nodes concurrently
-	if len(ctx.Config.ContainerdConfigPatches) > 0 || len(ctx.Config.ContainerdConfigPatchesJSON6902) > 0 {
+	registry, err := registryConfig(ctx.Config)
+	if err != nil {
+		return err
+	}
+	if needsContainerdConfig(ctx.Config, registry) {
 		fns := make([]func() error, len(kubeNodes))
 		for i, node := range kubeNodes {
 			node := node // capture loop variable
 			fns[i] = func() error {
-				// read and patch the config
-				const containerdConfigPath = "/etc/containerd/config.toml"
-				var buff bytes.Buffer
-				if err := node.Command("cat", containerdConfigPath).SetStdout(&buff).Run(); err != nil {
-					return errors.Wrap(err, "failed to read containerd config from node")
-				}
-				patched, err := patch.TOML(buff.String(), ctx.Config.ContainerdConfigPatches, ctx.Config.ContainerdConfigPatchesJSON6902)
-				if err != nil {
-					return errors.Wrap(err, "failed to patch containerd config")
-				}
-				if err := nodeutils.WriteFile(node, containerdConfigPath, patched); err != nil {
-					return errors.Wrap(err, "failed to write patched containerd config")
-				}
-				// restart containerd now that we've re-configured it
-				// skip if containerd is not running
-				if err := node.Command("bash", "-c", `! pgrep --exact containerd || systemctl restart containerd`).Run(); err != nil {
-					return errors.Wrap(err, "failed to restart containerd after patching config")
-				}
-				return nil
+				return patchContainerdConfig(ctx.Config, registry, node)
 			}
 		}
 		if err := errors.UntilErrorConcurrent(fns); err != nil {
@@ -152,6 +116,108 @@ func (a *Action) Execute(ctx *actions.ActionContext) error {
 	return nil
 }
 
+// WriteNodeConfig writes the kubeadm config for a node joining the existing
+// cluster ctx.Config.Name using the bootstrap token, and applies any
+// containerd config patches to it.
+// This is used when adding nodes after the cluster has been created.
+func WriteNodeConfig(ctx *actions.ActionContext, node nodes.Node, token string) error {
+	data, err := clusterConfigData(ctx)
+	if err != nil {
+		return err
+	}
+	data.Token = token
+	data.NodeName = node.String()
+	kubeadmConfig, err := getKubeadmConfig(ctx.Config, data, node, data.NodeProvider)
+	if err != nil {
+		return errors.Wrap(err, "failed to generate kubeadm config content")
+	}
+	ctx.Logger.V(2).Infof("Using the following kubeadm config for node %s:\n%s", node.String(), kubeadmConfig)
+	if err := writeKubeadmConfig(kubeadmConfig, node); err != nil {
+		return err
+	}
+	registry, err := registryConfig(ctx.Config)
+	if err != nil {
+		return err
+	}
+	if needsContainerdConfig(ctx.Config, registry) {
+		return patchContainerdConfig(ctx.Config, registry, node)
+	}
+	return nil
+}
+
+// clusterConfigData returns the kubeadm config data shared by all nodes
+func clusterConfigData(ctx *actions.ActionContext) (kubeadm.ConfigData, error) {
+	providerInfo, err := ctx.Provider.Info()
+	if err != nil {
+		return kubeadm.ConfigData{}, err
+	}
+
+	controlPlaneEndpoint, err := ctx.Provider.GetAPIServerInternalEndpoint(ctx.Config.Name)
+	if err != nil {
+		return kubeadm.ConfigData{}, err
+	}
+
+	return kubeadm.ConfigData{
+		NodeProvider:         fmt.Sprintf("%s", ctx.Provider),
+		ClusterName:          ctx.Config.Name,
+		ControlPlaneEndpoint: controlPlaneEndpoint,
+		APIBindPort:          common.APIServerInternalPort,
+		APIServerAddress:     ctx.Config.Networking.APIServerAddress,
+		Token:                kubeadm.Token,
+		PodSubnet:            ctx.Config.Networking.PodSubnet,
+		KubeProxyMode:        string(ctx.Config.Networking.KubeProxyMode),
+		ServiceSubnet:        ctx.Config.Networking.ServiceSubnet,
+		ControlPlane:         true,
+		IPFamily:             ctx.Config.Networking.IPFamily,
+		FeatureGates:         ctx.Config.FeatureGates,
+		RuntimeConfig:        ctx.Config.RuntimeConfig,
+		RootlessProvider:     providerInfo.Rootless,
+	}, nil
+}
+
+// patchContainerdConfig applies the cluster's containerd config patches
+// and registry config to node and restarts containerd
+func patchContainerdConfig(cfg *config.Cluster, registry *containerd.RegistryConfig, node nodes.Node) error {
+	// read and patch the config
+	const containerdConfigPath = "/etc/containerd/config.toml"
+	var buff bytes.Buffer
+	if err := node.Command("cat", containerdConfigPath).SetStdout(&buff).Run(); err != nil {
+		return errors.Wrap(err, "failed to read containerd config from node")
+	}
+	patched, err := patch.TOML(buff.String(), containerdConfigPatches(cfg, registry), cfg.ContainerdConfigPatchesJSON6902)
+	if err != nil {
+		return errors.Wrap(err, "failed to patch containerd config")
+	}
+	if authPatch := registry.AuthConfigPatch(); authPatch != "" {
+		// containerd replaces whole plugin sections of the config with those
+		// of imported files, so the root only drop-in with the credentials
+		// repeats the patched config
+		withAuth, err := patch.TOML(patched, []string{authPatch}, nil)
+		if err != nil {
+			return errors.Wrap(err, "failed to patch containerd config with registry credentials")
+		}
+		if err := containerd.WritePrivateFile(node, containerd.AuthConfigPath, withAuth); err != nil {
+			return errors.Wrap(err, "failed to write containerd registry credentials config")
+		}
+		patched, err = patch.TOML(patched, []string{fmt.Sprintf("imports = [%q]\n", containerd.AuthConfigPath)}, nil)
+		if err != nil {
+			return errors.Wrap(err, "failed to patch containerd config")
+		}
+	}
+	if err := nodeutils.WriteFile(node, containerdConfigPath, patched); err != nil {
+		return errors.Wrap(err, "failed to write patched containerd config")
+	}
+	if err := registry.Write(node); err != nil {
+		return err
+	}
+	// restart containerd now that we've re-configured it
+	// skip if containerd is not running
+	if err := node.Command("bash", "-c", `! pgrep --exact containerd || systemctl restart containerd`).Run(); err != nil {
+		return errors.Wrap(err, "failed to restart containerd after patching config")
+	}
+	return nil
+}
+
 // getKubeadmConfig generates the kubeadm config contents for the cluster
 // by running data through the template and applying patches as needed.
 func getKubeadmConfig(cfg *config.Cluster, data kubeadm.ConfigData, node nodes.Node, provider string) (path string, err error) {
@@ -171,7 +237,7 @@ func getKubeadmConfig(cfg *config.Cluster, data kubeadm.ConfigData, node nodes.N
 	for i := range cfg.Nodes {
 		n := &cfg.Nodes[i]
 		nodeSuffix := namer(string(n.Role))
-		if strings.HasSuffix(node.String(), nodeSuffix) {
+		if n.Name == node.String() || (n.Name == "" && strings.HasSuffix(node.String(), nodeSuffix)) {
 			configNode = n
 		}
 	}
@@ -0,0 +1,56 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/common"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/containerd"
+)
+
+// registryConfig returns the containerd registry config for cfg, including
+// the local registry, if any
+func registryConfig(cfg *config.Cluster) (*containerd.RegistryConfig, error) {
+	mirrors := cfg.RegistryMirrors
+	if cfg.Registry != nil {
+		// images pushed to localhost:<hostPort> on the host are pulled
+		// from the registry container over the node network
+		mirrors = append([]config.RegistryMirror{{
+			Registry:  fmt.Sprintf("localhost:%d", cfg.Registry.HostPort),
+			Endpoints: []string{fmt.Sprintf("http://%s:%d", cfg.Registry.Name, common.RegistryPort)},
+		}}, mirrors...)
+	}
+	return containerd.NewRegistryConfig(containerd.HostsDir, mirrors, cfg.RegistryAuth)
+}
+
+// containerdConfigPatches returns the containerd config patches for cfg,
+// the registry config patch is applied before the user's patches so that
+// they may override it
+func containerdConfigPatches(cfg *config.Cluster, registry *containerd.RegistryConfig) []string {
+	if registry.Empty() {
+		return cfg.ContainerdConfigPatches
+	}
+	return append([]string{registry.ConfigPatch()}, cfg.ContainerdConfigPatches...)
+}
+
+// needsContainerdConfig returns true if the containerd config on the nodes
+// must be patched for cfg
+func needsContainerdConfig(cfg *config.Cluster, registry *containerd.RegistryConfig) bool {
+	return len(containerdConfigPatches(cfg, registry)) > 0 || len(cfg.ContainerdConfigPatchesJSON6902) > 0
+}
@@ -19,10 +19,13 @@ package installcni
 
 import (
 	"bytes"
+	"encoding/json"
+	"strconv"
 	"strings"
 
 	"github.com/google/safetext/yamltemplate"
 
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
 	"sigs.k8s.io/kind/pkg/errors"
 	"sigs.k8s.io/kind/pkg/internal/apis/config"
 
@@ -110,8 +113,21 @@ func (a *action) Execute(ctx *actions.ActionContext) error {
 			Kind:    "DaemonSet",
 			Patch:   patchValue,
 		}
+		patches := []config.PatchJSON6902{controlPlanePatch6902}
+
+		// pass the kindnet options from the cluster config, if any
+		if kindnet := ctx.Config.Networking.Kindnet; kindnet != nil {
+			if err := checkChainedPlugins(kindnet, allNodes); err != nil {
+				return err
+			}
+			kindnetPatches, err := kindnetEnvPatches(kindnet)
+			if err != nil {
+				return err
+			}
+			patches = append(patches, kindnetPatches...)
+		}
 
-		patchedConfig, err := patch.KubeYAML(manifest, nil, []config.PatchJSON6902{controlPlanePatch6902})
+		patchedConfig, err := patch.KubeYAML(manifest, nil, patches)
 		if err != nil {
 			return err
 		}
@@ -132,3 +148,98 @@ func (a *action) Execute(ctx *actions.ActionContext) error {
 	ctx.Status.End(true)
 	return nil
 }
+
+// checkChainedPlugins checks that the kindnet chained plugins are installed
+// on allNodes, they are called by the container runtime on the nodes
+func checkChainedPlugins(kindnet *config.Kindnet, allNodes []nodes.Node) error {
+	plugins, err := chainedPluginTypes(kindnet)
+	if err != nil {
+		return err
+	}
+	internalNodes, err := nodeutils.InternalNodes(allNodes)
+	if err != nil {
+		return err
+	}
+	for _, node := range internalNodes {
+		for _, plugin := range plugins {
+			if err := node.Command("test", "-x", "/opt/cni/bin/"+plugin).Run(); err != nil {
+				return errors.Errorf("kindnet chained plugin %q is not installed in /opt/cni/bin on node %q, the node image must include it", plugin, node.String())
+			}
+		}
+	}
+	return nil
+}
+
+// chainedPluginTypes returns the plugin types of the kindnet chained plugins
+func chainedPluginTypes(kindnet *config.Kindnet) ([]string, error) {
+	types := []string{}
+	for _, plugin := range kindnet.ChainedPlugins {
+		var parsed struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal([]byte(plugin), &parsed); err != nil {
+			return nil, errors.Wrap(err, "invalid kindnet chained plugin")
+		}
+		types = append(types, parsed.Type)
+	}
+	return types, nil
+}
+
+// kindnetEnvPatches returns the patches adding the environment variables
+// kindnetd reads its options from to the kindnet DaemonSet, if any
+func kindnetEnvPatches(kindnet *config.Kindnet) ([]config.PatchJSON6902, error) {
+	env := [][2]string{}
+	if kindnet.MTU != 0 {
+		env = append(env, [2]string{"KINDNET_MTU", strconv.Itoa(int(kindnet.MTU))})
+	}
+	if len(kindnet.NoMasqueradeCIDRs) > 0 {
+		env = append(env, [2]string{"NO_MASQUERADE_CIDRS", strings.Join(kindnet.NoMasqueradeCIDRs, ",")})
+	}
+	if kindnet.Encapsulation != "" {
+		env = append(env, [2]string{"KINDNET_ENCAPSULATION", string(kindnet.Encapsulation)})
+	}
+	if len(kindnet.ChainedPlugins) > 0 {
+		// the plugins are validated JSON objects
+		plugins := make([]json.RawMessage, len(kindnet.ChainedPlugins))
+		for i, plugin := range kindnet.ChainedPlugins {
+			plugins[i] = json.RawMessage(plugin)
+		}
+		raw, err := json.Marshal(plugins)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to encode kindnet chained plugins")
+		}
+		env = append(env, [2]string{"CNI_CHAINED_PLUGINS", string(raw)})
+	}
+	if len(env) == 0 {
+		return nil, nil
+	}
+
+	type envVar struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	}
+	type operation struct {
+		Op    string `json:"op"`
+		Path  string `json:"path"`
+		Value envVar `json:"value"`
+	}
+	ops := make([]operation, 0, len(env))
+	for _, e := range env {
+		ops = append(ops, operation{
+			Op:    "add",
+			Path:  "/spec/template/spec/containers/0/env/-",
+			Value: envVar{Name: e[0], Value: e[1]},
+		})
+	}
+	// JSON is also valid YAML
+	raw, err := json.Marshal(ops)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode kindnet options patch")
+	}
+	return []config.PatchJSON6902{{
+		Group:   "apps",
+		Version: "v1",
+		Kind:    "DaemonSet",
+		Patch:   string(raw),
+	}}, nil
+}
@@ -18,9 +18,12 @@ limitations under the License.
 package loadbalancer
 
 import (
+	"bytes"
 	"fmt"
+	"time"
 
 	"sigs.k8s.io/kind/pkg/cluster/constants"
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
 	"sigs.k8s.io/kind/pkg/errors"
 	"sigs.k8s.io/kind/pkg/internal/apis/config"
 
@@ -30,6 +33,9 @@ import (
 	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
 )
 
+// caPath is the path to the cluster CA on the control plane nodes
+const caPath = "/etc/kubernetes/pki/ca.crt"
+
 // Action implements and action for configuring and starting the
 // external load balancer in front of the control-plane nodes.
 type Action struct{}
@@ -75,11 +81,17 @@ func (a *Action) Execute(ctx *actions.ActionContext) error {
 	}
 
 	// create loadbalancer config data
-	loadbalancerConfig, err := loadbalancer.Config(&loadbalancer.ConfigData{
+	data := &loadbalancer.ConfigData{
 		ControlPlanePort: common.APIServerInternalPort,
 		BackendServers:   backendServers,
 		IPv6:             ctx.Config.Networking.IPFamily == config.IPv6Family,
-	})
+	}
+	if lb := ctx.Config.LoadBalancer; lb != nil {
+		if err := configureLoadBalancer(data, lb, allNodes, loadBalancerNode); err != nil {
+			return err
+		}
+	}
+	loadbalancerConfig, err := loadbalancer.Config(data)
 	if err != nil {
 		return errors.Wrap(err, "failed to generate loadbalancer config data")
 	}
@@ -98,3 +110,65 @@ func (a *Action) Execute(ctx *actions.ActionContext) error {
 	ctx.Status.End(true)
 	return nil
 }
+
+// configureLoadBalancer sets the fields of data configured by lb.
+// The API servers are only verified once the cluster CA exists, the CA is
+// copied from the bootstrap control plane node to loadBalancerNode.
+func configureLoadBalancer(data *loadbalancer.ConfigData, lb *config.LoadBalancer, allNodes []nodes.Node, loadBalancerNode nodes.Node) error {
+	for _, timeout := range []struct {
+		value string
+		out   *int64
+	}{
+		{lb.Timeouts.Connect, &data.ConnectTimeout},
+		{lb.Timeouts.Client, &data.ClientTimeout},
+		{lb.Timeouts.Server, &data.ServerTimeout},
+		{lb.Timeouts.Check, &data.CheckTimeout},
+		{lb.Timeouts.CheckInterval, &data.CheckInterval},
+	} {
+		if timeout.value == "" {
+			continue
+		}
+		d, err := time.ParseDuration(timeout.value)
+		if err != nil {
+			return errors.Wrapf(err, "invalid loadbalancer timeout %q", timeout.value)
+		}
+		*timeout.out = d.Milliseconds()
+	}
+
+	for _, f := range lb.ExtraFrontends {
+		backends, err := nodeutils.SelectNodesByRole(allNodes, string(f.Backend.NodeRole))
+		if err != nil {
+			return err
+		}
+		frontend := loadbalancer.Frontend{
+			Name:        f.Name,
+			Port:        f.Port,
+			BackendPort: f.Backend.Port,
+		}
+		for _, n := range backends {
+			frontend.BackendServers = append(frontend.BackendServers, n.String())
+		}
+		data.ExtraFrontends = append(data.ExtraFrontends, frontend)
+	}
+
+	if !lb.VerifyAPIServer {
+		return nil
+	}
+	controlPlane, err := nodeutils.BootstrapControlPlaneNode(allNodes)
+	if err != nil {
+		return err
+	}
+	// the CA does not exist before kubeadm init
+	if controlPlane.Command("test", "-f", caPath).Run() != nil {
+		return nil
+	}
+	var ca bytes.Buffer
+	if err := controlPlane.Command("cat", caPath).SetStdout(&ca).Run(); err != nil {
+		return errors.Wrap(err, "failed to read the cluster CA")
+	}
+	if err := nodeutils.WriteFile(loadBalancerNode, loadbalancer.CAPath, ca.String()); err != nil {
+		return errors.Wrap(err, "failed to copy the cluster CA to the loadbalancer")
+	}
+	data.CAFile = loadbalancer.CAPath
+	return nil
+}
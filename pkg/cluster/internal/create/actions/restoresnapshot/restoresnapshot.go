@@ -0,0 +1,87 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package restoresnapshot implements the action for restoring the state
+// of newly provisioned nodes from a cluster snapshot
+package restoresnapshot
+
+import (
+	"sigs.k8s.io/kind/pkg/cluster/constants"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
+	"sigs.k8s.io/kind/pkg/cluster/internal/snapshot"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+// Action implements an action for restoring a cluster snapshot
+type Action struct {
+	snapshot *snapshot.Snapshot
+}
+
+// NewAction returns a new action for restoring the cluster snapshot s
+func NewAction(s *snapshot.Snapshot) actions.Action {
+	return &Action{
+		snapshot: s,
+	}
+}
+
+// Execute runs the action
+func (a *Action) Execute(ctx *actions.ActionContext) error {
+	ctx.Status.Start("Restoring snapshot 📦")
+	defer ctx.Status.End(false)
+
+	allNodes, err := ctx.Nodes()
+	if err != nil {
+		return err
+	}
+	internalNodes, err := nodeutils.InternalNodes(allNodes)
+	if err != nil {
+		return err
+	}
+
+	fns := []func() error{}
+	for _, node := range internalNodes {
+		node := node // capture loop variable
+		fns = append(fns, func() error {
+			return a.snapshot.RestoreNode(node)
+		})
+	}
+	if err := errors.UntilErrorConcurrent(fns); err != nil {
+		return err
+	}
+
+	// restart the nodes so the node entrypoint rewrites the snapshot node
+	// addresses to the current ones and regenerates the API server
+	// certificate, the control plane must come up before the workers
+	if err := ctx.Provider.StopNodes(internalNodes); err != nil {
+		return err
+	}
+	for _, role := range []string{
+		constants.ControlPlaneNodeRoleValue,
+		constants.WorkerNodeRoleValue,
+	} {
+		roleNodes, err := nodeutils.SelectNodesByRole(internalNodes, role)
+		if err != nil {
+			return err
+		}
+		if err := ctx.Provider.StartNodes(roleNodes); err != nil {
+			return err
+		}
+	}
+
+	ctx.Status.End(true)
+	return nil
+}
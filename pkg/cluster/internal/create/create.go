@@ -25,21 +25,17 @@ import (
 
 	"sigs.k8s.io/kind/pkg/cluster/internal/delete"
 	"sigs.k8s.io/kind/pkg/cluster/internal/providers"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
 	"sigs.k8s.io/kind/pkg/errors"
 	"sigs.k8s.io/kind/pkg/internal/apis/config"
 	"sigs.k8s.io/kind/pkg/internal/apis/config/encoding"
 	"sigs.k8s.io/kind/pkg/internal/cli"
 	"sigs.k8s.io/kind/pkg/log"
 
+	"sigs.k8s.io/kind/pkg/cluster/internal/clusterconfig"
 	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
-	configaction "sigs.k8s.io/kind/pkg/cluster/internal/create/actions/config"
-	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/installcni"
-	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/installstorage"
-	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/kubeadminit"
-	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/kubeadmjoin"
-	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/loadbalancer"
-	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/waitforready"
 	"sigs.k8s.io/kind/pkg/cluster/internal/kubeconfig"
+	"sigs.k8s.io/kind/pkg/cluster/internal/snapshot"
 )
 
 const (
@@ -62,6 +58,15 @@ type ClusterOptions struct {
 	// Options to control output
 	DisplayUsage      bool
 	DisplaySalutation bool
+	// ActionHooks are extra actions to run before or after built-in actions
+	ActionHooks []ActionHook
+	// SkipActions are the names of built-in actions that should not be run
+	SkipActions []string
+	// SnapshotPath is the path of a snapshot to restore the cluster from
+	// instead of setting up a new cluster, it replaces Config
+	SnapshotPath string
+
+	snapshot *snapshot.Snapshot
 }
 
 // Cluster creates a cluster
@@ -91,14 +96,32 @@ func Cluster(logger log.Logger, p providers.Provider, opts *ClusterOptions) erro
 		return err
 	}
 
-	// setup a status object to show progress to the user
+	// determine the actions to run, including any user supplied hooks
+	actionsToRun, err := actionsToRun(opts)
+	if err != nil {
+		return err
+	}
+
+	// setup a status object to show progress to the user, and events for
+	// machine readable progress
 	status := cli.StatusForLogger(logger)
+	events := cli.EventsForLogger(logger)
 
 	// we're going to start creating now, tell the user
 	logger.V(0).Infof("Creating cluster %q ...\n", opts.Config.Name)
 
+	// name the nodes up front, so the persisted config records their names
+	clusterconfig.NameNodes(opts.Config)
+
 	// Create node containers implementing defined config Nodes
-	if err := p.Provision(status, opts.Config); err != nil {
+	end := events.Start("provision")
+	err = p.Provision(status, opts.Config)
+	if err == nil {
+		// persist the effective config for operations on the existing cluster
+		err = persistConfig(p, opts.Config)
+	}
+	end(err)
+	if err != nil {
 		// In case of errors nodes are deleted (except if retain is explicitly set)
 		if !opts.Retain {
 			_ = delete.Cluster(logger, p, opts.Config.Name, opts.KubeconfigPath)
@@ -106,33 +129,26 @@ func Cluster(logger log.Logger, p providers.Provider, opts *ClusterOptions) erro
 		return err
 	}
 
-	// TODO(bentheelder): make this controllable from the command line?
-	actionsToRun := []actions.Action{
-		loadbalancer.NewAction(), // setup external loadbalancer
-		configaction.NewAction(), // setup kubeadm config
-	}
-	if !opts.StopBeforeSettingUpKubernetes {
-		actionsToRun = append(actionsToRun,
-			kubeadminit.NewAction(opts.Config), // run kubeadm init
-		)
-		// this step might be skipped, but is next after init
-		if !opts.Config.Networking.DisableDefaultCNI {
-			actionsToRun = append(actionsToRun,
-				installcni.NewAction(), // install CNI
-			)
+	// create or reuse the local registry on the node network
+	if opts.Config.Registry != nil {
+		end := events.Start("registry")
+		err := ensureRegistry(status, p, opts.Config.Name, opts.Config.Registry)
+		end(err)
+		if err != nil {
+			if !opts.Retain {
+				_ = delete.Cluster(logger, p, opts.Config.Name, opts.KubeconfigPath)
+			}
+			return err
 		}
-		// add remaining steps
-		actionsToRun = append(actionsToRun,
-			installstorage.NewAction(),                // install StorageClass
-			kubeadmjoin.NewAction(),                   // run kubeadm join
-			waitforready.NewAction(opts.WaitForReady), // wait for cluster readiness
-		)
 	}
 
 	// run all actions
 	actionsContext := actions.NewActionContext(logger, status, p, opts.Config)
 	for _, action := range actionsToRun {
-		if err := action.Execute(actionsContext); err != nil {
+		end := events.Start(action.name)
+		err := action.action.Execute(actionsContext)
+		end(err)
+		if err != nil {
 			if !opts.Retain {
 				_ = delete.Cluster(logger, p, opts.Config.Name, opts.KubeconfigPath)
 			}
@@ -148,13 +164,14 @@ func Cluster(logger log.Logger, p providers.Provider, opts *ClusterOptions) erro
 	// try exporting kubeconfig with backoff for locking failures
 	// TODO: factor out into a public errors API w/ backoff handling?
 	// for now this is easier than coming up with a good API
-	var err error
+	end = events.Start("kubeconfig")
 	for _, b := range []time.Duration{0, time.Millisecond, time.Millisecond * 50, time.Millisecond * 100} {
 		time.Sleep(b)
 		if err = kubeconfig.Export(p, opts.Config.Name, opts.KubeconfigPath, true); err == nil {
 			break
 		}
 	}
+	end(err)
 	if err != nil {
 		return err
 	}
@@ -219,6 +236,22 @@ func fixupOptions(opts *ClusterOptions) error {
 		opts.Config = cfg
 	}
 
+	// a snapshot provides its own config, and the nodes must keep their names
+	if opts.SnapshotPath != "" {
+		s, err := snapshot.Open(opts.SnapshotPath)
+		if err != nil {
+			return err
+		}
+		if opts.NameOverride != "" && opts.NameOverride != s.Config.Name {
+			return errors.Errorf("snapshot of cluster %q cannot be restored as %q", s.Config.Name, opts.NameOverride)
+		}
+		if opts.NodeImage != "" {
+			return errors.New("cannot override the node image when restoring a snapshot")
+		}
+		opts.snapshot = s
+		opts.Config = s.Config
+	}
+
 	if opts.NameOverride != "" {
 		opts.Config.Name = opts.NameOverride
 	}
@@ -255,3 +288,16 @@ func validateProvider(p providers.Provider) error {
 	}
 	return nil
 }
+
+// persistConfig writes cfg to the internal nodes of the cluster
+func persistConfig(p providers.Provider, cfg *config.Cluster) error {
+	allNodes, err := p.ListNodes(cfg.Name)
+	if err != nil {
+		return err
+	}
+	internalNodes, err := nodeutils.InternalNodes(allNodes)
+	if err != nil {
+		return err
+	}
+	return clusterconfig.Write(internalNodes, cfg)
+}
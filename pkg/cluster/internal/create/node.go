@@ -0,0 +1,239 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package create
+
+import (
+	"net"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/clusterconfig"
+	"sigs.k8s.io/kind/pkg/cluster/internal/delete"
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers"
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/common"
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/cli"
+	"sigs.k8s.io/kind/pkg/internal/sets"
+	"sigs.k8s.io/kind/pkg/log"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
+	configaction "sigs.k8s.io/kind/pkg/cluster/internal/create/actions/config"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/kubeadmjoin"
+)
+
+// NodeOptions holds options for adding a node to an existing cluster
+type NodeOptions struct {
+	ClusterName string
+	// NodeName is the name of the new node, if unset the next free name
+	// for Role is used
+	NodeName string
+	// Role is the role of the new node, only workers are supported currently
+	Role config.NodeRole
+	// Image is the node image, if unset the image the existing nodes were
+	// created or last upgraded with is used
+	Image  string
+	Labels map[string]string
+	Retain bool
+	// IPv4Address and IPv6Address are the static addresses of the new node
+	// on the node network, the cluster must have a node subnet for them
+	IPv4Address string
+	IPv6Address string
+}
+
+// Node provisions a new node and joins it to an existing cluster,
+// returning the name of the new node
+func Node(logger log.Logger, p providers.Provider, opts *NodeOptions) (string, error) {
+	if opts.Role == "" {
+		opts.Role = config.WorkerRole
+	}
+	if opts.Role != config.WorkerRole {
+		return "", errors.Errorf("adding %q nodes is not supported, only %q nodes may be added", opts.Role, config.WorkerRole)
+	}
+
+	allNodes, err := p.ListNodes(opts.ClusterName)
+	if err != nil {
+		return "", err
+	}
+	if len(allNodes) == 0 {
+		return "", errors.Errorf("unknown cluster %q", opts.ClusterName)
+	}
+	controlPlane, err := nodeutils.BootstrapControlPlaneNode(allNodes)
+	if err != nil {
+		return "", err
+	}
+
+	// pick the next free name for the role if not specified
+	name := opts.NodeName
+	existingNames := sets.NewString()
+	for _, n := range allNodes {
+		existingNames.Insert(n.String())
+	}
+	if name == "" {
+		namer := common.MakeNodeNamer(opts.ClusterName)
+		name = namer(string(opts.Role))
+		for existingNames.Has(name) {
+			name = namer(string(opts.Role))
+		}
+	} else if existingNames.Has(name) {
+		return "", errors.Errorf("node %q already exists", name)
+	}
+
+	// the persisted config carries everything new nodes need to match the
+	// existing ones, including registry config and patches
+	clusterCfg, err := clusterconfig.Get(p, opts.ClusterName, allNodes)
+	if err != nil {
+		return "", err
+	}
+	node := config.Node{
+		Name:        name,
+		Role:        opts.Role,
+		Image:       opts.Image,
+		Labels:      opts.Labels,
+		IPv4Address: opts.IPv4Address,
+		IPv6Address: opts.IPv6Address,
+	}
+	if (node.IPv4Address != "" || node.IPv6Address != "") && clusterCfg.Networking.NodeSubnet == "" {
+		return "", errors.Errorf("static node addresses require cluster %q to be created with a node subnet", opts.ClusterName)
+	}
+	if ip := net.ParseIP(node.IPv4Address); node.IPv4Address != "" && (ip == nil || ip.To4() == nil) {
+		return "", errors.Errorf("invalid IPv4 address %q", node.IPv4Address)
+	}
+	if ip := net.ParseIP(node.IPv6Address); node.IPv6Address != "" && (ip == nil || ip.To4() != nil) {
+		return "", errors.Errorf("invalid IPv6 address %q", node.IPv6Address)
+	}
+	if node.IPv4Address != "" || node.IPv6Address != "" {
+		if err := checkStaticAddresses(clusterCfg.Networking.NodeSubnet, node.IPv4Address, node.IPv6Address); err != nil {
+			return "", err
+		}
+	}
+	// the node containers keep their original image when the cluster is
+	// upgraded, the persisted config records the image to upgrade to
+	if node.Image == "" {
+		node.Image = configuredImage(clusterCfg, controlPlane.String())
+	}
+	if node.Image == "" {
+		nodeStatus, err := p.NodeStatus(controlPlane)
+		if err != nil {
+			return "", err
+		}
+		node.Image = nodeStatus.Image
+	}
+	cfg := clusterCfg.DeepCopy()
+	cfg.Nodes = []config.Node{node}
+
+	status := cli.StatusForLogger(logger)
+	logger.V(0).Infof("Adding node %q to cluster %q ...\n", name, opts.ClusterName)
+
+	// cleanup the new node on failure, unless retain is set
+	cleanup := func() {
+		if !opts.Retain {
+			_ = delete.Nodes(p, opts.ClusterName, name)
+		}
+	}
+	if err := p.ProvisionNode(status, cfg, &node, name); err != nil {
+		cleanup()
+		return "", err
+	}
+	if err := joinNode(logger, status, p, cfg, controlPlane, name); err != nil {
+		cleanup()
+		return "", err
+	}
+
+	// record the new node in the persisted config, including on the new node.
+	// the node has joined by now, so do not fail or clean it up over this
+	clusterCfg.Nodes = append(clusterCfg.Nodes, node)
+	if err := persistConfig(p, clusterCfg); err != nil {
+		logger.Warnf("Node %q joined the cluster, but recording it in the cluster config failed: %v", name, err)
+	}
+	return name, nil
+}
+
+// checkStaticAddresses returns an error if one of addresses is in the upper
+// half of nodeSubnet, which the container runtime assigns dynamically
+func checkStaticAddresses(nodeSubnet string, addresses ...string) error {
+	for _, subnet := range strings.Split(nodeSubnet, ",") {
+		ipRange, err := config.DynamicIPRange(subnet)
+		if err != nil {
+			return err
+		}
+		_, cidr, err := net.ParseCIDR(ipRange)
+		if err != nil {
+			return err
+		}
+		for _, address := range addresses {
+			if ip := net.ParseIP(address); ip != nil && cidr.Contains(ip) {
+				return errors.Errorf("static node address %s is in %s, the upper half of the node subnet that is assigned dynamically", address, ipRange)
+			}
+		}
+	}
+	return nil
+}
+
+// configuredImage returns the image of the node name in cfg, if any
+func configuredImage(cfg *config.Cluster, name string) string {
+	for _, n := range cfg.Nodes {
+		if n.Name == name {
+			return n.Image
+		}
+	}
+	return ""
+}
+
+// joinNode writes the kubeadm config to the new node name and joins it
+// to the cluster with a fresh bootstrap token
+func joinNode(logger log.Logger, status *cli.Status, p providers.Provider, cfg *config.Cluster, controlPlane nodes.Node, name string) error {
+	status.Start("Joining node 🚜")
+	defer status.End(false)
+
+	allNodes, err := p.ListNodes(cfg.Name)
+	if err != nil {
+		return err
+	}
+	var node nodes.Node
+	for _, n := range allNodes {
+		if n.String() == name {
+			node = n
+		}
+	}
+	if node == nil {
+		return errors.Errorf("failed to find new node %q", name)
+	}
+
+	// the well known token from cluster creation may have expired
+	lines, err := exec.OutputLines(controlPlane.Command("kubeadm", "token", "create"))
+	if err != nil {
+		return errors.Wrap(err, "failed to create bootstrap token")
+	}
+	if len(lines) == 0 {
+		return errors.New("failed to create bootstrap token: no output")
+	}
+	token := strings.TrimSpace(lines[len(lines)-1])
+
+	ctx := actions.NewActionContext(logger, status, p, cfg)
+	if err := configaction.WriteNodeConfig(ctx, node, token); err != nil {
+		return err
+	}
+	if err := kubeadmjoin.RunKubeadmJoin(logger, node); err != nil {
+		return err
+	}
+
+	status.End(true)
+	return nil
+}
@@ -0,0 +1,179 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package create
+
+import (
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/internal/sets"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
+	configaction "sigs.k8s.io/kind/pkg/cluster/internal/create/actions/config"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/installcni"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/installregistry"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/installstorage"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/kubeadminit"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/kubeadmjoin"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/loadbalancer"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/restoresnapshot"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/waitforready"
+)
+
+// Names of the built-in create actions, in the order they run.
+// ActionHooks and SkipActions refer to actions by these names.
+const (
+	LoadBalancerAction    = "loadbalancer"
+	ConfigAction          = "config"
+	KubeadmInitAction     = "kubeadminit"
+	VerifyAPIServerAction = "verifyapiserver"
+	InstallCNIAction      = "installcni"
+	InstallStorageAction  = "installstorage"
+	InstallRegistryAction = "installregistry"
+	KubeadmJoinAction     = "kubeadmjoin"
+	WaitForReadyAction    = "waitforready"
+	// RestoreSnapshotAction replaces ConfigAction through KubeadmJoinAction
+	// when restoring a snapshot
+	RestoreSnapshotAction = "restoresnapshot"
+)
+
+// builtinActionNames is the set of valid built-in action names
+var builtinActionNames = sets.NewString(
+	LoadBalancerAction,
+	ConfigAction,
+	KubeadmInitAction,
+	VerifyAPIServerAction,
+	InstallCNIAction,
+	InstallStorageAction,
+	InstallRegistryAction,
+	KubeadmJoinAction,
+	WaitForReadyAction,
+	RestoreSnapshotAction,
+)
+
+// ActionHook is an extra action to run before or after a built-in action
+type ActionHook struct {
+	// Target is the name of the built-in action this hook is relative to
+	Target string
+	// Before is true if the hook should run before Target, otherwise it
+	// runs after Target
+	Before bool
+	// Action is the action to run
+	Action actions.Action
+}
+
+// namedAction is a built-in action and its name, a nil action is a
+// placeholder for a skipped built-in so that hooks keep their position
+type namedAction struct {
+	name   string
+	action actions.Action
+}
+
+// builtinActions returns the built-in actions for opts, in order
+func builtinActions(opts *ClusterOptions) []namedAction {
+	if opts.snapshot != nil {
+		return snapshotActions(opts)
+	}
+	builtins := []namedAction{
+		{LoadBalancerAction, loadbalancer.NewAction()}, // setup external loadbalancer
+		{ConfigAction, configaction.NewAction()},       // setup kubeadm config
+	}
+	if opts.StopBeforeSettingUpKubernetes {
+		return builtins
+	}
+	cni := actions.Action(installcni.NewAction()) // install CNI
+	if opts.Config.Networking.DisableDefaultCNI {
+		// this step might be skipped, but is next after init
+		cni = nil
+	}
+	registry := actions.Action(installregistry.NewAction()) // publish local registry
+	if opts.Config.Registry == nil {
+		registry = nil
+	}
+	// reconfigure the external loadbalancer once kubeadm init has created
+	// the cluster CA to verify the API servers against
+	verify := actions.Action(loadbalancer.NewAction())
+	if opts.Config.LoadBalancer == nil || !opts.Config.LoadBalancer.VerifyAPIServer {
+		verify = nil
+	}
+	return append(builtins,
+		namedAction{KubeadmInitAction, kubeadminit.NewAction(opts.Config)},         // run kubeadm init
+		namedAction{VerifyAPIServerAction, verify},                                 // verify API servers in loadbalancer
+		namedAction{InstallCNIAction, cni},                                         // install CNI
+		namedAction{InstallStorageAction, installstorage.NewAction()},              // install StorageClass
+		namedAction{InstallRegistryAction, registry},                               // publish local registry
+		namedAction{KubeadmJoinAction, kubeadmjoin.NewAction()},                    // run kubeadm join
+		namedAction{WaitForReadyAction, waitforready.NewAction(opts.WaitForReady)}, // wait for cluster readiness
+	)
+}
+
+// snapshotActions returns the built-in actions for restoring opts.snapshot,
+// the nodes already have Kubernetes configured and only need to be
+// reconnected through the load balancer
+func snapshotActions(opts *ClusterOptions) []namedAction {
+	builtins := []namedAction{
+		{RestoreSnapshotAction, restoresnapshot.NewAction(opts.snapshot)}, // restore node state
+	}
+	if opts.StopBeforeSettingUpKubernetes {
+		return builtins
+	}
+	return append(builtins,
+		namedAction{LoadBalancerAction, loadbalancer.NewAction()},                  // setup external loadbalancer
+		namedAction{WaitForReadyAction, waitforready.NewAction(opts.WaitForReady)}, // wait for cluster readiness
+	)
+}
+
+// actionsToRun returns the ordered list of actions to run for opts,
+// combining the built-in actions with opts.SkipActions and opts.ActionHooks.
+// Hooks are named for their position, e.g. "before-kubeadminit".
+//
+// Hooks are run in the order they were supplied, relative to their target.
+// Hooks still run when their target is skipped, but hooks targeting actions
+// after kubeadm init are dropped along with them when
+// StopBeforeSettingUpKubernetes is set.
+func actionsToRun(opts *ClusterOptions) ([]namedAction, error) {
+	for _, name := range opts.SkipActions {
+		if !builtinActionNames.Has(name) {
+			return nil, errors.Errorf("cannot skip unknown create action %q", name)
+		}
+	}
+	for _, hook := range opts.ActionHooks {
+		if !builtinActionNames.Has(hook.Target) {
+			return nil, errors.Errorf("cannot hook unknown create action %q", hook.Target)
+		}
+		if hook.Action == nil {
+			return nil, errors.Errorf("create action hook for %q has no action", hook.Target)
+		}
+	}
+	skip := sets.NewString(opts.SkipActions...)
+
+	out := []namedAction{}
+	for _, builtin := range builtinActions(opts) {
+		for _, hook := range opts.ActionHooks {
+			if hook.Target == builtin.name && hook.Before {
+				out = append(out, namedAction{"before-" + builtin.name, hook.Action})
+			}
+		}
+		if builtin.action != nil && !skip.Has(builtin.name) {
+			out = append(out, builtin)
+		}
+		for _, hook := range opts.ActionHooks {
+			if hook.Target == builtin.name && !hook.Before {
+				out = append(out, namedAction{"after-" + builtin.name, hook.Action})
+			}
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,192 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package create
+
+import (
+	"fmt"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+type fakeAction string
+
+func (f fakeAction) Execute(*actions.ActionContext) error {
+	return nil
+}
+
+// actionNames identifies actions by their fake name, or package for built-ins
+func actionNames(in []namedAction) []string {
+	out := []string{}
+	for _, a := range in {
+		if f, ok := a.action.(fakeAction); ok {
+			out = append(out, string(f))
+		} else {
+			out = append(out, fmt.Sprintf("%T", a.action))
+		}
+	}
+	return out
+}
+
+func TestActionsToRun(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name        string
+		Options     ClusterOptions
+		Expected    []string
+		ExpectError bool
+		DisableCNI  bool
+		StopEarly   bool
+		Registry    bool
+		VerifyLB    bool
+	}{
+		{
+			Name: "default actions",
+			Expected: []string{
+				"*loadbalancer.Action",
+				"*config.Action",
+				"*kubeadminit.action",
+				"*installcni.action",
+				"*installstorage.action",
+				"*kubeadmjoin.Action",
+				"*waitforready.Action",
+			},
+		},
+		{
+			Name: "hooks around init and skipped cni",
+			Options: ClusterOptions{
+				SkipActions: []string{InstallCNIAction},
+				ActionHooks: []ActionHook{
+					{Target: InstallCNIAction, Action: fakeAction("my-cni")},
+					{Target: KubeadmInitAction, Before: true, Action: fakeAction("certs")},
+					{Target: InstallCNIAction, Action: fakeAction("my-cni-wait")},
+				},
+			},
+			Expected: []string{
+				"*loadbalancer.Action",
+				"*config.Action",
+				"certs",
+				"*kubeadminit.action",
+				"my-cni",
+				"my-cni-wait",
+				"*installstorage.action",
+				"*kubeadmjoin.Action",
+				"*waitforready.Action",
+			},
+		},
+		{
+			Name:       "hook on disabled default cni",
+			DisableCNI: true,
+			Options: ClusterOptions{
+				ActionHooks: []ActionHook{
+					{Target: InstallCNIAction, Before: true, Action: fakeAction("my-cni")},
+				},
+			},
+			Expected: []string{
+				"*loadbalancer.Action",
+				"*config.Action",
+				"*kubeadminit.action",
+				"my-cni",
+				"*installstorage.action",
+				"*kubeadmjoin.Action",
+				"*waitforready.Action",
+			},
+		},
+		{
+			Name:      "stop before setting up kubernetes",
+			StopEarly: true,
+			Options: ClusterOptions{
+				ActionHooks: []ActionHook{
+					{Target: ConfigAction, Action: fakeAction("after-config")},
+					{Target: KubeadmJoinAction, Action: fakeAction("after-join")},
+				},
+			},
+			Expected: []string{
+				"*loadbalancer.Action",
+				"*config.Action",
+				"after-config",
+			},
+		},
+		{
+			Name:     "local registry",
+			Registry: true,
+			Expected: []string{
+				"*loadbalancer.Action",
+				"*config.Action",
+				"*kubeadminit.action",
+				"*installcni.action",
+				"*installstorage.action",
+				"*installregistry.action",
+				"*kubeadmjoin.Action",
+				"*waitforready.Action",
+			},
+		},
+		{
+			Name:     "verified load balancer",
+			VerifyLB: true,
+			Expected: []string{
+				"*loadbalancer.Action",
+				"*config.Action",
+				"*kubeadminit.action",
+				"*loadbalancer.Action",
+				"*installcni.action",
+				"*installstorage.action",
+				"*kubeadmjoin.Action",
+				"*waitforready.Action",
+			},
+		},
+		{
+			Name: "unknown skip",
+			Options: ClusterOptions{
+				SkipActions: []string{"bogus"},
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "unknown hook target",
+			Options: ClusterOptions{
+				ActionHooks: []ActionHook{
+					{Target: "bogus", Action: fakeAction("foo")},
+				},
+			},
+			ExpectError: true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			opts := tc.Options
+			opts.Config = &config.Cluster{}
+			opts.Config.Networking.DisableDefaultCNI = tc.DisableCNI
+			opts.StopBeforeSettingUpKubernetes = tc.StopEarly
+			if tc.Registry {
+				opts.Config.Registry = &config.Registry{}
+			}
+			if tc.VerifyLB {
+				opts.Config.LoadBalancer = &config.LoadBalancer{VerifyAPIServer: true}
+			}
+			result, err := actionsToRun(&opts)
+			assert.ExpectError(t, tc.ExpectError, err)
+			if err == nil {
+				assert.DeepEqual(t, tc.Expected, actionNames(result))
+			}
+		})
+	}
+}
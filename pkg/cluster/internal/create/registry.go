@@ -0,0 +1,50 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package create
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/cli"
+	"sigs.k8s.io/kind/pkg/log"
+)
+
+// Registry creates a shared local registry on the node network, or reuses
+// an existing container with the same name. Clusters configured with a
+// registry of the same name will use it.
+func Registry(logger log.Logger, p providers.Provider, registry *config.Registry) error {
+	registry = registry.DeepCopy()
+	config.SetDefaultsRegistry(registry)
+	if err := registry.Validate(); err != nil {
+		return err
+	}
+	// registries without a cluster are always shared
+	registry.Shared = true
+	if err := ensureRegistry(cli.StatusForLogger(logger), p, "", registry); err != nil {
+		return err
+	}
+	logger.V(0).Infof("Registry %q is available at localhost:%d", registry.Name, registry.HostPort)
+	return nil
+}
+
+func ensureRegistry(status *cli.Status, p providers.Provider, cluster string, registry *config.Registry) (err error) {
+	status.Start(fmt.Sprintf("Ensuring registry (%s) 🗄", registry.Name))
+	defer func() { status.End(err == nil) }()
+	return p.EnsureRegistry(cluster, registry)
+}
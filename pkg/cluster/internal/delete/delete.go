@@ -18,6 +18,7 @@ package delete
 
 import (
 	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/internal/cli"
 	"sigs.k8s.io/kind/pkg/log"
 
 	"sigs.k8s.io/kind/pkg/cluster/internal/kubeconfig"
@@ -33,19 +34,55 @@ func Cluster(logger log.Logger, p providers.Provider, name, explicitKubeconfigPa
 		return errors.Wrap(err, "error listing nodes")
 	}
 
+	events := cli.EventsForLogger(logger)
+
+	end := events.Start("kubeconfig")
 	kerr := kubeconfig.Remove(name, explicitKubeconfigPath)
+	end(kerr)
 	if kerr != nil {
 		logger.Errorf("failed to update kubeconfig: %v", kerr)
 	}
 
 	if len(n) > 0 {
+		end := events.Start("nodes")
 		err = p.DeleteNodes(n)
+		end(err)
 		if err != nil {
 			return err
 		}
 		logger.V(0).Infof("Deleted nodes: %q", n)
 	}
 
+	// helpers such as the Service load balancers are owned by the cluster
+	helpers, err := p.ListHelpers(name, "", "")
+	if err != nil {
+		return errors.Wrap(err, "error listing helper containers")
+	}
+	if len(helpers) > 0 {
+		end := events.Start("helpers")
+		err = p.DeleteNodes(helpers)
+		end(err)
+		if err != nil {
+			return err
+		}
+	}
+
+	// shared registries are left for other clusters
+	end = events.Start("registry")
+	err = p.DeleteRegistry(name)
+	end(err)
+	if err != nil {
+		return err
+	}
+
+	// networks still used by other clusters are left in place
+	end = events.Start("networks")
+	err = p.DeleteNetworks(name)
+	end(err)
+	if err != nil {
+		return err
+	}
+
 	if kerr != nil {
 		return kerr
 	}
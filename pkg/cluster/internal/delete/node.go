@@ -0,0 +1,137 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package delete
+
+import (
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/cluster/constants"
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+	"sigs.k8s.io/kind/pkg/log"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/clusterconfig"
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers"
+)
+
+// Node drains and resets the worker node name and removes it from the
+// cluster before deleting the node container
+func Node(logger log.Logger, p providers.Provider, cluster, name string) error {
+	allNodes, err := p.ListNodes(cluster)
+	if err != nil {
+		return errors.Wrap(err, "error listing nodes")
+	}
+	var node nodes.Node
+	for _, n := range allNodes {
+		if n.String() == name {
+			node = n
+		}
+	}
+	if node == nil {
+		return errors.Errorf("unknown node %q in cluster %q", name, cluster)
+	}
+	role, err := node.Role()
+	if err != nil {
+		return err
+	}
+	if role != constants.WorkerNodeRoleValue {
+		return errors.Errorf("deleting %q nodes is not supported, only %q nodes may be deleted", role, constants.WorkerNodeRoleValue)
+	}
+	controlPlane, err := nodeutils.BootstrapControlPlaneNode(allNodes)
+	if err != nil {
+		return err
+	}
+
+	// these are best effort, the node may already be broken
+	logger.V(0).Infof("Draining node %q ...", name)
+	if err := kubectl(logger, controlPlane,
+		"drain", name, "--ignore-daemonsets", "--delete-emptydir-data", "--force", "--timeout=2m",
+	); err != nil {
+		logger.Warnf("failed to drain node %q: %v", name, err)
+	}
+	lines, err := exec.CombinedOutputLines(node.Command("kubeadm", "reset", "--force"))
+	logger.V(3).Info(strings.Join(lines, "\n"))
+	if err != nil {
+		logger.Warnf("failed to reset node %q: %v", name, err)
+	}
+
+	if err := kubectl(logger, controlPlane, "delete", "node", name, "--ignore-not-found"); err != nil {
+		return errors.Wrapf(err, "failed to remove node %q from the cluster", name)
+	}
+	if err := p.DeleteNodes([]nodes.Node{node}); err != nil {
+		return err
+	}
+	// clusters created by older versions of kind have no persisted config
+	if err := removeFromConfig(allNodes, name); err != nil {
+		logger.Warnf("failed to remove node %q from the cluster config: %v", name, err)
+	}
+	logger.V(0).Infof("Deleted node: %q", name)
+	return nil
+}
+
+// Nodes deletes the node containers named names in cluster without
+// otherwise removing them from the cluster, unknown names are ignored
+func Nodes(p providers.Provider, cluster string, names ...string) error {
+	allNodes, err := p.ListNodes(cluster)
+	if err != nil {
+		return errors.Wrap(err, "error listing nodes")
+	}
+	toDelete := []nodes.Node{}
+	for _, n := range allNodes {
+		for _, name := range names {
+			if n.String() == name {
+				toDelete = append(toDelete, n)
+			}
+		}
+	}
+	return p.DeleteNodes(toDelete)
+}
+
+// removeFromConfig removes the node name from the config persisted on the
+// remaining internal nodes of allNodes, if any
+func removeFromConfig(allNodes []nodes.Node, name string) error {
+	remaining := []nodes.Node{}
+	for _, n := range allNodes {
+		if n.String() != name {
+			remaining = append(remaining, n)
+		}
+	}
+	internalNodes, err := nodeutils.InternalNodes(remaining)
+	if err != nil {
+		return err
+	}
+	bootstrap, err := nodeutils.BootstrapControlPlaneNode(internalNodes)
+	if err != nil {
+		return err
+	}
+	cfg, err := clusterconfig.Read(bootstrap)
+	if err != nil || cfg == nil {
+		return err
+	}
+	clusterconfig.RemoveNode(cfg, name)
+	return clusterconfig.Write(internalNodes, cfg)
+}
+
+func kubectl(logger log.Logger, controlPlane nodes.Node, args ...string) error {
+	lines, err := exec.CombinedOutputLines(controlPlane.Command(
+		"kubectl", append([]string{"--kubeconfig=/etc/kubernetes/admin.conf"}, args...)...,
+	))
+	logger.V(3).Info(strings.Join(lines, "\n"))
+	return err
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeadm
+
+import (
+	"bytes"
+	"net"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+// ConfigPath is where kind writes the kubeadm config on each node
+const ConfigPath = "/kind/kubeadm.conf"
+
+// ClusterConfigFromNode reconstructs the cluster wide networking settings
+// of the existing cluster name from the kubeadm config written to node
+// when the cluster was created
+func ClusterConfigFromNode(name string, node nodes.Node) (*config.Cluster, error) {
+	var buff bytes.Buffer
+	if err := node.Command("cat", ConfigPath).SetStdout(&buff).Run(); err != nil {
+		return nil, errors.Wrap(err, "failed to read kubeadm config from node")
+	}
+	return clusterConfigFromKubeadmConfig(name, buff.String())
+}
+
+// clusterConfigFromKubeadmConfig implements ClusterConfigFromNode for the
+// kubeadm config contents raw
+func clusterConfigFromKubeadmConfig(name, raw string) (*config.Cluster, error) {
+	cfg := &config.Cluster{Name: name}
+	// kube-proxy is only configured if it is enabled
+	cfg.Networking.KubeProxyMode = config.NoneProxyMode
+	for _, doc := range strings.Split(raw, "\n---") {
+		var kubeadmConfig struct {
+			Kind       string `json:"kind"`
+			Networking struct {
+				PodSubnet     string `json:"podSubnet"`
+				ServiceSubnet string `json:"serviceSubnet"`
+			} `json:"networking"`
+			// Mode is only set on the KubeProxyConfiguration
+			Mode string `json:"mode"`
+		}
+		if err := yaml.Unmarshal([]byte(doc), &kubeadmConfig); err != nil {
+			return nil, errors.Wrap(err, "failed to parse kubeadm config from node")
+		}
+		switch kubeadmConfig.Kind {
+		case "ClusterConfiguration":
+			cfg.Networking.PodSubnet = kubeadmConfig.Networking.PodSubnet
+			cfg.Networking.ServiceSubnet = kubeadmConfig.Networking.ServiceSubnet
+		case "KubeProxyConfiguration":
+			cfg.Networking.KubeProxyMode = config.ProxyMode(kubeadmConfig.Mode)
+		}
+	}
+	cfg.Networking.IPFamily = ipFamilyForSubnets(cfg.Networking.ServiceSubnet)
+	return cfg, nil
+}
+
+// ipFamilyForSubnets returns the cluster IP family for a comma separated
+// list of subnets, as used in the kubeadm networking config
+func ipFamilyForSubnets(subnets string) config.ClusterIPFamily {
+	parts := strings.Split(subnets, ",")
+	if len(parts) > 1 {
+		return config.DualStackFamily
+	}
+	if ip, _, err := net.ParseCIDR(parts[0]); err == nil && ip.To4() == nil {
+		return config.IPv6Family
+	}
+	return config.IPv4Family
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeadm
+
+import (
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestIPFamilyForSubnets(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Subnets  string
+		Expected config.ClusterIPFamily
+	}{
+		{Subnets: "10.96.0.0/16", Expected: config.IPv4Family},
+		{Subnets: "fd00:10:96::/112", Expected: config.IPv6Family},
+		{Subnets: "10.96.0.0/16,fd00:10:96::/112", Expected: config.DualStackFamily},
+		{Subnets: "", Expected: config.IPv4Family},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Subnets, func(t *testing.T) {
+			t.Parallel()
+			assert.StringEqual(t, string(tc.Expected), string(ipFamilyForSubnets(tc.Subnets)))
+		})
+	}
+}
+
+func TestClusterConfigFromKubeadmConfig(t *testing.T) {
+	t.Parallel()
+	raw := `apiVersion: kubeadm.k8s.io/v1beta3
+kind: ClusterConfiguration
+networking:
+  podSubnet: 10.244.0.0/16
+  serviceSubnet: 10.96.0.0/16
+---
+apiVersion: kubeadm.k8s.io/v1beta3
+kind: InitConfiguration
+---
+apiVersion: kubeproxy.config.k8s.io/v1alpha1
+kind: KubeProxyConfiguration
+mode: "ipvs"
+`
+	cfg, err := clusterConfigFromKubeadmConfig("kind", raw)
+	assert.ExpectError(t, false, err)
+	assert.StringEqual(t, "10.244.0.0/16", cfg.Networking.PodSubnet)
+	assert.StringEqual(t, "10.96.0.0/16", cfg.Networking.ServiceSubnet)
+	assert.StringEqual(t, string(config.IPVSProxyMode), string(cfg.Networking.KubeProxyMode))
+
+	// kube-proxy is not configured when disabled
+	cfg, err = clusterConfigFromKubeadmConfig("kind", raw[:strings.Index(raw, "---")])
+	assert.ExpectError(t, false, err)
+	assert.StringEqual(t, string(config.NoneProxyMode), string(cfg.Networking.KubeProxyMode))
+}
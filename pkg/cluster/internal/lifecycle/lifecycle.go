@@ -0,0 +1,169 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package lifecycle implements stopping, starting, pausing and unpausing
+// the nodes of existing clusters
+package lifecycle
+
+import (
+	"time"
+
+	"sigs.k8s.io/kind/pkg/cluster/constants"
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/internal/cli"
+	"sigs.k8s.io/kind/pkg/log"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/clusterconfig"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/loadbalancer"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/waitforready"
+	"sigs.k8s.io/kind/pkg/cluster/internal/kubeconfig"
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers"
+)
+
+// StartOptions holds options for starting a stopped cluster
+type StartOptions struct {
+	// WaitForReady is the maximum time to wait for the control plane to be
+	// ready after starting, no waiting is performed if zero
+	WaitForReady   time.Duration
+	KubeconfigPath string
+}
+
+// Start starts the nodes of the stopped cluster name in a safe order:
+// the external load balancer first, then control plane nodes, then workers.
+// The load balancer is then reconfigured in case the node addresses changed
+// and the kubeconfig is re-exported.
+func Start(logger log.Logger, p providers.Provider, name string, opts *StartOptions) error {
+	groups, err := nodeGroups(p, name)
+	if err != nil {
+		return err
+	}
+	status := cli.StatusForLogger(logger)
+	logger.V(0).Infof("Starting cluster %q ...\n", name)
+
+	status.Start("Starting nodes 🔌")
+	for _, group := range groups {
+		if err := p.StartNodes(group); err != nil {
+			status.End(false)
+			return err
+		}
+	}
+	status.End(true)
+
+	// the cluster config records the networking needed to reconfigure
+	// the load balancer for the current node addresses
+	allNodes, err := p.ListNodes(name)
+	if err != nil {
+		return err
+	}
+	cfg, err := clusterconfig.Get(p, name, allNodes)
+	if err != nil {
+		return err
+	}
+	ctx := actions.NewActionContext(logger, status, p, cfg)
+	for _, action := range []actions.Action{
+		loadbalancer.NewAction(),
+		waitforready.NewAction(opts.WaitForReady),
+	} {
+		if err := action.Execute(ctx); err != nil {
+			return err
+		}
+	}
+
+	// the API server endpoint on the host may have changed
+	return kubeconfig.Export(p, name, opts.KubeconfigPath, true)
+}
+
+// Stop stops the nodes of the cluster name in the reverse order of Start
+func Stop(logger log.Logger, p providers.Provider, name string) error {
+	groups, err := nodeGroups(p, name)
+	if err != nil {
+		return err
+	}
+	status := cli.StatusForLogger(logger)
+	logger.V(0).Infof("Stopping cluster %q ...\n", name)
+
+	status.Start("Stopping nodes 🛑")
+	for i := len(groups) - 1; i >= 0; i-- {
+		if err := p.StopNodes(groups[i]); err != nil {
+			status.End(false)
+			return err
+		}
+	}
+	status.End(true)
+	return nil
+}
+
+// Pause freezes all the nodes of the cluster name
+func Pause(logger log.Logger, p providers.Provider, name string) error {
+	allNodes, err := listNodes(p, name)
+	if err != nil {
+		return err
+	}
+	if err := p.PauseNodes(allNodes); err != nil {
+		return err
+	}
+	logger.V(0).Infof("Paused nodes: %q", allNodes)
+	return nil
+}
+
+// Unpause resumes all the nodes of the paused cluster name
+func Unpause(logger log.Logger, p providers.Provider, name string) error {
+	allNodes, err := listNodes(p, name)
+	if err != nil {
+		return err
+	}
+	if err := p.UnpauseNodes(allNodes); err != nil {
+		return err
+	}
+	logger.V(0).Infof("Unpaused nodes: %q", allNodes)
+	return nil
+}
+
+// nodeGroups returns the nodes of cluster name in start order:
+// the external load balancer, control plane nodes, then worker nodes
+func nodeGroups(p providers.Provider, name string) ([][]nodes.Node, error) {
+	allNodes, err := listNodes(p, name)
+	if err != nil {
+		return nil, err
+	}
+	groups := [][]nodes.Node{}
+	for _, role := range []string{
+		constants.ExternalLoadBalancerNodeRoleValue,
+		constants.ControlPlaneNodeRoleValue,
+		constants.WorkerNodeRoleValue,
+	} {
+		n, err := nodeutils.SelectNodesByRole(allNodes, role)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, n)
+	}
+	return groups, nil
+}
+
+func listNodes(p providers.Provider, name string) ([]nodes.Node, error) {
+	allNodes, err := p.ListNodes(name)
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing nodes")
+	}
+	if len(allNodes) == 0 {
+		return nil, errors.Errorf("unknown cluster %q", name)
+	}
+	return allNodes, nil
+}
@@ -28,6 +28,29 @@ type ConfigData struct {
 	ControlPlanePort int
 	BackendServers   map[string]string
 	IPv6             bool
+	// The timeouts are in milliseconds, zero keeps the default
+	ConnectTimeout int64
+	ClientTimeout  int64
+	ServerTimeout  int64
+	CheckTimeout   int64
+	CheckInterval  int64
+	// CAFile is the path to the CA the API servers are verified against,
+	// they are not verified if unset
+	CAFile string
+	// ExtraFrontends are additional ports of the loadbalancer
+	ExtraFrontends []Frontend
+}
+
+// Frontend is an additional port of the loadbalancer
+type Frontend struct {
+	// Name is the name of the frontend, its backend is <Name>-nodes
+	Name string
+	// Port is the port the loadbalancer listens on
+	Port int32
+	// BackendPort is the port of the backend servers
+	BackendPort int32
+	// BackendServers are the names of the nodes to balance to
+	BackendServers []string
 }
 
 // DefaultConfigTemplate is the loadbalancer config template
@@ -46,12 +69,14 @@ defaults
   log global
   mode tcp
   option dontlognull
-  # TODO: tune these
-  timeout connect 5000
-  timeout client 50000
-  timeout server 50000
+  timeout connect {{ or .ConnectTimeout 5000 }}
+  timeout client {{ or .ClientTimeout 50000 }}
+  timeout server {{ or .ServerTimeout 50000 }}
+  {{- if .CheckTimeout }}
+  timeout check {{ .CheckTimeout }}
+  {{- end }}
   # allow to boot despite dns don't resolve backends
-  default-server init-addr none
+  default-server init-addr none {{- if .CheckInterval }} inter {{ .CheckInterval }} {{- end }}
 
 frontend control-plane
   bind *:{{ .ControlPlanePort }}
@@ -62,16 +87,102 @@ frontend control-plane
 
 backend kube-apiservers
   option httpchk GET /healthz
-  # TODO: we should be verifying (!)
+  {{- if not .CAFile }}
+  # the API servers are not verified, see loadBalancer.verifyAPIServer
+  {{- end }}
   {{range $server, $address := .BackendServers}}
-  server {{ $server }} {{ $address }} check check-ssl verify none resolvers docker resolve-prefer {{ if $.IPv6 -}} ipv6 {{- else -}} ipv4 {{- end }}
+  server {{ $server }} {{ $address }} check check-ssl {{ if $.CAFile -}} verify required ca-file {{ $.CAFile }} verifyhost kubernetes {{- else -}} verify none {{- end }} resolvers docker resolve-prefer {{ if $.IPv6 -}} ipv6 {{- else -}} ipv4 {{- end }}
   {{- end}}
+{{ range $frontend := .ExtraFrontends }}
+frontend {{ $frontend.Name }}
+  bind *:{{ $frontend.Port }}
+  {{ if $.IPv6 -}}
+  bind :::{{ $frontend.Port }}
+  {{- end }}
+  default_backend {{ $frontend.Name }}-nodes
+
+backend {{ $frontend.Name }}-nodes
+  {{- range $server := $frontend.BackendServers }}
+  server {{ $server }} {{ $server }}:{{ $frontend.BackendPort }} check resolvers docker resolve-prefer {{ if $.IPv6 -}} ipv6 {{- else -}} ipv4 {{- end }}
+  {{- end }}
+{{ end -}}
+`
+
+// ServiceConfigData is supplied to the Service load balancer config template
+type ServiceConfigData struct {
+	// Ports are the ports of the Service
+	Ports []ServicePort
+	// BackendServers are the names of the nodes to balance to
+	BackendServers []string
+	IPv6           bool
+}
+
+// ServicePort is a port of a Service of type LoadBalancer
+type ServicePort struct {
+	// Port is the port of the Service, the load balancer listens on it
+	Port int32
+	// NodePort is the port of the Service on the nodes
+	NodePort int32
+	// HealthCheckNodePort is the port on the nodes serving the health of
+	// the local endpoints of Services with the Local traffic policy, if any
+	HealthCheckNodePort int32
+}
+
+// ServiceConfigTemplate is the Service load balancer config template
+const ServiceConfigTemplate = `# generated by kind
+global
+  log /dev/log local0
+  log /dev/log local1 notice
+  daemon
+  # limit memory usage to approximately 18 MB
+  maxconn 100000
+
+resolvers docker
+  nameserver dns 127.0.0.11:53
+
+defaults
+  log global
+  mode tcp
+  option dontlognull
+  timeout connect 5000
+  timeout client 50000
+  timeout server 50000
+  # allow to boot despite dns don't resolve backends
+  default-server init-addr none
+{{ range $port := .Ports }}
+frontend service-{{ $port.Port }}
+  bind *:{{ $port.Port }}
+  {{ if $.IPv6 -}}
+  bind :::{{ $port.Port }}
+  {{- end }}
+  default_backend nodes-{{ $port.Port }}
+
+backend nodes-{{ $port.Port }}
+  {{- if $port.HealthCheckNodePort }}
+  # only balance to the nodes with local endpoints
+  option httpchk GET /healthz
+  {{- end }}
+  {{- range $server := $.BackendServers }}
+  server {{ $server }} {{ $server }}:{{ $port.NodePort }} check {{- if $port.HealthCheckNodePort }} port {{ $port.HealthCheckNodePort }} {{- end }} resolvers docker resolve-prefer {{ if $.IPv6 -}} ipv6 {{- else -}} ipv4 {{- end }}
+  {{- end }}
+{{ end -}}
 `
 
 // Config returns a kubeadm config generated from config data, in particular
 // the kubernetes version
 func Config(data *ConfigData) (config string, err error) {
-	t, err := template.New("loadbalancer-config").Parse(DefaultConfigTemplate)
+	return render(DefaultConfigTemplate, data)
+}
+
+// ServiceConfig returns the config of the load balancer of a Service
+// generated from config data
+func ServiceConfig(data *ServiceConfigData) (config string, err error) {
+	return render(ServiceConfigTemplate, data)
+}
+
+// render executes the config template tmpl with data
+func render(tmpl string, data interface{}) (string, error) {
+	t, err := template.New("loadbalancer-config").Parse(tmpl)
 	if err != nil {
 		return "", errors.Wrap(err, "failed to parse config template")
 	}
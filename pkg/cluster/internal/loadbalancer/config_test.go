@@ -0,0 +1,94 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadbalancer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConfig(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name        string
+		Data        *ConfigData
+		Contains    []string
+		NotContains []string
+	}{
+		{
+			Name: "defaults",
+			Data: &ConfigData{
+				ControlPlanePort: 6443,
+				BackendServers:   map[string]string{"kind-control-plane": "kind-control-plane:6443"},
+			},
+			Contains: []string{
+				"timeout connect 5000\n",
+				"timeout server 50000\n",
+				"default-server init-addr none\n",
+				"server kind-control-plane kind-control-plane:6443 check check-ssl verify none resolvers docker resolve-prefer ipv4\n",
+			},
+			NotContains: []string{"timeout check", "frontend ingress"},
+		},
+		{
+			Name: "configured",
+			Data: &ConfigData{
+				ControlPlanePort: 6443,
+				BackendServers:   map[string]string{"kind-control-plane": "kind-control-plane:6443"},
+				ConnectTimeout:   1000,
+				CheckTimeout:     500,
+				CheckInterval:    250,
+				CAFile:           CAPath,
+				ExtraFrontends: []Frontend{{
+					Name:           "ingress",
+					Port:           80,
+					BackendPort:    30080,
+					BackendServers: []string{"kind-worker", "kind-worker2"},
+				}},
+			},
+			Contains: []string{
+				"timeout connect 1000\n",
+				"timeout check 500\n",
+				"default-server init-addr none inter 250\n",
+				"check check-ssl verify required ca-file " + CAPath + " verifyhost kubernetes resolvers",
+				"frontend ingress\n  bind *:80\n",
+				"default_backend ingress-nodes\n",
+				"server kind-worker2 kind-worker2:30080 check resolvers docker resolve-prefer ipv4\n",
+			},
+			NotContains: []string{"verify none"},
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			config, err := Config(tc.Data)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, s := range tc.Contains {
+				if !strings.Contains(config, s) {
+					t.Errorf("expected config to contain %q, got:\n%s", s, config)
+				}
+			}
+			for _, s := range tc.NotContains {
+				if strings.Contains(config, s) {
+					t.Errorf("expected config not to contain %q, got:\n%s", s, config)
+				}
+			}
+		})
+	}
+}
@@ -16,8 +16,14 @@ limitations under the License.
 
 package loadbalancer
 
-// Image defines the loadbalancer image:tag
-const Image = "docker.io/kindest/haproxy:v20230606-42a2262b"
+import "sigs.k8s.io/kind/pkg/apis/config/defaults"
+
+// Image defines the default loadbalancer image:tag
+const Image = defaults.LoadBalancerImage
+
+// CAPath defines the path to the cluster CA in the image, the API servers
+// are verified against it if present
+const CAPath = "/usr/local/etc/haproxy/ca.crt"
 
 // ConfigPath defines the path to the config file in the image
 const ConfigPath = "/usr/local/etc/haproxy/haproxy.cfg"
@@ -0,0 +1,269 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package portforward implements forwarding host ports to the nodes of a
+// running cluster, with a haproxy helper container per forwarded port
+package portforward
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/cluster/constants"
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/log"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/clusterconfig"
+	"sigs.k8s.io/kind/pkg/cluster/internal/loadbalancer"
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers"
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/common"
+)
+
+// helperRole is the helper role label value of the port forwards
+const helperRole = "port-forward"
+
+// nodeLabelKey is applied to port forwards, the value is the target node
+const nodeLabelKey = "io.x-k8s.kind.forward.node"
+
+// nodePortLabelKey is applied to port forwards, the value is the target port
+const nodePortLabelKey = "io.x-k8s.kind.forward.port"
+
+// Forward is a port on the host forwarded to a port of a node
+type Forward struct {
+	// Node is the name of the node
+	Node string
+	// ListenAddress is the host address the port is published on
+	ListenAddress string
+	// HostPort is the port on the host
+	HostPort int32
+	// NodePort is the port on the node
+	NodePort int32
+	// Protocol is the port protocol, e.g. "tcp"
+	Protocol string
+	// Dynamic is true for the ports forwarded by Expose, and false for the
+	// ports published when the node was created
+	Dynamic bool
+	// Service is the namespace and name of the Service for the ports of
+	// Service load balancers, NodePort is then the Service port and Node
+	// is unset
+	Service string
+}
+
+// Expose forwards forward.HostPort on the host to forward.NodePort of the
+// node forward.Node of the cluster, or of its bootstrap control plane node if
+// unset, until Unexpose is called or the cluster is deleted
+func Expose(logger log.Logger, p providers.Provider, cluster string, forward *Forward) error {
+	if forward.Protocol != "" && strings.ToLower(forward.Protocol) != "tcp" {
+		return errors.Errorf("unsupported protocol %q, only tcp ports can be exposed on running clusters", forward.Protocol)
+	}
+	for _, port := range []int32{forward.HostPort, forward.NodePort} {
+		if port < 1 || port > 65535 {
+			return errors.Errorf("invalid port %d, must be between 1 and 65535", port)
+		}
+	}
+
+	allNodes, err := p.ListNodes(cluster)
+	if err != nil {
+		return err
+	}
+	if len(allNodes) == 0 {
+		return errors.Errorf("unknown cluster %q", cluster)
+	}
+	target, err := targetNode(allNodes, forward.Node)
+	if err != nil {
+		return err
+	}
+	cfg, err := clusterconfig.Get(p, cluster, allNodes)
+	if err != nil {
+		return err
+	}
+
+	name := helperName(cluster, forward.HostPort)
+	existing, err := p.ListHelpers(cluster, common.HelperRoleLabelKey, helperRole)
+	if err != nil {
+		return err
+	}
+	for _, n := range existing {
+		if n.String() == name {
+			return errors.Errorf("host port %d is already exposed for cluster %q", forward.HostPort, cluster)
+		}
+	}
+
+	helper := &providers.Helper{
+		Name:  name,
+		Image: loadbalancer.Image,
+		Labels: map[string]string{
+			common.HelperRoleLabelKey: helperRole,
+			nodeLabelKey:              target.String(),
+			nodePortLabelKey:          strconv.Itoa(int(forward.NodePort)),
+		},
+		PortMappings: []config.PortMapping{{
+			// the forward listens on the node port as well
+			ContainerPort: forward.NodePort,
+			HostPort:      forward.HostPort,
+			ListenAddress: forward.ListenAddress,
+			Protocol:      config.PortMappingProtocolTCP,
+		}},
+		IPFamily: cfg.Networking.IPFamily,
+	}
+	logger.V(0).Infof("Forwarding host port %d to %s:%d ...", forward.HostPort, target.String(), forward.NodePort)
+	n, err := p.EnsureHelper(cluster, helper)
+	if err != nil {
+		return err
+	}
+	ipv6 := cfg.Networking.IPFamily == config.IPv6Family
+	if err := configure(n, target.String(), forward.NodePort, ipv6); err != nil {
+		// do not leave a forward that does not work behind
+		_ = p.DeleteNodes([]nodes.Node{n})
+		return err
+	}
+	return nil
+}
+
+// Unexpose stops forwarding hostPort to the nodes of the cluster
+func Unexpose(logger log.Logger, p providers.Provider, cluster string, hostPort int32) error {
+	name := helperName(cluster, hostPort)
+	existing, err := p.ListHelpers(cluster, common.HelperRoleLabelKey, helperRole)
+	if err != nil {
+		return err
+	}
+	for _, n := range existing {
+		if n.String() == name {
+			logger.V(0).Infof("Removing the forward of host port %d ...", hostPort)
+			return p.DeleteNodes([]nodes.Node{n})
+		}
+	}
+	return errors.Errorf("host port %d is not exposed for cluster %q", hostPort, cluster)
+}
+
+// List returns the ports on the host forwarded to the nodes of the cluster,
+// both the ports published when the nodes were created and the ports
+// forwarded by Expose
+func List(p providers.Provider, cluster string) ([]Forward, error) {
+	allNodes, err := p.ListNodes(cluster)
+	if err != nil {
+		return nil, err
+	}
+	if len(allNodes) == 0 {
+		return nil, errors.Errorf("unknown cluster %q", cluster)
+	}
+	forwards := []Forward{}
+	for _, n := range allNodes {
+		status, err := p.NodeStatus(n)
+		if err != nil {
+			return nil, err
+		}
+		for _, port := range status.Ports {
+			forwards = append(forwards, Forward{
+				Node:          n.String(),
+				ListenAddress: port.ListenAddress,
+				HostPort:      port.HostPort,
+				NodePort:      port.ContainerPort,
+				Protocol:      port.Protocol,
+			})
+		}
+	}
+
+	helpers, err := p.ListHelpers(cluster, common.HelperRoleLabelKey, helperRole)
+	if err != nil {
+		return nil, err
+	}
+	for _, n := range helpers {
+		status, err := p.NodeStatus(n)
+		if err != nil {
+			return nil, err
+		}
+		nodePort, err := strconv.Atoi(status.Labels[nodePortLabelKey])
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid node port label on port forward %q", n.String())
+		}
+		for _, port := range status.Ports {
+			forwards = append(forwards, Forward{
+				Node:          status.Labels[nodeLabelKey],
+				ListenAddress: port.ListenAddress,
+				HostPort:      port.HostPort,
+				NodePort:      int32(nodePort),
+				Protocol:      port.Protocol,
+				Dynamic:       true,
+			})
+		}
+	}
+
+	Sort(forwards)
+	return forwards, nil
+}
+
+// Sort sorts forwards by host port and address
+func Sort(forwards []Forward) {
+	sort.SliceStable(forwards, func(i, j int) bool {
+		if forwards[i].HostPort != forwards[j].HostPort {
+			return forwards[i].HostPort < forwards[j].HostPort
+		}
+		return forwards[i].ListenAddress < forwards[j].ListenAddress
+	})
+}
+
+// targetNode returns the Kubernetes node named name from allNodes, or the
+// bootstrap control plane node if name is empty
+func targetNode(allNodes []nodes.Node, name string) (nodes.Node, error) {
+	if name == "" {
+		return nodeutils.BootstrapControlPlaneNode(allNodes)
+	}
+	for _, n := range allNodes {
+		if n.String() != name {
+			continue
+		}
+		role, err := n.Role()
+		if err != nil {
+			return nil, err
+		}
+		if role == constants.ExternalLoadBalancerNodeRoleValue {
+			return nil, errors.Errorf("node %q is not a Kubernetes node", name)
+		}
+		return n, nil
+	}
+	return nil, errors.Errorf("unknown node %q", name)
+}
+
+// configure writes the config forwarding to nodePort of node to the port
+// forward n, haproxy will reload on SIGHUP
+func configure(n nodes.Node, node string, nodePort int32, ipv6 bool) error {
+	forwardConfig, err := loadbalancer.ServiceConfig(&loadbalancer.ServiceConfigData{
+		Ports:          []loadbalancer.ServicePort{{Port: nodePort, NodePort: nodePort}},
+		BackendServers: []string{node},
+		IPv6:           ipv6,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to generate port forward config")
+	}
+	if err := nodeutils.WriteFile(n, loadbalancer.ConfigPath, forwardConfig); err != nil {
+		return errors.Wrap(err, "failed to copy port forward config")
+	}
+	if err := n.Command("kill", "-s", "HUP", "1").Run(); err != nil {
+		return errors.Wrap(err, "failed to reload port forward")
+	}
+	return nil
+}
+
+// helperName returns the name of the port forward of hostPort
+func helperName(cluster string, hostPort int32) string {
+	return fmt.Sprintf("%s-forward-%d", cluster, hostPort)
+}
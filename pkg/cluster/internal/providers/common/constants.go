@@ -19,3 +19,11 @@ package common
 // APIServerInternalPort defines the port where the control plane is listening
 // _inside_ the node network
 const APIServerInternalPort = 6443
+
+// RegistryPort defines the port where the local registry is listening
+// inside the registry container, and on the node network
+const RegistryPort = 5000
+
+// HelperRoleLabelKey is applied to helper containers, which are not nodes,
+// to identify what they are used for
+const HelperRoleLabelKey = "io.x-k8s.kind.helper.role"
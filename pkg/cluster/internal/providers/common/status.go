@@ -0,0 +1,104 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+// NodeStatusFormat returns the inspect --format template for ParseNodeStatus,
+// given the template field holding the node image for the runtime
+func NodeStatusFormat(imageField string) string {
+	return "{{" + imageField + "}}\t{{.State.Status}}\t{{json .NetworkSettings.Ports}}\t{{json .Config.Labels}}"
+}
+
+// ParseNodeStatus parses the output of inspecting a node container with
+// the NodeStatusFormat template
+func ParseNodeStatus(lines []string) (*nodes.Status, error) {
+	if len(lines) != 1 {
+		return nil, errors.Errorf("node status should only be one line, got %d lines", len(lines))
+	}
+	parts := strings.Split(lines[0], "\t")
+	if len(parts) != 4 {
+		return nil, errors.Errorf("node status should only be four parts, got %d", len(parts))
+	}
+	ports, err := parsePorts(parts[2])
+	if err != nil {
+		return nil, err
+	}
+	labels := map[string]string{}
+	if err := json.Unmarshal([]byte(parts[3]), &labels); err != nil {
+		return nil, errors.Wrap(err, "failed to parse node labels")
+	}
+	return &nodes.Status{
+		Image:  parts[0],
+		State:  parts[1],
+		Ports:  ports,
+		Labels: labels,
+	}, nil
+}
+
+// parsePorts parses the docker compatible NetworkSettings.Ports JSON, e.g.
+// {"6443/tcp":[{"HostIp":"127.0.0.1","HostPort":"34567"}],"80/tcp":null}
+func parsePorts(raw string) ([]nodes.PortMapping, error) {
+	bindings := map[string][]struct {
+		HostIP   string `json:"HostIp"`
+		HostPort string `json:"HostPort"`
+	}{}
+	if err := json.Unmarshal([]byte(raw), &bindings); err != nil {
+		return nil, errors.Wrap(err, "failed to parse node ports")
+	}
+	ports := []nodes.PortMapping{}
+	for key, hostBindings := range bindings {
+		port, protocol := key, "tcp"
+		if i := strings.IndexRune(key, '/'); i != -1 {
+			port, protocol = key[:i], key[i+1:]
+		}
+		containerPort, err := strconv.ParseInt(port, 10, 32)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid node port %q", key)
+		}
+		for _, b := range hostBindings {
+			hostPort, err := strconv.ParseInt(b.HostPort, 10, 32)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid host port for node port %q", key)
+			}
+			ports = append(ports, nodes.PortMapping{
+				ContainerPort: int32(containerPort),
+				HostPort:      int32(hostPort),
+				ListenAddress: b.HostIP,
+				Protocol:      protocol,
+			})
+		}
+	}
+	sort.Slice(ports, func(i, j int) bool {
+		if ports[i].ContainerPort != ports[j].ContainerPort {
+			return ports[i].ContainerPort < ports[j].ContainerPort
+		}
+		if ports[i].Protocol != ports[j].Protocol {
+			return ports[i].Protocol < ports[j].Protocol
+		}
+		return ports[i].ListenAddress < ports[j].ListenAddress
+	})
+	return ports, nil
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestParseNodeStatus(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name        string
+		Lines       []string
+		Expected    *nodes.Status
+		ExpectError bool
+	}{
+		{
+			Name: "running control plane",
+			Lines: []string{
+				"kindest/node:v1.31.0\trunning\t" +
+					`{"30000/udp":[{"HostIp":"0.0.0.0","HostPort":"30000"},{"HostIp":"::","HostPort":"30000"}],` +
+					`"6443/tcp":[{"HostIp":"127.0.0.1","HostPort":"34567"}],"80/tcp":null}` +
+					"\t" + `{"io.x-k8s.kind.cluster":"kind","io.x-k8s.kind.role":"control-plane"}`,
+			},
+			Expected: &nodes.Status{
+				Image: "kindest/node:v1.31.0",
+				State: "running",
+				Ports: []nodes.PortMapping{
+					{ContainerPort: 6443, HostPort: 34567, ListenAddress: "127.0.0.1", Protocol: "tcp"},
+					{ContainerPort: 30000, HostPort: 30000, ListenAddress: "0.0.0.0", Protocol: "udp"},
+					{ContainerPort: 30000, HostPort: 30000, ListenAddress: "::", Protocol: "udp"},
+				},
+				Labels: map[string]string{
+					"io.x-k8s.kind.cluster": "kind",
+					"io.x-k8s.kind.role":    "control-plane",
+				},
+			},
+		},
+		{
+			Name:  "stopped worker",
+			Lines: []string{"kindest/node:v1.31.0\texited\t{}\t{}"},
+			Expected: &nodes.Status{
+				Image:  "kindest/node:v1.31.0",
+				State:  "exited",
+				Ports:  []nodes.PortMapping{},
+				Labels: map[string]string{},
+			},
+		},
+		{
+			Name:        "missing fields",
+			Lines:       []string{"kindest/node:v1.31.0"},
+			ExpectError: true,
+		},
+		{
+			Name:        "invalid ports",
+			Lines:       []string{"kindest/node:v1.31.0\trunning\t{\"http/tcp\":[]}\t{}"},
+			ExpectError: true,
+		},
+		{
+			Name:        "invalid labels",
+			Lines:       []string{"kindest/node:v1.31.0\trunning\t{}\t[]"},
+			ExpectError: true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			result, err := ParseNodeStatus(tc.Lines)
+			assert.ExpectError(t, tc.ExpectError, err)
+			if err == nil {
+				assert.DeepEqual(t, tc.Expected, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"net"
+)
+
+// MissingSubnets returns the subnets in want that are not in existing,
+// comparing the parsed CIDRs so that equivalent notations match
+func MissingSubnets(existing, want []string) []string {
+	have := map[string]bool{}
+	for _, subnet := range existing {
+		if _, cidr, err := net.ParseCIDR(subnet); err == nil {
+			have[cidr.String()] = true
+		}
+	}
+	missing := []string{}
+	for _, subnet := range want {
+		_, cidr, err := net.ParseCIDR(subnet)
+		if err != nil || !have[cidr.String()] {
+			missing = append(missing, subnet)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestMissingSubnets(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		name     string
+		existing []string
+		want     []string
+		missing  []string
+	}{
+		{
+			name:     "all present",
+			existing: []string{"172.18.0.0/16", "fc00:f853:ccd:e793::/64"},
+			want:     []string{"172.18.0.0/16"},
+			missing:  []string{},
+		},
+		{
+			name:     "equivalent notation",
+			existing: []string{"fc00:f853:0ccd:e793::/64"},
+			want:     []string{"fc00:f853:ccd:e793::/64"},
+			missing:  []string{},
+		},
+		{
+			name:     "missing",
+			existing: []string{"172.18.0.0/16"},
+			want:     []string{"172.30.0.0/24", "fd00:30::/64"},
+			missing:  []string{"172.30.0.0/24", "fd00:30::/64"},
+		},
+	}
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			assert.DeepEqual(t, tc.missing, MissingSubnets(tc.existing, tc.want))
+		})
+	}
+}
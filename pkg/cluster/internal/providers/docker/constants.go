@@ -22,3 +22,19 @@ const clusterLabelKey = "io.x-k8s.kind.cluster"
 // nodeRoleLabelKey is applied to each "node" docker container for categorization
 // of nodes by role
 const nodeRoleLabelKey = "io.x-k8s.kind.role"
+
+// registryLabelKey is applied to each registry docker container created by kind,
+// the value is the registry name
+const registryLabelKey = "io.x-k8s.kind.registry"
+
+// registryClusterLabelKey is applied to registry docker containers that are
+// not shared, the value is the name of the cluster that owns the registry
+const registryClusterLabelKey = "io.x-k8s.kind.registry.cluster"
+
+// networkClusterLabelKey is applied to the additional networks created by
+// kind, the value is the name of the cluster that created the network
+const networkClusterLabelKey = "io.x-k8s.kind.network.cluster"
+
+// helperClusterLabelKey is applied to the helper containers of a cluster,
+// which are not nodes, with the cluster name as the value
+const helperClusterLabelKey = "io.x-k8s.kind.helper.cluster"
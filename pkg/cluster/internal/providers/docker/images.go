@@ -26,6 +26,7 @@ import (
 	"sigs.k8s.io/kind/pkg/log"
 
 	"sigs.k8s.io/kind/pkg/cluster/internal/providers/common"
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
 	"sigs.k8s.io/kind/pkg/internal/apis/config"
 	"sigs.k8s.io/kind/pkg/internal/cli"
 )
@@ -46,6 +47,24 @@ func ensureNodeImages(logger log.Logger, status *cli.Status, cfg *config.Cluster
 	return nil
 }
 
+// RunImage is part of the providers.Provider interface
+func (p *provider) RunImage(status *cli.Status, image, name string) (nodes.Node, error) {
+	if err := ensureNodeImages(p.logger, status, &config.Cluster{Nodes: []config.Node{{Image: image}}}); err != nil {
+		return nil, err
+	}
+	args := []string{
+		"-d",                 // make the client exit while the container continues to run
+		"--entrypoint=sleep", // the container should hang forever, so we can exec in it
+		"--security-opt", "seccomp=unconfined",
+		image,
+		"infinity",
+	}
+	if err := createContainer(name, args); err != nil {
+		return nil, errors.Wrapf(err, "failed to run image %q", image)
+	}
+	return p.node(name), nil
+}
+
 // pullIfNotPresent will pull an image if it is not present locally
 // retrying up to retries times
 // it returns true if it attempted to pull, and any errors from pulling
@@ -52,7 +52,7 @@ func TestIntegrationEnsureNetworkConcurrent(t *testing.T) {
 	errCh := make(chan error, networkConcurrency)
 	for i := 0; i < networkConcurrency; i++ {
 		go func() {
-			errCh <- ensureNetwork(testNetworkName)
+			errCh <- ensureNetwork(testNetworkName, "")
 		}()
 	}
 	for i := 0; i < networkConcurrency; i++ {
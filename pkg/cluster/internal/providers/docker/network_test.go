@@ -160,3 +160,48 @@ func Test_sortNetworkInspectEntries(t *testing.T) {
 		})
 	}
 }
+
+func Test_networkCreateArgs(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		name            string
+		subnets         []string
+		mtu             int
+		staticAddresses bool
+		expected        []string
+	}{
+		{
+			name:    "generated IPv6 subnet",
+			subnets: []string{"fc00:f853:ccd:e793::/64"},
+			expected: []string{"network", "create", "-d=bridge",
+				"-o", "com.docker.network.bridge.enable_ip_masquerade=true",
+				"--ipv6", "--subnet", "fc00:f853:ccd:e793::/64",
+				"kind",
+			},
+		},
+		{
+			name:            "node subnets",
+			subnets:         []string{"172.30.0.0/24", "fd00:30::/64"},
+			mtu:             1400,
+			staticAddresses: true,
+			expected: []string{"network", "create", "-d=bridge",
+				"-o", "com.docker.network.bridge.enable_ip_masquerade=true",
+				"-o", "com.docker.network.driver.mtu=1400",
+				"--subnet", "172.30.0.0/24", "--ip-range", "172.30.0.128/25",
+				"--ipv6", "--subnet", "fd00:30::/64", "--ip-range", "fd00:30:0:0:8000::/65",
+				"kind",
+			},
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			args, err := networkCreateArgs("kind", tc.subnets, tc.mtu, tc.staticAddresses)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			assert.DeepEqual(t, tc.expected, args)
+		})
+	}
+}
@@ -69,15 +69,13 @@ func (p *provider) Provision(status *cli.Status, cfg *config.Cluster) (err error
 	}
 
 	// ensure the pre-requisite network exists
-	networkName := fixedNetworkName
-	if n := os.Getenv("KIND_EXPERIMENTAL_DOCKER_NETWORK"); n != "" {
-		p.logger.Warn("WARNING: Overriding docker network due to KIND_EXPERIMENTAL_DOCKER_NETWORK")
-		p.logger.Warn("WARNING: Here be dragons! This is not supported currently.")
-		networkName = n
-	}
-	if err := ensureNetwork(networkName); err != nil {
+	networkName := p.networkName()
+	if err := ensureNetwork(networkName, cfg.Networking.NodeSubnet); err != nil {
 		return errors.Wrap(err, "failed to ensure docker network")
 	}
+	if err := ensureClusterNetworks(cfg.Name, networkName, cfg.Networks); err != nil {
+		return err
+	}
 
 	// actually provision the cluster
 	icons := strings.Repeat("📦 ", len(cfg.Nodes))
@@ -94,6 +92,67 @@ func (p *provider) Provision(status *cli.Status, cfg *config.Cluster) (err error
 	return errors.UntilErrorConcurrent(createContainerFuncs)
 }
 
+// ProvisionNode is part of the providers.Provider interface
+func (p *provider) ProvisionNode(status *cli.Status, cfg *config.Cluster, node *config.Node, name string) (err error) {
+	existing, err := p.ListNodes(cfg.Name)
+	if err != nil {
+		return err
+	}
+	if len(existing) == 0 {
+		return errors.Errorf("no nodes found for cluster %q", cfg.Name)
+	}
+
+	// default to the image of the existing nodes
+	node = node.DeepCopy()
+	if node.Image == "" {
+		bootstrap, err := nodeutils.BootstrapControlPlaneNode(existing)
+		if err != nil {
+			return err
+		}
+		if node.Image, err = containerImage(bootstrap.String()); err != nil {
+			return err
+		}
+	}
+	if err := ensureNodeImages(p.logger, status, &config.Cluster{Nodes: []config.Node{*node}}); err != nil {
+		return err
+	}
+
+	status.Start(fmt.Sprintf("Preparing node %s 📦", name))
+	defer func() { status.End(err == nil) }()
+
+	// the new node must know all the names for NO_PROXY
+	names := []string{name}
+	for _, n := range existing {
+		names = append(names, n.String())
+	}
+	networkName := p.networkName()
+	if err := ensureClusterNetworks(cfg.Name, networkName, cfg.Networks); err != nil {
+		return err
+	}
+	genericArgs, err := commonArgs(cfg.Name, cfg, networkName, names)
+	if err != nil {
+		return err
+	}
+	args, err := runArgsForNode(node, cfg.Networking.IPFamily, name, genericArgs)
+	if err != nil {
+		return err
+	}
+	if err := createContainerWithWaitUntilSystemdReachesMultiUserSystem(name, args); err != nil {
+		return err
+	}
+	return connectNetworks(name, node.Networks)
+}
+
+// networkName returns the name of the network nodes are attached to
+func (p *provider) networkName() string {
+	if n := os.Getenv("KIND_EXPERIMENTAL_DOCKER_NETWORK"); n != "" {
+		p.logger.Warn("WARNING: Overriding docker network due to KIND_EXPERIMENTAL_DOCKER_NETWORK")
+		p.logger.Warn("WARNING: Here be dragons! This is not supported currently.")
+		return n
+	}
+	return fixedNetworkName
+}
+
 // ListClusters is part of the providers.Provider interface
 func (p *provider) ListClusters() ([]string, error) {
 	cmd := exec.Command("docker",
@@ -154,6 +213,64 @@ func (p *provider) DeleteNodes(n []nodes.Node) error {
 	return nil
 }
 
+// StopNodes is part of the providers.Provider interface
+func (p *provider) StopNodes(n []nodes.Node) error {
+	return containersCommand("stop", n)
+}
+
+// StartNodes is part of the providers.Provider interface
+func (p *provider) StartNodes(n []nodes.Node) error {
+	return containersCommand("start", n)
+}
+
+// PauseNodes is part of the providers.Provider interface
+func (p *provider) PauseNodes(n []nodes.Node) error {
+	return containersCommand("pause", n)
+}
+
+// UnpauseNodes is part of the providers.Provider interface
+func (p *provider) UnpauseNodes(n []nodes.Node) error {
+	return containersCommand("unpause", n)
+}
+
+// containersCommand runs `docker <command>` against the node containers n
+func containersCommand(command string, n []nodes.Node) error {
+	if len(n) == 0 {
+		return nil
+	}
+	args := make([]string, 0, len(n)+1) // allocate once
+	args = append(args, command)
+	for _, node := range n {
+		args = append(args, node.String())
+	}
+	if err := exec.Command("docker", args...).Run(); err != nil {
+		return errors.Wrapf(err, "failed to %s nodes", command)
+	}
+	return nil
+}
+
+// NodeStatus is part of the providers.Provider interface
+func (p *provider) NodeStatus(node nodes.Node) (*nodes.Status, error) {
+	lines, err := exec.OutputLines(exec.Command(
+		"docker", "inspect", "--format", common.NodeStatusFormat(".Config.Image"), node.String(),
+	))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get status of node %q", node.String())
+	}
+	return common.ParseNodeStatus(lines)
+}
+
+// GetNetwork is part of the providers.Provider interface
+func (p *provider) GetNetwork(cluster string) (*providers.NetworkInfo, error) {
+	// all clusters share the same network
+	name := p.networkName()
+	subnets, err := networkSubnets(name)
+	if err != nil {
+		return nil, err
+	}
+	return &providers.NetworkInfo{Name: name, Subnets: subnets}, nil
+}
+
 // GetAPIServerEndpoint is part of the providers.Provider interface
 func (p *provider) GetAPIServerEndpoint(cluster string) (string, error) {
 	// locate the node that hosts this
@@ -0,0 +1,148 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docker
+
+import (
+	"fmt"
+	"regexp"
+
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/common"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+// EnsureRegistry is part of the providers.Provider interface
+func (p *provider) EnsureRegistry(cluster string, registry *config.Registry) error {
+	networkName := p.networkName()
+	// the node network was created with the node subnet, if any, by Provision
+	if err := ensureNetwork(networkName, ""); err != nil {
+		return errors.Wrap(err, "failed to ensure docker network")
+	}
+
+	exists, err := containerExists(registry.Name)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		if _, err := pullIfNotPresent(p.logger, registry.Image, 4); err != nil {
+			return err
+		}
+		args := []string{
+			"run", "--detach",
+			"--restart=always",
+			"--name", registry.Name,
+			"--network", networkName,
+			"--label", fmt.Sprintf("%s=%s", registryLabelKey, registry.Name),
+			"--publish", fmt.Sprintf("%s:%d:%d/tcp", registry.ListenAddress, registry.HostPort, common.RegistryPort),
+		}
+		if !registry.Shared {
+			args = append(args, "--label", fmt.Sprintf("%s=%s", registryClusterLabelKey, cluster))
+		}
+		args = append(args, registry.Image)
+		if err := exec.Command("docker", args...).Run(); err != nil {
+			return errors.Wrapf(err, "failed to create registry %q", registry.Name)
+		}
+		return nil
+	}
+
+	// reuse the existing registry, which may have been created by other
+	// means and not be connected to the node network yet
+	if err := checkRegistryOwner(cluster, registry.Name); err != nil {
+		return err
+	}
+	p.logger.V(0).Infof("Using existing registry %q", registry.Name)
+	if err := exec.Command("docker", "start", registry.Name).Run(); err != nil {
+		return errors.Wrapf(err, "failed to start registry %q", registry.Name)
+	}
+	lines, err := exec.OutputLines(exec.Command(
+		"docker", "inspect",
+		"--type", "container",
+		"--format", `{{range $k, $v := .NetworkSettings.Networks}}{{$k}}{{"\n"}}{{end}}`,
+		registry.Name,
+	))
+	if err != nil {
+		return errors.Wrapf(err, "failed to inspect registry %q", registry.Name)
+	}
+	for _, network := range lines {
+		if network == networkName {
+			return nil
+		}
+	}
+	if err := exec.Command("docker", "network", "connect", networkName, registry.Name).Run(); err != nil {
+		return errors.Wrapf(err, "failed to connect registry %q to network %q", registry.Name, networkName)
+	}
+	return nil
+}
+
+// DeleteRegistry is part of the providers.Provider interface
+func (p *provider) DeleteRegistry(cluster string) error {
+	names, err := exec.OutputLines(exec.Command(
+		"docker", "ps",
+		"--all",
+		"--filter", fmt.Sprintf("label=%s=%s", registryClusterLabelKey, cluster),
+		"--format", "{{.Names}}",
+	))
+	if err != nil {
+		return errors.Wrap(err, "failed to list registries")
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	args := append([]string{"rm", "--force", "--volumes"}, names...)
+	if err := exec.Command("docker", args...).Run(); err != nil {
+		return errors.Wrap(err, "failed to delete registry")
+	}
+	return nil
+}
+
+// containerExists returns true if a container named name exists
+func containerExists(name string) (bool, error) {
+	lines, err := exec.OutputLines(exec.Command(
+		"docker", "ps",
+		"--all",
+		"--filter=name=^"+regexp.QuoteMeta(name)+"$",
+		"--format", "{{.Names}}",
+	))
+	if err != nil {
+		return false, errors.Wrap(err, "failed to list containers")
+	}
+	return len(lines) > 0, nil
+}
+
+// checkRegistryOwner checks that the existing registry name is shared or
+// owned by cluster, registries which are not shared are deleted with the
+// cluster that created them
+func checkRegistryOwner(cluster, name string) error {
+	lines, err := exec.OutputLines(exec.Command(
+		"docker", "inspect",
+		"--type", "container",
+		"--format", fmt.Sprintf("{{index .Config.Labels %q}}", registryClusterLabelKey),
+		name,
+	))
+	if err != nil {
+		return errors.Wrapf(err, "failed to inspect registry %q", name)
+	}
+	if len(lines) > 0 && lines[0] != "" && lines[0] != cluster {
+		return errors.Errorf(
+			"registry %q is owned by cluster %q and will be deleted with it, use a different name or create a shared registry with kind create registry",
+			name, lines[0],
+		)
+	}
+	return nil
+}
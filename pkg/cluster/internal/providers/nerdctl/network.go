@@ -26,6 +26,9 @@ import (
 
 	"sigs.k8s.io/kind/pkg/errors"
 	"sigs.k8s.io/kind/pkg/exec"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/common"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
 )
 
 // This may be overridden by KIND_EXPERIMENTAL_DOCKER_NETWORK env,
@@ -41,14 +44,27 @@ import (
 // networks.
 const fixedNetworkName = "kind"
 
-// ensureNetwork checks if docker network by name exists, if not it creates it
-func ensureNetwork(name, binaryName string) error {
+// ensureNetwork checks if docker network by name exists, if not it creates it.
+// If nodeSubnet is set the network is created with it, and an existing
+// network must have it.
+func ensureNetwork(name, nodeSubnet, binaryName string) error {
 	// check if network exists already and remove any duplicate networks
 	exists, err := checkIfNetworkExists(name, binaryName)
 	if err != nil {
 		return err
 	}
 
+	// the subnets are configured, there is nothing to pick
+	if nodeSubnet != "" {
+		subnets := strings.Split(nodeSubnet, ",")
+		if !exists {
+			if err := createNetworkWithSubnets(name, subnets, getDefaultNetworkMTU(binaryName), binaryName); err != nil {
+				return err
+			}
+		}
+		return checkNetworkSubnets(name, subnets, binaryName)
+	}
+
 	// network already exists, we're good
 	// TODO: the network might already exist and not have ipv6 ... :|
 	// discussion: https://github.com/kubernetes-sigs/kind/pull/1508#discussion_r414594198
@@ -130,6 +146,145 @@ func createNetwork(name, ipv6Subnet string, mtu int, binaryName string) error {
 	return exec.Command(binaryName, args...).Run()
 }
 
+// createNetworkWithSubnets creates the network by name with subnets,
+// reserving the lower half of each for static addresses
+func createNetworkWithSubnets(name string, subnets []string, mtu int, binaryName string) error {
+	args, err := networkCreateArgs(name, subnets, mtu)
+	if err != nil {
+		return err
+	}
+	return exec.Command(binaryName, args...).Run()
+}
+
+// networkCreateArgs returns the args creating the network by name with
+// subnets, reserving the lower half of each for static addresses
+func networkCreateArgs(name string, subnets []string, mtu int) ([]string, error) {
+	args := []string{"network", "create", "-d=bridge"}
+	if mtu > 0 {
+		args = append(args, "-o", fmt.Sprintf("com.docker.network.driver.mtu=%d", mtu))
+	}
+	subnetArgs, err := subnetArgs(subnets)
+	if err != nil {
+		return nil, err
+	}
+	args = append(args, subnetArgs...)
+	return append(args, name), nil
+}
+
+// subnetArgs returns the network create args for subnets, only the upper
+// half of each subnet is assigned dynamically, leaving the lower half for
+// static addresses
+func subnetArgs(subnets []string) ([]string, error) {
+	args := []string{}
+	for _, subnet := range subnets {
+		if isIPv6CIDR(subnet) {
+			args = append(args, "--ipv6")
+		}
+		ipRange, err := config.DynamicIPRange(subnet)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, "--subnet", subnet, "--ip-range", ipRange)
+	}
+	return args, nil
+}
+
+// checkNetworkSubnets returns an error if the network by name does not have
+// all of subnets
+func checkNetworkSubnets(name string, subnets []string, binaryName string) error {
+	existing, err := networkSubnets(name, binaryName)
+	if err != nil {
+		return err
+	}
+	if missing := common.MissingSubnets(existing, subnets); len(missing) > 0 {
+		return errors.Errorf(
+			"network %q already exists without the node subnet %s, it must be deleted to be created again with it",
+			name, strings.Join(missing, ","),
+		)
+	}
+	return nil
+}
+
+// networkSubnets returns the subnets of the network by name
+func networkSubnets(name, binaryName string) ([]string, error) {
+	lines, err := exec.OutputLines(exec.Command(
+		binaryName, "network", "inspect", "--format", "{{range .IPAM.Config}}{{println .Subnet}}{{end}}", name,
+	))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to inspect network %q", name)
+	}
+	var subnets []string
+	for _, line := range lines {
+		if line != "" {
+			subnets = append(subnets, line)
+		}
+	}
+	return subnets, nil
+}
+
+// ensureClusterNetworks creates the additional networks of the cluster that
+// do not exist yet, existing networks are used as they are
+func ensureClusterNetworks(cluster, nodeNetwork string, networks []config.Network, binaryName string) error {
+	for _, network := range networks {
+		if network.Name == nodeNetwork {
+			return errors.Errorf("network %q is already attached to all the nodes", network.Name)
+		}
+		exists, err := checkIfNetworkExists(network.Name, binaryName)
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+		args := []string{"network", "create", "-d=bridge",
+			"--label", fmt.Sprintf("%s=%s", networkClusterLabelKey, cluster),
+		}
+		if network.MTU > 0 {
+			args = append(args, "-o", fmt.Sprintf("com.docker.network.driver.mtu=%d", network.MTU))
+		}
+		if network.Internal {
+			args = append(args, "--internal")
+		}
+		if network.Subnet != "" {
+			subnetArgs, err := subnetArgs(strings.Split(network.Subnet, ","))
+			if err != nil {
+				return err
+			}
+			args = append(args, subnetArgs...)
+		}
+		args = append(args, network.Name)
+		if err := exec.Command(binaryName, args...).Run(); err != nil {
+			return errors.Wrapf(err, "failed to create network %q", network.Name)
+		}
+	}
+	return nil
+}
+
+// DeleteNetworks is part of the providers.Provider interface
+func (p *provider) DeleteNetworks(cluster string) error {
+	names, err := exec.OutputLines(exec.Command(
+		p.Binary(), "network", "ls",
+		"--filter", fmt.Sprintf("label=%s=%s", networkClusterLabelKey, cluster),
+		"--format", "{{.Name}}",
+	))
+	if err != nil {
+		return errors.Wrap(err, "failed to list networks")
+	}
+	for _, name := range names {
+		// the network may still be used by other clusters or containers
+		if err := exec.Command(p.Binary(), "network", "rm", name).Run(); err != nil {
+			p.logger.Warnf("Not deleting network %q, it may still be in use: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// isIPv6CIDR returns true if cidr is an IPv6 CIDR
+func isIPv6CIDR(cidr string) bool {
+	ip, _, _ := net.ParseCIDR(cidr)
+	return ip != nil && ip.To4() == nil
+}
+
 // getDefaultNetworkMTU obtains the MTU from the docker default network
 func getDefaultNetworkMTU(binaryName string) int {
 	cmd := exec.Command(binaryName, "network", "inspect", "bridge",
@@ -18,6 +18,7 @@ package nerdctl
 
 import (
 	"fmt"
+	"reflect"
 	"testing"
 )
 
@@ -66,3 +67,20 @@ func Test_generateULASubnetFromName(t *testing.T) {
 		})
 	}
 }
+
+func Test_networkCreateArgs(t *testing.T) {
+	t.Parallel()
+	args, err := networkCreateArgs("kind", []string{"172.30.0.0/24", "fd00:30::/64"}, 1400)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []string{"network", "create", "-d=bridge",
+		"-o", "com.docker.network.driver.mtu=1400",
+		"--subnet", "172.30.0.0/24", "--ip-range", "172.30.0.128/25",
+		"--ipv6", "--subnet", "fd00:30::/64", "--ip-range", "fd00:30:0:0:8000::/65",
+		"kind",
+	}
+	if !reflect.DeepEqual(expected, args) {
+		t.Errorf("expected %v but got %v", expected, args)
+	}
+}
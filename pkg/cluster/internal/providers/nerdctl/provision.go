@@ -41,7 +41,10 @@ func planCreation(cfg *config.Cluster, networkName, binaryName string) (createCo
 	nodeNamer := common.MakeNodeNamer(cfg.Name)
 	names := make([]string, len(cfg.Nodes))
 	for i, node := range cfg.Nodes {
-		name := nodeNamer(string(node.Role)) // name the node
+		name := node.Name // nodes restored from a snapshot keep their names
+		if name == "" {
+			name = nodeNamer(string(node.Role)) // name the node
+		}
 		names[i] = name
 	}
 	haveLoadbalancer := config.ClusterHasImplicitLoadBalancer(cfg)
@@ -220,6 +223,23 @@ func runArgsForNode(node *config.Node, clusterIPFamily config.ClusterIPFamily, n
 	}
 	args = append(args, mappingArgs...)
 
+	// static addresses on the node network
+	if node.IPv4Address != "" {
+		args = append(args, "--ip", node.IPv4Address)
+	}
+	if node.IPv6Address != "" {
+		args = append(args, "--ip6", node.IPv6Address)
+	}
+
+	// nerdctl can not connect running containers to networks, the additional
+	// networks are attached at creation instead
+	for _, network := range node.Networks {
+		if network.IPv4Address != "" || network.IPv6Address != "" {
+			return nil, errors.Errorf("static addresses on additional network %q are not supported with nerdctl", network.Name)
+		}
+		args = append(args, "--net", network.Name)
+	}
+
 	switch node.Role {
 	case config.ControlPlaneRole:
 		args = append(args, "-e", "KUBECONFIG=/etc/kubernetes/admin.conf")
@@ -238,21 +258,31 @@ func runArgsForLoadBalancer(cfg *config.Cluster, name string, args []string) ([]
 		args...,
 	)
 
-	// load balancer port mapping
-	mappingArgs, err := generatePortMappings(cfg.Networking.IPFamily,
-		config.PortMapping{
-			ListenAddress: cfg.Networking.APIServerAddress,
-			HostPort:      cfg.Networking.APIServerPort,
-			ContainerPort: common.APIServerInternalPort,
-		},
-	)
+	// load balancer port mappings, and the image which may be configured
+	image := loadbalancer.Image
+	portMappings := []config.PortMapping{{
+		ListenAddress: cfg.Networking.APIServerAddress,
+		HostPort:      cfg.Networking.APIServerPort,
+		ContainerPort: common.APIServerInternalPort,
+	}}
+	if cfg.LoadBalancer != nil {
+		image = cfg.LoadBalancer.Image
+		for _, f := range cfg.LoadBalancer.ExtraFrontends {
+			portMappings = append(portMappings, config.PortMapping{
+				ListenAddress: f.ListenAddress,
+				HostPort:      f.HostPort,
+				ContainerPort: f.Port,
+			})
+		}
+	}
+	mappingArgs, err := generatePortMappings(cfg.Networking.IPFamily, portMappings...)
 	if err != nil {
 		return nil, err
 	}
 	args = append(args, mappingArgs...)
 
 	// finally, specify the image to run
-	return append(args, loadbalancer.Image), nil
+	return append(args, image), nil
 }
 
 func getProxyEnv(cfg *config.Cluster, networkName string, nodeNames []string, binaryName string) (map[string]string, error) {
@@ -372,6 +402,18 @@ func generatePortMappings(clusterIPFamily config.ClusterIPFamily, portMappings .
 	return args, nil
 }
 
+// containerImage returns the image the container name was created from
+func containerImage(name, binaryName string) (string, error) {
+	lines, err := exec.OutputLines(exec.Command(binaryName, "inspect", "--format", "{{.Config.Image}}", name))
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get image for %q", name)
+	}
+	if len(lines) != 1 {
+		return "", errors.Errorf("image details should only be one line, got %d lines", len(lines))
+	}
+	return lines[0], nil
+}
+
 func createContainer(name string, args []string, binaryName string) error {
 	return exec.Command(binaryName, append([]string{"run", "--name", name}, args...)...).Run()
 }
@@ -0,0 +1,91 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podman
+
+import (
+	"fmt"
+	"sort"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers"
+)
+
+// EnsureHelper is part of the providers.Provider interface
+func (p *provider) EnsureHelper(cluster string, helper *providers.Helper) (nodes.Node, error) {
+	exists, err := containerExists(helper.Name)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return p.node(helper.Name), nil
+	}
+	if _, err := pullIfNotPresent(p.logger, helper.Image, 4); err != nil {
+		return nil, err
+	}
+	args := []string{
+		"run", "--detach",
+		// like the nodes, only restart on host / daemon reboots
+		"--restart=on-failure:1",
+		"--name", helper.Name,
+		"--hostname", helper.Name,
+		"--network", p.networkName(),
+		"--label", fmt.Sprintf("%s=%s", helperClusterLabelKey, cluster),
+	}
+	keys := make([]string, 0, len(helper.Labels))
+	for key := range helper.Labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		args = append(args, "--label", fmt.Sprintf("%s=%s", key, helper.Labels[key]))
+	}
+	mappingArgs, err := generatePortMappings(helper.IPFamily, helper.PortMappings...)
+	if err != nil {
+		return nil, err
+	}
+	args = append(args, mappingArgs...)
+	args = append(args, helper.Image)
+	if err := exec.Command("podman", args...).Run(); err != nil {
+		return nil, errors.Wrapf(err, "failed to create helper container %q", helper.Name)
+	}
+	return p.node(helper.Name), nil
+}
+
+// ListHelpers is part of the providers.Provider interface
+func (p *provider) ListHelpers(cluster, key, value string) ([]nodes.Node, error) {
+	args := []string{
+		"ps",
+		"--all",
+		"--filter", fmt.Sprintf("label=%s=%s", helperClusterLabelKey, cluster),
+	}
+	if key != "" {
+		args = append(args, "--filter", fmt.Sprintf("label=%s=%s", key, value))
+	}
+	args = append(args, "--format", "{{.Names}}")
+	names, err := exec.OutputLines(exec.Command("podman", args...))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list helper containers")
+	}
+	helpers := make([]nodes.Node, 0, len(names))
+	for _, name := range names {
+		helpers = append(helpers, p.node(name))
+	}
+	return helpers, nil
+}
@@ -19,12 +19,16 @@ package podman
 import (
 	"crypto/sha1"
 	"encoding/binary"
+	"fmt"
 	"net"
 	"regexp"
 	"strings"
 
 	"sigs.k8s.io/kind/pkg/errors"
 	"sigs.k8s.io/kind/pkg/exec"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/common"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
 )
 
 // This may be overridden by KIND_EXPERIMENTAL_PODMAN_NETWORK env,
@@ -42,7 +46,20 @@ const fixedNetworkName = "kind"
 
 // ensureNetwork creates a new network
 // podman only creates IPv6 networks for versions >= 2.2.0
-func ensureNetwork(name string) error {
+// If nodeSubnet is set the network is created with it, and an existing
+// network must have it.
+func ensureNetwork(name, nodeSubnet string) error {
+	// the subnets are configured, there is nothing to pick
+	if nodeSubnet != "" {
+		subnets := strings.Split(nodeSubnet, ",")
+		if !checkIfNetworkExists(name) {
+			if err := createNetworkWithSubnets(name, subnets); err != nil {
+				return err
+			}
+		}
+		return checkNetworkSubnets(name, subnets)
+	}
+
 	// network already exists
 	if checkIfNetworkExists(name) {
 		return nil
@@ -94,6 +111,79 @@ func createNetwork(name, ipv6Subnet string) error {
 		"--ipv6", "--subnet", ipv6Subnet, name).Run()
 }
 
+// createNetworkWithSubnets creates the network by name with subnets,
+// reserving the lower half of each for static addresses
+func createNetworkWithSubnets(name string, subnets []string) error {
+	args, err := networkCreateArgs(name, subnets)
+	if err != nil {
+		return err
+	}
+	return exec.Command("podman", args...).Run()
+}
+
+// networkCreateArgs returns the args creating the network by name with
+// subnets, reserving the lower half of each for static addresses
+func networkCreateArgs(name string, subnets []string) ([]string, error) {
+	args := []string{"network", "create", "-d=bridge"}
+	subnetArgs, err := subnetArgs(subnets)
+	if err != nil {
+		return nil, err
+	}
+	args = append(args, subnetArgs...)
+	return append(args, name), nil
+}
+
+// subnetArgs returns the network create args for subnets, only the upper
+// half of each subnet is assigned dynamically, leaving the lower half for
+// static addresses
+func subnetArgs(subnets []string) ([]string, error) {
+	args := []string{}
+	for _, subnet := range subnets {
+		if isIPv6CIDR(subnet) {
+			args = append(args, "--ipv6")
+		}
+		ipRange, err := config.DynamicIPRange(subnet)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, "--subnet", subnet, "--ip-range", ipRange)
+	}
+	return args, nil
+}
+
+// checkNetworkSubnets returns an error if the network by name does not have
+// all of subnets
+func checkNetworkSubnets(name string, subnets []string) error {
+	existing, err := networkSubnets(name)
+	if err != nil {
+		return err
+	}
+	if missing := common.MissingSubnets(existing, subnets); len(missing) > 0 {
+		return errors.Errorf(
+			"network %q already exists without the node subnet %s, it must be deleted to be created again with it",
+			name, strings.Join(missing, ","),
+		)
+	}
+	return nil
+}
+
+// networkSubnets returns the subnets of the network by name
+func networkSubnets(name string) ([]string, error) {
+	lines, err := exec.OutputLines(exec.Command(
+		"podman", "network", "inspect", "--format", "{{range .Subnets}}{{println .Subnet}}{{end}}", name,
+	))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to inspect network %q", name)
+	}
+	var subnets []string
+	for _, line := range lines {
+		if line != "" {
+			subnets = append(subnets, line)
+		}
+	}
+	return subnets, nil
+}
+
 func checkIfNetworkExists(name string) bool {
 	_, err := exec.Output(exec.Command(
 		"podman", "network", "inspect",
@@ -102,6 +192,83 @@ func checkIfNetworkExists(name string) bool {
 	return err == nil
 }
 
+// ensureClusterNetworks creates the additional networks of the cluster that
+// do not exist yet, existing networks are used as they are
+func ensureClusterNetworks(cluster, nodeNetwork string, networks []config.Network) error {
+	for _, network := range networks {
+		if network.Name == nodeNetwork {
+			return errors.Errorf("network %q is already attached to all the nodes", network.Name)
+		}
+		if checkIfNetworkExists(network.Name) {
+			continue
+		}
+		args := []string{"network", "create", "-d=bridge",
+			"--label", fmt.Sprintf("%s=%s", networkClusterLabelKey, cluster),
+		}
+		if network.MTU > 0 {
+			args = append(args, "--opt", fmt.Sprintf("mtu=%d", network.MTU))
+		}
+		if network.Internal {
+			args = append(args, "--internal")
+		}
+		if network.Subnet != "" {
+			subnetArgs, err := subnetArgs(strings.Split(network.Subnet, ","))
+			if err != nil {
+				return err
+			}
+			args = append(args, subnetArgs...)
+		}
+		args = append(args, network.Name)
+		if err := exec.Command("podman", args...).Run(); err != nil {
+			return errors.Wrapf(err, "failed to create network %q", network.Name)
+		}
+	}
+	return nil
+}
+
+// connectNetworks attaches the node container name to its additional networks
+func connectNetworks(name string, networks []config.NodeNetwork) error {
+	for _, network := range networks {
+		args := []string{"network", "connect"}
+		if network.IPv4Address != "" {
+			args = append(args, "--ip", network.IPv4Address)
+		}
+		if network.IPv6Address != "" {
+			args = append(args, "--ip6", network.IPv6Address)
+		}
+		args = append(args, network.Name, name)
+		if err := exec.Command("podman", args...).Run(); err != nil {
+			return errors.Wrapf(err, "failed to attach node %q to network %q", name, network.Name)
+		}
+	}
+	return nil
+}
+
+// DeleteNetworks is part of the providers.Provider interface
+func (p *provider) DeleteNetworks(cluster string) error {
+	names, err := exec.OutputLines(exec.Command(
+		"podman", "network", "ls",
+		"--filter", fmt.Sprintf("label=%s=%s", networkClusterLabelKey, cluster),
+		"--format", "{{.Name}}",
+	))
+	if err != nil {
+		return errors.Wrap(err, "failed to list networks")
+	}
+	for _, name := range names {
+		// the network may still be used by other clusters or containers
+		if err := exec.Command("podman", "network", "rm", name).Run(); err != nil {
+			p.logger.Warnf("Not deleting network %q, it may still be in use: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// isIPv6CIDR returns true if cidr is an IPv6 CIDR
+func isIPv6CIDR(cidr string) bool {
+	ip, _, _ := net.ParseCIDR(cidr)
+	return ip != nil && ip.To4() == nil
+}
+
 func isUnknownIPv6FlagError(err error) bool {
 	rerr := exec.RunErrorForError(err)
 	return rerr != nil &&
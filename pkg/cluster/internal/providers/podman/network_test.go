@@ -0,0 +1,36 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podman
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func Test_networkCreateArgs(t *testing.T) {
+	t.Parallel()
+	args, err := networkCreateArgs("kind", []string{"172.30.0.0/24", "fd00:30::/64"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assert.DeepEqual(t, []string{"network", "create", "-d=bridge",
+		"--subnet", "172.30.0.0/24", "--ip-range", "172.30.0.128/25",
+		"--ipv6", "--subnet", "fd00:30::/64", "--ip-range", "fd00:30:0:0:8000::/65",
+		"kind",
+	}, args)
+}
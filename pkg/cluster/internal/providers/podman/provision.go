@@ -40,7 +40,10 @@ func planCreation(cfg *config.Cluster, networkName string) (createContainerFuncs
 	nodeNamer := common.MakeNodeNamer(cfg.Name)
 	names := make([]string, len(cfg.Nodes))
 	for i, node := range cfg.Nodes {
-		name := nodeNamer(string(node.Role)) // name the node
+		name := node.Name // nodes restored from a snapshot keep their names
+		if name == "" {
+			name = nodeNamer(string(node.Role)) // name the node
+		}
 		names[i] = name
 	}
 	haveLoadbalancer := config.ClusterHasImplicitLoadBalancer(cfg)
@@ -108,7 +111,10 @@ func planCreation(cfg *config.Cluster, networkName string) (createContainerFuncs
 				if err != nil {
 					return err
 				}
-				return createContainerWithWaitUntilSystemdReachesMultiUserSystem(name, args)
+				if err := createContainerWithWaitUntilSystemdReachesMultiUserSystem(name, args); err != nil {
+					return err
+				}
+				return connectNetworks(name, node.Networks)
 			})
 		case config.WorkerRole:
 			createContainerFuncs = append(createContainerFuncs, func() error {
@@ -116,7 +122,10 @@ func planCreation(cfg *config.Cluster, networkName string) (createContainerFuncs
 				if err != nil {
 					return err
 				}
-				return createContainerWithWaitUntilSystemdReachesMultiUserSystem(name, args)
+				if err := createContainerWithWaitUntilSystemdReachesMultiUserSystem(name, args); err != nil {
+					return err
+				}
+				return connectNetworks(name, node.Networks)
 			})
 		default:
 			return nil, errors.Errorf("unknown node role: %q", node.Role)
@@ -220,6 +229,14 @@ func runArgsForNode(node *config.Node, clusterIPFamily config.ClusterIPFamily, n
 	}
 	args = append(args, mappingArgs...)
 
+	// static addresses on the node network
+	if node.IPv4Address != "" {
+		args = append(args, "--ip", node.IPv4Address)
+	}
+	if node.IPv6Address != "" {
+		args = append(args, "--ip6", node.IPv6Address)
+	}
+
 	switch node.Role {
 	case config.ControlPlaneRole:
 		args = append(args, "-e", "KUBECONFIG=/etc/kubernetes/admin.conf")
@@ -239,21 +256,31 @@ func runArgsForLoadBalancer(cfg *config.Cluster, name string, args []string) ([]
 		args...,
 	)
 
-	// load balancer port mapping
-	mappingArgs, err := generatePortMappings(cfg.Networking.IPFamily,
-		config.PortMapping{
-			ListenAddress: cfg.Networking.APIServerAddress,
-			HostPort:      cfg.Networking.APIServerPort,
-			ContainerPort: common.APIServerInternalPort,
-		},
-	)
+	// load balancer port mappings, and the image which may be configured
+	image := loadbalancer.Image
+	portMappings := []config.PortMapping{{
+		ListenAddress: cfg.Networking.APIServerAddress,
+		HostPort:      cfg.Networking.APIServerPort,
+		ContainerPort: common.APIServerInternalPort,
+	}}
+	if cfg.LoadBalancer != nil {
+		image = cfg.LoadBalancer.Image
+		for _, f := range cfg.LoadBalancer.ExtraFrontends {
+			portMappings = append(portMappings, config.PortMapping{
+				ListenAddress: f.ListenAddress,
+				HostPort:      f.HostPort,
+				ContainerPort: f.Port,
+			})
+		}
+	}
+	mappingArgs, err := generatePortMappings(cfg.Networking.IPFamily, portMappings...)
 	if err != nil {
 		return nil, err
 	}
 	args = append(args, mappingArgs...)
 
 	// finally, specify the image to run
-	_, image := sanitizeImage(loadbalancer.Image)
+	_, image = sanitizeImage(image)
 	return append(args, image), nil
 }
 
@@ -420,6 +447,18 @@ func generatePortMappings(clusterIPFamily config.ClusterIPFamily, portMappings .
 	return args, nil
 }
 
+// containerImage returns the image the container name was created from
+func containerImage(name string) (string, error) {
+	lines, err := exec.OutputLines(exec.Command("podman", "inspect", "--format", "{{.ImageName}}", name))
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get image for %q", name)
+	}
+	if len(lines) != 1 {
+		return "", errors.Errorf("image details should only be one line, got %d lines", len(lines))
+	}
+	return lines[0], nil
+}
+
 func createContainer(name string, args []string) error {
 	return exec.Command("podman", append([]string{"run", "--name", name}, args...)...).Run()
 }
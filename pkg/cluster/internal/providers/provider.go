@@ -29,6 +29,10 @@ type Provider interface {
 	// Provision should create and start the nodes, just short of
 	// actually starting up Kubernetes, based on the given cluster config
 	Provision(status *cli.Status, cfg *config.Cluster) error
+	// ProvisionNode should create and start one more node named name for the
+	// existing cluster cfg.Name, just short of joining it to the cluster.
+	// If node.Image is unset, the image of the existing nodes should be used.
+	ProvisionNode(status *cli.Status, cfg *config.Cluster, node *config.Node, name string) error
 	// ListClusters discovers the clusters that currently have resources
 	// under this providers
 	ListClusters() ([]string, error)
@@ -39,6 +43,40 @@ type Provider interface {
 	// These should be from results previously returned by this provider
 	// E.G. by ListNodes()
 	DeleteNodes([]nodes.Node) error
+	// StopNodes stops the provided list of nodes without deleting them
+	StopNodes([]nodes.Node) error
+	// StartNodes starts the provided list of previously stopped nodes
+	StartNodes([]nodes.Node) error
+	// PauseNodes freezes all processes in the provided list of nodes
+	PauseNodes([]nodes.Node) error
+	// UnpauseNodes resumes the provided list of paused nodes
+	UnpauseNodes([]nodes.Node) error
+	// EnsureRegistry creates the local registry container described by
+	// registry on the node network for the cluster, or reuses an existing
+	// container with the same name. Registries that are not shared are
+	// owned by the cluster.
+	EnsureRegistry(cluster string, registry *config.Registry) error
+	// DeleteRegistry deletes the registry owned by the cluster, if any
+	DeleteRegistry(cluster string) error
+	// DeleteNetworks deletes the additional networks created for the cluster
+	// that are no longer in use
+	DeleteNetworks(cluster string) error
+	// EnsureHelper creates the helper container described by helper on the
+	// node network, owned by cluster, unless a container with the same name
+	// already exists, and returns it. Helpers are deleted with DeleteNodes.
+	EnsureHelper(cluster string, helper *Helper) (nodes.Node, error)
+	// ListHelpers returns the helper containers owned by cluster with the
+	// label key set to value, or all of them if key is empty
+	ListHelpers(cluster, key, value string) ([]nodes.Node, error)
+	// RunImage pulls the node image if necessary and runs a container named
+	// name from it, outside of any cluster, that idles instead of booting so
+	// that the contents of the image can be read by running commands in it.
+	// The container should be deleted with DeleteNodes.
+	RunImage(status *cli.Status, image, name string) (nodes.Node, error)
+	// NodeStatus returns the image, state and published ports of the node
+	NodeStatus(node nodes.Node) (*nodes.Status, error)
+	// GetNetwork returns the network the nodes of cluster are attached to
+	GetNetwork(cluster string) (*NetworkInfo, error)
 	// GetAPIServerEndpoint returns the host endpoint for the cluster's API server
 	GetAPIServerEndpoint(cluster string) (string, error)
 	// GetAPIServerInternalEndpoint returns the internal network endpoint for the cluster's API server
@@ -49,6 +87,28 @@ type Provider interface {
 	Info() (*ProviderInfo, error)
 }
 
+// Helper describes a container owned by a cluster that is not one of its
+// nodes, such as the load balancer of a Service
+type Helper struct {
+	// Name is the name of the container
+	Name string
+	// Image is the image the container runs
+	Image string
+	// Labels are added to the container, in addition to the cluster label
+	Labels map[string]string
+	// PortMappings are the ports published from the container to the host
+	PortMappings []config.PortMapping
+	// IPFamily is the IP family of the cluster, port mappings without a
+	// listen address are published on all the host addresses of the family
+	IPFamily config.ClusterIPFamily
+}
+
+// NetworkInfo describes a network nodes are attached to
+type NetworkInfo struct {
+	Name    string
+	Subnets []string
+}
+
 // ProviderInfo is the info of the provider
 type ProviderInfo struct {
 	Rootless            bool
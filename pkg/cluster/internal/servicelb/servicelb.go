@@ -0,0 +1,310 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package servicelb implements load balancers for the Services of type
+// LoadBalancer of a cluster, each Service gets a haproxy helper container on
+// the node network balancing its ports to the node ports of the Service.
+package servicelb
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/sets"
+	"sigs.k8s.io/kind/pkg/log"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/clusterconfig"
+	"sigs.k8s.io/kind/pkg/cluster/internal/loadbalancer"
+	"sigs.k8s.io/kind/pkg/cluster/internal/portforward"
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers"
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/common"
+)
+
+// helperRole is the helper role label value of the Service load balancers
+const helperRole = "service-load-balancer"
+
+// serviceLabelKey is applied to the load balancer of a Service, the value is
+// the namespace and name of the Service
+const serviceLabelKey = "io.x-k8s.kind.service"
+
+// Serve reconciles the load balancers of the cluster every interval until
+// stop is closed. Failures are logged and retried on the next interval.
+func Serve(logger log.Logger, p providers.Provider, cluster string, interval time.Duration, stop <-chan struct{}) error {
+	logger.V(0).Infof("Serving load balancers for the Services of cluster %q, press Ctrl+C to stop", cluster)
+	for {
+		if err := Reconcile(logger, p, cluster); err != nil {
+			logger.Errorf("Failed to reconcile load balancers: %v", err)
+		}
+		select {
+		case <-stop:
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+// Reconcile ensures a load balancer for each Service of type LoadBalancer of
+// the cluster, and deletes the load balancers of Services that no longer
+// exist or are no longer of type LoadBalancer
+func Reconcile(logger log.Logger, p providers.Provider, cluster string) error {
+	allNodes, err := p.ListNodes(cluster)
+	if err != nil {
+		return err
+	}
+	if len(allNodes) == 0 {
+		return errors.Errorf("unknown cluster %q", cluster)
+	}
+	controlPlane, err := nodeutils.BootstrapControlPlaneNode(allNodes)
+	if err != nil {
+		return err
+	}
+	cfg, err := clusterconfig.Get(p, cluster, allNodes)
+	if err != nil {
+		return err
+	}
+
+	services, err := listServices(controlPlane)
+	if err != nil {
+		return err
+	}
+	backends, err := backendNodes(controlPlane)
+	if err != nil {
+		return err
+	}
+	existing, err := p.ListHelpers(cluster, common.HelperRoleLabelKey, helperRole)
+	if err != nil {
+		return err
+	}
+	existingByName := map[string]nodes.Node{}
+	for _, n := range existing {
+		existingByName[n.String()] = n
+	}
+
+	errs := []error{}
+	wanted := sets.NewString()
+	for i := range services {
+		svc := &services[i]
+		if !svc.needsLoadBalancer() {
+			continue
+		}
+		name := helperName(cluster, svc)
+		wanted.Insert(name)
+		lb := &serviceLoadBalancer{
+			logger:       logger,
+			provider:     p,
+			cluster:      cluster,
+			name:         name,
+			service:      svc,
+			backends:     backends,
+			controlPlane: controlPlane,
+			networking:   &cfg.Networking,
+		}
+		if err := lb.ensure(existingByName[name]); err != nil {
+			errs = append(errs, errors.Wrapf(err, "failed to ensure the load balancer of Service %s", svc.key()))
+		}
+	}
+
+	stale := []nodes.Node{}
+	for _, n := range existing {
+		if !wanted.Has(n.String()) {
+			stale = append(stale, n)
+		}
+	}
+	if len(stale) > 0 {
+		logger.V(0).Infof("Deleting load balancers %v", stale)
+		if err := p.DeleteNodes(stale); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.NewAggregate(errs)
+	}
+	return nil
+}
+
+// List returns the host ports published by the load balancers of the
+// Services of the cluster
+func List(p providers.Provider, cluster string) ([]portforward.Forward, error) {
+	helpers, err := p.ListHelpers(cluster, common.HelperRoleLabelKey, helperRole)
+	if err != nil {
+		return nil, err
+	}
+	forwards := []portforward.Forward{}
+	for _, n := range helpers {
+		status, err := p.NodeStatus(n)
+		if err != nil {
+			return nil, err
+		}
+		for _, port := range status.Ports {
+			forwards = append(forwards, portforward.Forward{
+				ListenAddress: port.ListenAddress,
+				HostPort:      port.HostPort,
+				NodePort:      port.ContainerPort,
+				Protocol:      port.Protocol,
+				Dynamic:       true,
+				Service:       status.Labels[serviceLabelKey],
+			})
+		}
+	}
+	portforward.Sort(forwards)
+	return forwards, nil
+}
+
+// serviceLoadBalancer is the load balancer of a Service
+type serviceLoadBalancer struct {
+	logger       log.Logger
+	provider     providers.Provider
+	cluster      string
+	name         string
+	service      *service
+	backends     []string
+	controlPlane nodes.Node
+	networking   *config.Networking
+}
+
+// ensure creates or updates the load balancer, which is existing if it
+// already exists, and publishes its addresses in the status of the Service
+func (s *serviceLoadBalancer) ensure(existing nodes.Node) error {
+	ports := s.tcpPorts()
+	helper := &providers.Helper{
+		Name:  s.name,
+		Image: loadbalancer.Image,
+		Labels: map[string]string{
+			common.HelperRoleLabelKey: helperRole,
+			serviceLabelKey:           s.service.key(),
+		},
+		IPFamily: s.networking.IPFamily,
+	}
+	for _, port := range ports {
+		helper.PortMappings = append(helper.PortMappings, config.PortMapping{
+			ContainerPort: port.Port,
+			// published at a random port on the same host address as the
+			// API server, see List
+			ListenAddress: s.networking.APIServerAddress,
+			Protocol:      config.PortMappingProtocolTCP,
+		})
+	}
+
+	// the published ports are fixed when the container is created
+	if existing != nil {
+		status, err := s.provider.NodeStatus(existing)
+		if err != nil {
+			return err
+		}
+		if status.State != "running" || !publishesPorts(status, ports) {
+			if err := s.provider.DeleteNodes([]nodes.Node{existing}); err != nil {
+				return err
+			}
+			existing = nil
+		}
+	}
+	if existing == nil {
+		s.logger.V(0).Infof("Creating load balancer %s for Service %s", s.name, s.service.key())
+		for _, port := range s.service.Spec.Ports {
+			if port.Protocol != "" && port.Protocol != "TCP" {
+				s.logger.Warnf("Service %s: ignoring %s port %d, only TCP ports are supported", s.service.key(), port.Protocol, port.Port)
+			}
+		}
+	}
+	lb, err := s.provider.EnsureHelper(s.cluster, helper)
+	if err != nil {
+		return err
+	}
+
+	// update the config if needed, haproxy will reload on SIGHUP
+	lbConfig, err := loadbalancer.ServiceConfig(&loadbalancer.ServiceConfigData{
+		Ports:          ports,
+		BackendServers: s.backends,
+		IPv6:           s.networking.IPFamily == config.IPv6Family,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to generate load balancer config")
+	}
+	current, err := exec.Output(lb.Command("cat", loadbalancer.ConfigPath))
+	if err != nil || string(current) != lbConfig {
+		if err := nodeutils.WriteFile(lb, loadbalancer.ConfigPath, lbConfig); err != nil {
+			return errors.Wrap(err, "failed to copy load balancer config")
+		}
+		if err := lb.Command("kill", "-s", "HUP", "1").Run(); err != nil {
+			return errors.Wrap(err, "failed to reload load balancer")
+		}
+	}
+
+	// publish the addresses of the load balancer on the node network, these
+	// are only reachable from the host where the container runtime runs
+	// natively on Linux, elsewhere the published host ports must be used
+	ipv4, ipv6, err := lb.IP()
+	if err != nil {
+		return errors.Wrap(err, "failed to get load balancer address")
+	}
+	ips := []string{}
+	for _, ip := range []string{ipv4, ipv6} {
+		if ip != "" {
+			ips = append(ips, ip)
+		}
+	}
+	if !s.service.hasIngressIPs(ips) {
+		if err := patchIngressIPs(s.controlPlane, s.service, ips); err != nil {
+			return err
+		}
+		s.logger.V(0).Infof("Service %s has load balancer address %v", s.service.key(), ips)
+	}
+	return nil
+}
+
+// tcpPorts returns the TCP ports of the Service
+func (s *serviceLoadBalancer) tcpPorts() []loadbalancer.ServicePort {
+	ports := []loadbalancer.ServicePort{}
+	for _, port := range s.service.Spec.Ports {
+		if port.Protocol != "" && port.Protocol != "TCP" {
+			continue
+		}
+		ports = append(ports, loadbalancer.ServicePort{
+			Port:                port.Port,
+			NodePort:            port.NodePort,
+			HealthCheckNodePort: s.service.Spec.HealthCheckNodePort,
+		})
+	}
+	return ports
+}
+
+// publishesPorts returns true if status has exactly ports published
+func publishesPorts(status *nodes.Status, ports []loadbalancer.ServicePort) bool {
+	published := sets.NewString()
+	for _, pm := range status.Ports {
+		published.Insert(fmt.Sprintf("%d/%s", pm.ContainerPort, pm.Protocol))
+	}
+	wanted := sets.NewString()
+	for _, port := range ports {
+		wanted.Insert(fmt.Sprintf("%d/tcp", port.Port))
+	}
+	return published.Equal(wanted)
+}
+
+// helperName returns the name of the load balancer of svc, Service names
+// may be too long for container names so a hash of the name is used
+func helperName(cluster string, svc *service) string {
+	sum := sha1.Sum([]byte(svc.key()))
+	return fmt.Sprintf("%s-lb-%s", cluster, hex.EncodeToString(sum[:5]))
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package servicelb
+
+import (
+	"encoding/json"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+	"sigs.k8s.io/kind/pkg/internal/sets"
+)
+
+// service is the subset of a Kubernetes Service used by the load balancers
+type service struct {
+	Metadata struct {
+		Name      string `json:"name"`
+		Namespace string `json:"namespace"`
+	} `json:"metadata"`
+	Spec struct {
+		Type              string  `json:"type"`
+		LoadBalancerClass *string `json:"loadBalancerClass,omitempty"`
+		Ports             []struct {
+			Protocol string `json:"protocol"`
+			Port     int32  `json:"port"`
+			NodePort int32  `json:"nodePort"`
+		} `json:"ports"`
+		HealthCheckNodePort int32 `json:"healthCheckNodePort"`
+	} `json:"spec"`
+	Status struct {
+		LoadBalancer struct {
+			Ingress []struct {
+				IP string `json:"ip"`
+			} `json:"ingress"`
+		} `json:"loadBalancer"`
+	} `json:"status"`
+}
+
+// key returns the namespace and name of the Service
+func (s *service) key() string {
+	return s.Metadata.Namespace + "/" + s.Metadata.Name
+}
+
+// needsLoadBalancer returns true if the Service is of type LoadBalancer and
+// is not implemented by another load balancer class
+func (s *service) needsLoadBalancer() bool {
+	return s.Spec.Type == "LoadBalancer" && s.Spec.LoadBalancerClass == nil
+}
+
+// hasIngressIPs returns true if the status of the Service has exactly ips
+func (s *service) hasIngressIPs(ips []string) bool {
+	current := sets.NewString()
+	for _, ingress := range s.Status.LoadBalancer.Ingress {
+		current.Insert(ingress.IP)
+	}
+	return current.Equal(sets.NewString(ips...))
+}
+
+// listServices returns the Services of every namespace of the cluster
+func listServices(controlPlane nodes.Node) ([]service, error) {
+	out, err := exec.Output(controlPlane.Command(
+		"kubectl", "--kubeconfig=/etc/kubernetes/admin.conf",
+		"get", "services", "--all-namespaces", "-o", "json",
+	))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list Services")
+	}
+	list := struct {
+		Items []service `json:"items"`
+	}{}
+	if err := json.Unmarshal(out, &list); err != nil {
+		return nil, errors.Wrap(err, "failed to parse Services")
+	}
+	return list.Items, nil
+}
+
+// backendNodes returns the names of the nodes the load balancers balance to,
+// nodes excluded from external load balancers by kubeadm are skipped
+func backendNodes(controlPlane nodes.Node) ([]string, error) {
+	out, err := exec.Output(controlPlane.Command(
+		"kubectl", "--kubeconfig=/etc/kubernetes/admin.conf",
+		"get", "nodes", "--selector=!node.kubernetes.io/exclude-from-external-load-balancers",
+		"-o", "jsonpath={.items[*].metadata.name}",
+	))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list nodes")
+	}
+	return strings.Fields(string(out)), nil
+}
+
+// patchIngressIPs sets the ingress addresses in the status of svc to ips
+func patchIngressIPs(controlPlane nodes.Node, svc *service, ips []string) error {
+	ingress := []map[string]string{}
+	for _, ip := range ips {
+		ingress = append(ingress, map[string]string{"ip": ip})
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"status": map[string]interface{}{
+			"loadBalancer": map[string]interface{}{
+				"ingress": ingress,
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if err := controlPlane.Command(
+		"kubectl", "--kubeconfig=/etc/kubernetes/admin.conf",
+		"patch", "service", "--namespace", svc.Metadata.Namespace, svc.Metadata.Name,
+		"--subresource=status", "--type=merge", "--patch", string(patch),
+	).Run(); err != nil {
+		return errors.Wrapf(err, "failed to update the status of Service %s", svc.key())
+	}
+	return nil
+}
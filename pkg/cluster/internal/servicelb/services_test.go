@@ -0,0 +1,79 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package servicelb
+
+import (
+	"encoding/json"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/loadbalancer"
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+const servicesJSON = `{"items": [
+  {
+    "metadata": {"name": "foo", "namespace": "default"},
+    "spec": {
+      "type": "LoadBalancer",
+      "ports": [
+        {"protocol": "TCP", "port": 80, "nodePort": 30080},
+        {"protocol": "UDP", "port": 53, "nodePort": 30053}
+      ],
+      "healthCheckNodePort": 32000
+    },
+    "status": {"loadBalancer": {"ingress": [{"ip": "172.18.0.5"}]}}
+  },
+  {
+    "metadata": {"name": "bar", "namespace": "default"},
+    "spec": {"type": "LoadBalancer", "loadBalancerClass": "example.com/lb"}
+  },
+  {
+    "metadata": {"name": "kubernetes", "namespace": "default"},
+    "spec": {"type": "ClusterIP"}
+  }
+]}`
+
+func TestServices(t *testing.T) {
+	t.Parallel()
+	list := struct {
+		Items []service `json:"items"`
+	}{}
+	if err := json.Unmarshal([]byte(servicesJSON), &list); err != nil {
+		t.Fatalf("failed to parse Services: %v", err)
+	}
+	foo, bar, kubernetes := &list.Items[0], &list.Items[1], &list.Items[2]
+
+	assert.StringEqual(t, "default/foo", foo.key())
+	assert.BoolEqual(t, true, foo.needsLoadBalancer())
+	assert.BoolEqual(t, false, bar.needsLoadBalancer())
+	assert.BoolEqual(t, false, kubernetes.needsLoadBalancer())
+
+	assert.BoolEqual(t, true, foo.hasIngressIPs([]string{"172.18.0.5"}))
+	assert.BoolEqual(t, false, foo.hasIngressIPs([]string{"172.18.0.5", "fc00:f853:ccd:e793::5"}))
+	assert.BoolEqual(t, false, bar.hasIngressIPs([]string{"172.18.0.6"}))
+
+	lb := &serviceLoadBalancer{service: foo}
+	ports := lb.tcpPorts()
+	assert.DeepEqual(t, []loadbalancer.ServicePort{
+		{Port: 80, NodePort: 30080, HealthCheckNodePort: 32000},
+	}, ports)
+	assert.BoolEqual(t, true, publishesPorts(&nodes.Status{
+		Ports: []nodes.PortMapping{{ContainerPort: 80, HostPort: 41234, ListenAddress: "127.0.0.1", Protocol: "tcp"}},
+	}, ports))
+	assert.BoolEqual(t, false, publishesPorts(&nodes.Status{}, ports))
+}
@@ -0,0 +1,165 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package snapshot
+
+import (
+	"os"
+	"path/filepath"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/internal/cli"
+	"sigs.k8s.io/kind/pkg/log"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/clusterconfig"
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers"
+)
+
+// Create writes a snapshot of the cluster name to path.
+//
+// Kubernetes is briefly stopped on all nodes while their state is archived
+// so that etcd and containerd are not written to mid-snapshot, the
+// cluster keeps running afterwards.
+func Create(logger log.Logger, p providers.Provider, name, path string) error {
+	allNodes, err := p.ListNodes(name)
+	if err != nil {
+		return errors.Wrap(err, "error listing nodes")
+	}
+	if len(allNodes) == 0 {
+		return errors.Errorf("unknown cluster %q", name)
+	}
+	cfg, err := clusterconfig.Get(p, name, allNodes)
+	if err != nil {
+		return err
+	}
+	internalNodes, err := nodeutils.InternalNodes(allNodes)
+	if err != nil {
+		return err
+	}
+	for _, auth := range cfg.RegistryAuth {
+		if auth.CredentialsFile != "" {
+			logger.Warnf("The snapshot contains the credentials for registry %s, keep it private", auth.Host)
+		}
+	}
+
+	dir, err := os.MkdirTemp("", "kind-snapshot-")
+	if err != nil {
+		return errors.Wrap(err, "failed to create temporary directory")
+	}
+	defer os.RemoveAll(dir)
+
+	status := cli.StatusForLogger(logger)
+	status.Start("Stopping Kubernetes 🛑")
+	err = errors.UntilErrorConcurrent(nodeFuncs(internalNodes, stopKubernetes))
+	status.End(err == nil)
+	if err == nil {
+		status.Start("Archiving node state 📦")
+		err = archiveNodes(internalNodes, dir)
+		if err == nil {
+			err = write(path, cfg, dir, nodeNames(internalNodes))
+		}
+		status.End(err == nil)
+	}
+
+	// always try to bring the cluster back up, even if archiving failed
+	status.Start("Starting Kubernetes 🚀")
+	startErr := errors.AggregateConcurrent(nodeFuncs(internalNodes, startKubernetes))
+	status.End(startErr == nil)
+	if err != nil {
+		return err
+	}
+	if startErr != nil {
+		return startErr
+	}
+
+	logger.V(0).Infof("Saved snapshot of cluster %q to %s", name, path)
+	return nil
+}
+
+func stopKubernetes(node nodes.Node) error {
+	if err := node.Command("systemctl", "stop", "kubelet").Run(); err != nil {
+		return errors.Wrapf(err, "failed to stop kubelet on node %q", node.String())
+	}
+	// the kubelet leaves its containers running, stop them so that etcd
+	// data is consistent on disk
+	if err := node.Command(
+		"sh", "-c", "crictl ps --quiet | xargs --no-run-if-empty crictl stop",
+	).Run(); err != nil {
+		return errors.Wrapf(err, "failed to stop containers on node %q", node.String())
+	}
+	return errors.Wrapf(node.Command("sync").Run(), "failed to sync node %q", node.String())
+}
+
+func startKubernetes(node nodes.Node) error {
+	return errors.Wrapf(
+		node.Command("systemctl", "start", "kubelet").Run(),
+		"failed to start kubelet on node %q", node.String(),
+	)
+}
+
+// archiveNodes writes an archive of the state of each node to dir
+func archiveNodes(n []nodes.Node, dir string) error {
+	return errors.UntilErrorConcurrent(nodeFuncs(n, func(node nodes.Node) error {
+		return archiveNode(node, filepath.Join(dir, node.String()+nodeEntrySuffix))
+	}))
+}
+
+// archiveNode writes an archive of the state of node to path
+func archiveNode(node nodes.Node, path string) (err error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return errors.Wrap(err, "failed to create node archive")
+	}
+	defer func() {
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+	args := []string{
+		"--create", "--numeric-owner", "--xattrs", "--directory=/",
+		// skip mounts within the state, e.g. pod volumes and
+		// container root filesystems
+		"--one-file-system",
+		// /kind/old-ipv6 only exists on nodes with an IPv6 address
+		"--ignore-failed-read",
+	}
+	args = append(args, statePaths...)
+	if err := node.Command("tar", args...).SetStdout(f).Run(); err != nil {
+		return errors.Wrapf(err, "failed to archive node %q", node.String())
+	}
+	return nil
+}
+
+func nodeNames(n []nodes.Node) []string {
+	names := make([]string, len(n))
+	for i, node := range n {
+		names[i] = node.String()
+	}
+	return names
+}
+
+func nodeFuncs(n []nodes.Node, fn func(nodes.Node) error) []func() error {
+	fns := make([]func() error, len(n))
+	for i := range n {
+		node := n[i]
+		fns[i] = func() error {
+			return fn(node)
+		}
+	}
+	return fns
+}
@@ -0,0 +1,260 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package snapshot implements saving the state of the nodes of an existing
+// cluster to a single archive and restoring nodes from it
+package snapshot
+
+import (
+	"archive/tar"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/apis/config/encoding"
+	"sigs.k8s.io/kind/pkg/internal/sets"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/clusterconfig"
+)
+
+// A snapshot is an uncompressed tar archive containing:
+//
+//	cluster.yaml     the cluster config, at the current public API version
+//	node-names       the names of the nodes of the config entries, in order
+//	nodes/<name>.tar an archive of the state of node <name>, relative to /
+const (
+	configEntry     = "cluster.yaml"
+	nodeNamesEntry  = "node-names"
+	nodeEntryPrefix = "nodes/"
+	nodeEntrySuffix = ".tar"
+)
+
+// statePaths are the paths on each node, relative to /, that hold the
+// state of the cluster: the /var volume (etcd data, kubelet state and
+// containerd images) plus the kubernetes and kind configuration.
+// These include the cluster CA keys and any registry credentials.
+// /kind/old-ipv4 and /kind/old-ipv6 are included so that the node
+// entrypoint rewrites the old node addresses when the node next boots.
+var statePaths = []string{
+	"var",
+	"etc/kubernetes",
+	"etc/containerd",
+	"kind/kubeadm.conf",
+	"kind/old-ipv4",
+	"kind/old-ipv6",
+}
+
+// Snapshot is a cluster snapshot on disk
+type Snapshot struct {
+	// Config is the cluster config recorded in the snapshot
+	Config *config.Cluster
+	// Nodes are the names of the nodes with recorded state
+	Nodes []string
+
+	path string
+}
+
+// Open reads the snapshot at path and checks that it has the state of
+// every node of Config, which are named as the nodes they were recorded from
+func Open(path string) (*Snapshot, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open snapshot")
+	}
+	defer f.Close()
+
+	s := &Snapshot{path: path}
+	nodeNames := ""
+	tr := tar.NewReader(f)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read snapshot")
+		}
+		switch {
+		case hdr.Name == configEntry:
+			raw, err := io.ReadAll(tr)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to read snapshot config")
+			}
+			if s.Config, err = encoding.Parse(raw); err != nil {
+				return nil, errors.Wrap(err, "failed to parse snapshot config")
+			}
+		case hdr.Name == nodeNamesEntry:
+			raw, err := io.ReadAll(tr)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to read snapshot node names")
+			}
+			nodeNames = string(raw)
+		case strings.HasPrefix(hdr.Name, nodeEntryPrefix) && strings.HasSuffix(hdr.Name, nodeEntrySuffix):
+			name := strings.TrimSuffix(strings.TrimPrefix(hdr.Name, nodeEntryPrefix), nodeEntrySuffix)
+			s.Nodes = append(s.Nodes, name)
+		}
+	}
+	if s.Config == nil {
+		return nil, errors.Errorf("%q is not a cluster snapshot, missing %s", path, configEntry)
+	}
+	if err := clusterconfig.DecodeNodeNames(s.Config, nodeNames); err != nil {
+		return nil, errors.Wrap(err, "invalid snapshot")
+	}
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// validate checks that the nodes of s.Config are exactly the recorded nodes
+func (s *Snapshot) validate() error {
+	expected := sets.NewString()
+	for _, node := range s.Config.Nodes {
+		expected.Insert(node.Name)
+	}
+	recorded := sets.NewString(s.Nodes...)
+	if len(s.Config.Nodes) != expected.Len() || !expected.Equal(recorded) {
+		return errors.Errorf(
+			"snapshot nodes %v do not match the nodes of the snapshot config %v",
+			recorded.List(), expected.List(),
+		)
+	}
+	return nil
+}
+
+// RestoreNode replaces the state of node with the state recorded for the
+// node with the same name. The node should not be running Kubernetes yet.
+func (s *Snapshot) RestoreNode(node nodes.Node) error {
+	f, err := os.Open(s.path)
+	if err != nil {
+		return errors.Wrap(err, "failed to open snapshot")
+	}
+	defer f.Close()
+
+	entry := nodeEntryPrefix + node.String() + nodeEntrySuffix
+	tr := tar.NewReader(f)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return errors.Errorf("snapshot has no state for node %q", node.String())
+		}
+		if err != nil {
+			return errors.Wrap(err, "failed to read snapshot")
+		}
+		if hdr.Name == entry {
+			break
+		}
+	}
+
+	// nothing may be writing to the state we are about to replace
+	if err := node.Command("systemctl", "stop", "kubelet", "containerd").Run(); err != nil {
+		return errors.Wrapf(err, "failed to stop services on node %q", node.String())
+	}
+	rmArgs := []string{"-rf"}
+	for _, p := range []string{"var/lib/containerd", "var/lib/kubelet", "var/lib/etcd", "etc/kubernetes"} {
+		rmArgs = append(rmArgs, "/"+p)
+	}
+	if err := node.Command("rm", rmArgs...).Run(); err != nil {
+		return errors.Wrapf(err, "failed to clear state on node %q", node.String())
+	}
+	if err := node.Command(
+		"tar", "--extract", "--numeric-owner", "--xattrs", "--directory=/",
+	).SetStdin(tr).Run(); err != nil {
+		return errors.Wrapf(err, "failed to restore state on node %q", node.String())
+	}
+	return nil
+}
+
+// write writes a snapshot of cfg to path, with the node state archives
+// previously saved under dir as <node name>.tar. The snapshot holds the
+// secrets of the cluster, so it is only readable by the user.
+func write(path string, cfg *config.Cluster, dir string, nodeNames []string) (err error) {
+	raw, err := encoding.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+	names, err := clusterconfig.EncodeNodeNames(cfg)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return errors.Wrap(err, "failed to create snapshot file")
+	}
+	defer func() {
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	tw := tar.NewWriter(f)
+	for _, entry := range []struct {
+		name     string
+		contents []byte
+	}{
+		{configEntry, raw},
+		{nodeNamesEntry, []byte(names)},
+	} {
+		if err := tw.WriteHeader(&tar.Header{
+			Name: entry.name,
+			Mode: 0644,
+			Size: int64(len(entry.contents)),
+		}); err != nil {
+			return errors.Wrap(err, "failed to write snapshot")
+		}
+		if _, err := tw.Write(entry.contents); err != nil {
+			return errors.Wrap(err, "failed to write snapshot")
+		}
+	}
+
+	sort.Strings(nodeNames)
+	for _, name := range nodeNames {
+		if err := writeFile(tw, nodeEntryPrefix+name+nodeEntrySuffix, filepath.Join(dir, name+nodeEntrySuffix)); err != nil {
+			return err
+		}
+	}
+	return errors.Wrap(tw.Close(), "failed to write snapshot")
+}
+
+// writeFile adds the file at path to tw as name
+func writeFile(tw *tar.Writer, name, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return errors.Wrap(err, "failed to open node archive")
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return errors.Wrap(err, "failed to stat node archive")
+	}
+	if err := tw.WriteHeader(&tar.Header{
+		Name: name,
+		Mode: 0644,
+		Size: info.Size(),
+	}); err != nil {
+		return errors.Wrap(err, "failed to write snapshot")
+	}
+	if _, err := io.Copy(tw, f); err != nil {
+		return errors.Wrap(err, "failed to write snapshot")
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package snapshot
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+func TestWriteOpen(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name        string
+		NodeNames   []string
+		Nodes       []string
+		ExpectError bool
+	}{
+		{
+			Name:      "nodes match config",
+			NodeNames: []string{"kind-control-plane", "kind-worker", "kind-worker2"},
+			Nodes:     []string{"kind-control-plane", "kind-worker", "kind-worker2"},
+		},
+		{
+			Name:      "deleted and explicitly named workers",
+			NodeNames: []string{"kind-control-plane", "kind-worker3", "custom"},
+			Nodes:     []string{"custom", "kind-control-plane", "kind-worker3"},
+		},
+		{
+			Name:        "missing node",
+			NodeNames:   []string{"kind-control-plane", "kind-worker", "kind-worker2"},
+			Nodes:       []string{"kind-control-plane", "kind-worker"},
+			ExpectError: true,
+		},
+		{
+			Name:        "unexpected node",
+			NodeNames:   []string{"kind-control-plane", "kind-worker", "kind-worker2"},
+			Nodes:       []string{"kind-control-plane", "kind-worker", "kind-worker3"},
+			ExpectError: true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			dir := t.TempDir()
+			for _, name := range tc.Nodes {
+				if err := os.WriteFile(filepath.Join(dir, name+nodeEntrySuffix), []byte(name), 0644); err != nil {
+					t.Fatalf("failed to write node archive: %v", err)
+				}
+			}
+			cfg := &config.Cluster{
+				Name: "kind",
+				Nodes: []config.Node{
+					{Role: config.ControlPlaneRole, Image: "kindest/node:test"},
+					{Role: config.WorkerRole, Image: "kindest/node:test"},
+					{Role: config.WorkerRole, Image: "kindest/node:test"},
+				},
+			}
+			config.SetDefaultsCluster(cfg)
+			for i, name := range tc.NodeNames {
+				cfg.Nodes[i].Name = name
+			}
+			path := filepath.Join(dir, "snapshot.tar")
+			if err := write(path, cfg, dir, tc.Nodes); err != nil {
+				t.Fatalf("unexpected error writing snapshot: %v", err)
+			}
+			if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+				t.Errorf("expected snapshot to only be readable by the user")
+			}
+
+			s, err := Open(path)
+			if err != nil {
+				if !tc.ExpectError {
+					t.Fatalf("unexpected error opening snapshot: %v", err)
+				}
+				return
+			}
+			if tc.ExpectError {
+				t.Fatalf("expected error opening snapshot")
+			}
+			if s.Config.Name != cfg.Name || !reflect.DeepEqual(s.Config.Networking, cfg.Networking) {
+				t.Errorf("expected config %+v but got %+v", cfg, s.Config)
+			}
+			for i := range cfg.Nodes {
+				if s.Config.Nodes[i].Name != cfg.Nodes[i].Name || s.Config.Nodes[i].Role != cfg.Nodes[i].Role || s.Config.Nodes[i].Image != cfg.Nodes[i].Image {
+					t.Errorf("expected node %+v but got %+v", cfg.Nodes[i], s.Config.Nodes[i])
+				}
+			}
+			if !reflect.DeepEqual(s.Nodes, tc.Nodes) {
+				t.Errorf("expected nodes %v but got %v", tc.Nodes, s.Nodes)
+			}
+		})
+	}
+}
@@ -0,0 +1,307 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package upgrade implements upgrading the Kubernetes version of existing
+// clusters in place
+package upgrade
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/cluster/constants"
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/cli"
+	"sigs.k8s.io/kind/pkg/internal/version"
+	"sigs.k8s.io/kind/pkg/log"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/clusterconfig"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/waitforready"
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers"
+)
+
+// nodePaths are the paths in the node image, relative to /, that are
+// copied to the nodes of the cluster: the Kubernetes binaries and the
+// version file read by nodeutils.KubeVersion
+var nodePaths = []string{
+	"usr/bin/kubeadm",
+	"usr/bin/kubelet",
+	"usr/bin/kubectl",
+	"kind/version",
+}
+
+// Options holds options for upgrading a cluster
+type Options struct {
+	// Image is the node image to upgrade to
+	Image        string
+	WaitForReady time.Duration
+}
+
+// Cluster upgrades the running cluster name to the Kubernetes version of
+// the node image opts.Image.
+// The binaries and images from the node image are loaded into the existing
+// nodes, which are then upgraded with kubeadm one at a time, control plane
+// nodes first. The node containers keep running on their original image.
+func Cluster(logger log.Logger, p providers.Provider, name string, opts *Options) error {
+	allNodes, err := p.ListNodes(name)
+	if err != nil {
+		return errors.Wrap(err, "error listing nodes")
+	}
+	if len(allNodes) == 0 {
+		return errors.Errorf("unknown cluster %q", name)
+	}
+	ordered, err := upgradeOrder(allNodes)
+	if err != nil {
+		return err
+	}
+	currentVersion, err := nodeutils.KubeVersion(ordered[0])
+	if err != nil {
+		return errors.Wrap(err, "failed to get kubernetes version from node")
+	}
+	cfg, err := clusterconfig.Get(p, name, allNodes)
+	if err != nil {
+		return err
+	}
+
+	status := cli.StatusForLogger(logger)
+	logger.V(0).Infof("Upgrading cluster %q to %s ...\n", name, opts.Image)
+
+	// read the new version and images from a container running the image
+	random := rand.New(rand.NewSource(time.Now().UnixNano())).Int31()
+	image, err := p.RunImage(status, opts.Image, fmt.Sprintf("kind-upgrade-%d-%d", time.Now().UTC().Unix(), random))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = p.DeleteNodes([]nodes.Node{image}) }()
+	targetVersion, err := nodeutils.KubeVersion(image)
+	if err != nil {
+		return errors.Wrap(err, "failed to get kubernetes version from node image")
+	}
+	if err := checkVersions(currentVersion, targetVersion); err != nil {
+		return err
+	}
+	logger.V(0).Infof("Upgrading Kubernetes from %s to %s ...\n", currentVersion, targetVersion)
+
+	status.Start("Preparing node image 📦")
+	images, err := exportImages(image)
+	if err != nil {
+		status.End(false)
+		return err
+	}
+	defer os.Remove(images)
+	status.End(true)
+
+	for i, node := range ordered {
+		status.Start(fmt.Sprintf("Upgrading node %s ⏫", node.String()))
+		if err := upgradeNode(logger, image, ordered[0], node, images, targetVersion, i == 0); err != nil {
+			status.End(false)
+			return errors.Wrapf(err, "failed to upgrade node %q", node.String())
+		}
+		status.End(true)
+	}
+
+	// record the new image for the nodes in the persisted config, new
+	// nodes are created from it
+	cfg = upgradedConfig(cfg, opts.Image)
+	internalNodes, err := nodeutils.InternalNodes(allNodes)
+	if err != nil {
+		return err
+	}
+	if err := clusterconfig.Write(internalNodes, cfg); err != nil {
+		return err
+	}
+
+	ctx := actions.NewActionContext(logger, status, p, cfg)
+	return waitforready.NewAction(opts.WaitForReady).Execute(ctx)
+}
+
+// upgradeOrder returns the internal nodes of allNodes in the order they
+// must be upgraded: the bootstrap control plane node, the other control
+// plane nodes and then the workers
+func upgradeOrder(allNodes []nodes.Node) ([]nodes.Node, error) {
+	bootstrap, err := nodeutils.BootstrapControlPlaneNode(allNodes)
+	if err != nil {
+		return nil, err
+	}
+	secondary, err := nodeutils.SecondaryControlPlaneNodes(allNodes)
+	if err != nil {
+		return nil, err
+	}
+	workers, err := nodeutils.SelectNodesByRole(allNodes, constants.WorkerNodeRoleValue)
+	if err != nil {
+		return nil, err
+	}
+	ordered := append([]nodes.Node{bootstrap}, secondary...)
+	return append(ordered, workers...), nil
+}
+
+// upgradedConfig returns a copy of cfg with the nodes' image set to image
+func upgradedConfig(cfg *config.Cluster, image string) *config.Cluster {
+	upgraded := cfg.DeepCopy()
+	for i := range upgraded.Nodes {
+		upgraded.Nodes[i].Image = image
+	}
+	return upgraded
+}
+
+// checkVersions checks that a cluster at version current may be upgraded
+// to version target, following the kubeadm version skew policy
+func checkVersions(current, target string) error {
+	c, err := version.ParseSemantic(current)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse current kubernetes version %q", current)
+	}
+	t, err := version.ParseSemantic(target)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse target kubernetes version %q", target)
+	}
+	if !c.LessThan(t) {
+		return errors.Errorf("cannot upgrade from %s to %s, the node image must have a newer Kubernetes version", current, target)
+	}
+	if t.Major() != c.Major() || t.Minor() > c.Minor()+1 {
+		return errors.Errorf("cannot upgrade from %s to %s, kubeadm only supports upgrading one minor version at a time", current, target)
+	}
+	return nil
+}
+
+// exportImages exports the images in the node image running as image to a
+// temporary file on the host, returning its path
+func exportImages(image nodes.Node) (path string, err error) {
+	if err := image.Command("bash", "-c", "nohup containerd > /dev/null 2>&1 &").Run(); err != nil {
+		return "", errors.Wrap(err, "failed to start containerd")
+	}
+	// ctr doesn't respect timeouts when the socket doesn't exist
+	if err := image.Command("bash", "-c", `set -e
+for i in {0..3}; do
+  if [ -S /run/containerd/containerd.sock ]; then
+    break
+  fi
+  sleep "$i"
+done
+ctr info
+`).Run(); err != nil {
+		return "", errors.Wrap(err, "failed to wait for containerd to become ready")
+	}
+	names, err := exec.OutputLines(image.Command("ctr", "--namespace=k8s.io", "images", "list", "-q"))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to list images")
+	}
+
+	f, err := os.CreateTemp("", "kind-upgrade-images-")
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create temporary file")
+	}
+	defer func() {
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(f.Name())
+		}
+	}()
+	args := append([]string{"--namespace=k8s.io", "images", "export", "-"}, names...)
+	if err := image.Command("ctr", args...).SetStdout(f).Run(); err != nil {
+		return "", errors.Wrap(err, "failed to export images")
+	}
+	return f.Name(), nil
+}
+
+// upgradeNode loads the binaries from the node image running as image and
+// the exported images into node and upgrades it to kubeVersion with kubeadm.
+// The first control plane node upgraded applies the cluster wide upgrade.
+// Workers are drained with kubectl on controlPlane during the upgrade.
+func upgradeNode(logger log.Logger, image, controlPlane, node nodes.Node, images, kubeVersion string, first bool) (err error) {
+	role, err := node.Role()
+	if err != nil {
+		return err
+	}
+	if role == constants.WorkerNodeRoleValue {
+		if err := kubectl(logger, controlPlane,
+			"drain", node.String(), "--ignore-daemonsets", "--delete-emptydir-data", "--force", "--timeout=2m",
+		); err != nil {
+			return errors.Wrap(err, "failed to drain node")
+		}
+		// the node is usable again even if the upgrade fails
+		defer func() {
+			if uncordonErr := kubectl(logger, controlPlane, "uncordon", node.String()); err == nil && uncordonErr != nil {
+				err = errors.Wrap(uncordonErr, "failed to uncordon node")
+			}
+		}()
+	}
+
+	// the running kubelet binary can only be replaced by a new file
+	pr, pw := io.Pipe()
+	copyErr := make(chan error, 1)
+	go func() {
+		err := image.Command("tar", append([]string{"-C", "/", "-cf", "-"}, nodePaths...)...).SetStdout(pw).Run()
+		pw.CloseWithError(err)
+		copyErr <- err
+	}()
+	if err := node.Command("tar", "-C", "/", "--unlink-first", "-xf", "-").SetStdin(pr).Run(); err != nil {
+		pr.CloseWithError(err)
+		<-copyErr
+		return errors.Wrap(err, "failed to copy binaries")
+	}
+	if err := <-copyErr; err != nil {
+		return errors.Wrap(err, "failed to read binaries from node image")
+	}
+
+	f, err := os.Open(images)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := nodeutils.LoadImageArchive(node, f); err != nil {
+		return errors.Wrap(err, "failed to load images")
+	}
+
+	args := []string{"upgrade", "node", "--v=6"}
+	if first {
+		args = []string{"upgrade", "apply", kubeVersion, "--yes", "--v=6"}
+		// node images are often built from unreleased Kubernetes versions
+		if strings.Contains(kubeVersion, "-") {
+			args = append(args, "--allow-experimental-upgrades", "--allow-release-candidate-upgrades")
+		}
+	}
+	lines, err := exec.CombinedOutputLines(node.Command("kubeadm", args...))
+	logger.V(3).Info(strings.Join(lines, "\n"))
+	if err != nil {
+		return errors.Wrap(err, "failed to upgrade with kubeadm")
+	}
+
+	lines, err = exec.CombinedOutputLines(node.Command(
+		"bash", "-c", "systemctl daemon-reload && systemctl restart kubelet",
+	))
+	logger.V(3).Info(strings.Join(lines, "\n"))
+	return errors.Wrap(err, "failed to restart kubelet")
+}
+
+func kubectl(logger log.Logger, controlPlane nodes.Node, args ...string) error {
+	lines, err := exec.CombinedOutputLines(controlPlane.Command(
+		"kubectl", append([]string{"--kubeconfig=/etc/kubernetes/admin.conf"}, args...)...,
+	))
+	logger.V(3).Info(strings.Join(lines, "\n"))
+	return err
+}
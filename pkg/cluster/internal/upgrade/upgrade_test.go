@@ -0,0 +1,139 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package upgrade
+
+import (
+	"io"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/cluster/constants"
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/exec"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+// fakeNode is a nodes.Node with a name and role, it cannot run commands
+type fakeNode struct {
+	exec.Cmder
+	name string
+	role string
+}
+
+func (n *fakeNode) String() string                    { return n.name }
+func (n *fakeNode) Role() (string, error)             { return n.role, nil }
+func (n *fakeNode) IP() (string, string, error)       { return "", "", nil }
+func (n *fakeNode) SerialLogs(writer io.Writer) error { return nil }
+
+func TestUpgradeOrder(t *testing.T) {
+	t.Parallel()
+	allNodes := []nodes.Node{
+		&fakeNode{name: "kind-worker2", role: constants.WorkerNodeRoleValue},
+		&fakeNode{name: "kind-external-load-balancer", role: constants.ExternalLoadBalancerNodeRoleValue},
+		&fakeNode{name: "kind-control-plane2", role: constants.ControlPlaneNodeRoleValue},
+		&fakeNode{name: "kind-worker", role: constants.WorkerNodeRoleValue},
+		&fakeNode{name: "kind-control-plane", role: constants.ControlPlaneNodeRoleValue},
+	}
+	ordered, err := upgradeOrder(allNodes)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	names := []string{}
+	for _, n := range ordered {
+		names = append(names, n.String())
+	}
+	assert.DeepEqual(t, []string{"kind-control-plane", "kind-control-plane2", "kind-worker2", "kind-worker"}, names)
+
+	_, err = upgradeOrder([]nodes.Node{&fakeNode{name: "kind-worker", role: constants.WorkerNodeRoleValue}})
+	assert.ExpectError(t, true, err)
+}
+
+func TestUpgradedConfig(t *testing.T) {
+	t.Parallel()
+	cfg := &config.Cluster{
+		Name: "kind",
+		Nodes: []config.Node{
+			{Name: "kind-control-plane", Role: config.ControlPlaneRole, Image: "kindest/node:v1.30.0"},
+			{Name: "custom", Role: config.WorkerRole, Image: "kindest/node:v1.30.0", Labels: map[string]string{"a": "b"}},
+		},
+	}
+	upgraded := upgradedConfig(cfg, "kindest/node:v1.31.0")
+	assert.DeepEqual(t, []config.Node{
+		{Name: "kind-control-plane", Role: config.ControlPlaneRole, Image: "kindest/node:v1.31.0"},
+		{Name: "custom", Role: config.WorkerRole, Image: "kindest/node:v1.31.0", Labels: map[string]string{"a": "b"}},
+	}, upgraded.Nodes)
+	// the original config is not modified
+	assert.StringEqual(t, "kindest/node:v1.30.0", cfg.Nodes[0].Image)
+}
+
+func TestCheckVersions(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name        string
+		Current     string
+		Target      string
+		ExpectError bool
+	}{
+		{
+			Name:    "patch upgrade",
+			Current: "v1.30.0",
+			Target:  "v1.30.2",
+		},
+		{
+			Name:    "minor upgrade",
+			Current: "v1.30.2",
+			Target:  "v1.31.0",
+		},
+		{
+			Name:    "pre-release upgrade",
+			Current: "v1.31.0",
+			Target:  "v1.32.0-alpha.1.123+0123456789abcd",
+		},
+		{
+			Name:        "same version",
+			Current:     "v1.31.0",
+			Target:      "v1.31.0",
+			ExpectError: true,
+		},
+		{
+			Name:        "downgrade",
+			Current:     "v1.31.0",
+			Target:      "v1.30.4",
+			ExpectError: true,
+		},
+		{
+			Name:        "skipping a minor version",
+			Current:     "v1.29.0",
+			Target:      "v1.31.0",
+			ExpectError: true,
+		},
+		{
+			Name:        "invalid version",
+			Current:     "v1.31.0",
+			Target:      "latest",
+			ExpectError: true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			err := checkVersions(tc.Current, tc.Target)
+			assert.ExpectError(t, tc.ExpectError, err)
+		})
+	}
+}
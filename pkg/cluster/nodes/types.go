@@ -38,3 +38,28 @@ type Node interface {
 	// SerialLogs collects the "node" container logs
 	SerialLogs(writer io.Writer) error
 }
+
+// Status is the status of a node as reported by its provider
+type Status struct {
+	// Image is the image the node was created from
+	Image string
+	// State is the state of the node container, e.g. "running", "exited"
+	// or "paused", as reported by the container runtime
+	State string
+	// Ports are the ports published from the node to the host
+	Ports []PortMapping
+	// Labels are the labels of the node container
+	Labels map[string]string
+}
+
+// PortMapping is a port published from a node to the host
+type PortMapping struct {
+	// ContainerPort is the port within the node
+	ContainerPort int32
+	// HostPort is the port on the host
+	HostPort int32
+	// ListenAddress is the host address the port is published on
+	ListenAddress string
+	// Protocol is the port protocol, e.g. "tcp"
+	Protocol string
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"sigs.k8s.io/kind/pkg/cluster/internal/portforward"
+	"sigs.k8s.io/kind/pkg/cluster/internal/servicelb"
+)
+
+// PortForward is a port on the host forwarded to a port of a node
+type PortForward struct {
+	// Node is the name of the node, defaults to the bootstrap control plane
+	// node for Expose
+	Node          string `json:"node"`
+	ListenAddress string `json:"listenAddress"`
+	HostPort      int32  `json:"hostPort"`
+	NodePort      int32  `json:"nodePort"`
+	Protocol      string `json:"protocol"`
+	// Dynamic is true for ports forwarded with Expose, and false for ports
+	// published when the node was created
+	Dynamic bool `json:"dynamic"`
+	// Service is the namespace and name of the Service for the ports of the
+	// Service load balancers of `kind serve loadbalancers`, NodePort is then
+	// the Service port and Node is unset
+	Service string `json:"service,omitempty"`
+}
+
+// Expose forwards forward.HostPort on the host to forward.NodePort of a node
+// of the running cluster name, until Unexpose is called or the cluster is
+// deleted. Only tcp ports can be forwarded.
+func (p *Provider) Expose(name string, forward *PortForward) error {
+	return portforward.Expose(p.logger, p.provider, defaultName(name), &portforward.Forward{
+		Node:          forward.Node,
+		ListenAddress: forward.ListenAddress,
+		HostPort:      forward.HostPort,
+		NodePort:      forward.NodePort,
+		Protocol:      forward.Protocol,
+	})
+}
+
+// Unexpose stops forwarding hostPort forwarded with Expose to the cluster name
+func (p *Provider) Unexpose(name string, hostPort int32) error {
+	return portforward.Unexpose(p.logger, p.provider, defaultName(name), hostPort)
+}
+
+// ListPorts returns the host ports forwarded to the nodes of the cluster name,
+// the ports published when the nodes were created, the ports forwarded with
+// Expose and the ports of the Service load balancers
+func (p *Provider) ListPorts(name string) ([]PortForward, error) {
+	forwards, err := portforward.List(p.provider, defaultName(name))
+	if err != nil {
+		return nil, err
+	}
+	services, err := servicelb.List(p.provider, defaultName(name))
+	if err != nil {
+		return nil, err
+	}
+	forwards = append(forwards, services...)
+	portforward.Sort(forwards)
+	ports := make([]PortForward, 0, len(forwards))
+	for _, f := range forwards {
+		ports = append(ports, PortForward{
+			Node:          f.Node,
+			ListenAddress: f.ListenAddress,
+			HostPort:      f.HostPort,
+			NodePort:      f.NodePort,
+			Protocol:      f.Protocol,
+			Dynamic:       f.Dynamic,
+			Service:       f.Service,
+		})
+	}
+	return ports, nil
+}
@@ -20,22 +20,33 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"time"
 
 	"sigs.k8s.io/kind/pkg/cmd/kind/version"
 
+	"sigs.k8s.io/kind/pkg/apis/config/v1alpha4"
 	"sigs.k8s.io/kind/pkg/cluster/constants"
 	"sigs.k8s.io/kind/pkg/cluster/nodes"
 	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
 	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
 	"sigs.k8s.io/kind/pkg/log"
 
+	"sigs.k8s.io/kind/pkg/cluster/internal/clusterconfig"
 	internalcreate "sigs.k8s.io/kind/pkg/cluster/internal/create"
 	internaldelete "sigs.k8s.io/kind/pkg/cluster/internal/delete"
 	"sigs.k8s.io/kind/pkg/cluster/internal/kubeconfig"
+	"sigs.k8s.io/kind/pkg/cluster/internal/lifecycle"
+	"sigs.k8s.io/kind/pkg/cluster/internal/loadbalancer"
 	internalproviders "sigs.k8s.io/kind/pkg/cluster/internal/providers"
 	"sigs.k8s.io/kind/pkg/cluster/internal/providers/docker"
 	"sigs.k8s.io/kind/pkg/cluster/internal/providers/nerdctl"
 	"sigs.k8s.io/kind/pkg/cluster/internal/providers/podman"
+	"sigs.k8s.io/kind/pkg/cluster/internal/servicelb"
+	"sigs.k8s.io/kind/pkg/cluster/internal/snapshot"
+	"sigs.k8s.io/kind/pkg/cluster/internal/upgrade"
+	internalconfig "sigs.k8s.io/kind/pkg/internal/apis/config"
+	internalencoding "sigs.k8s.io/kind/pkg/internal/apis/config/encoding"
 )
 
 // DefaultName is the default cluster name
@@ -197,6 +208,97 @@ func (p *Provider) Delete(name, explicitKubeconfigPath string) error {
 	return internaldelete.Cluster(p.logger, p.provider, defaultName(name), explicitKubeconfigPath)
 }
 
+// CreateNode provisions a new node and joins it to the existing cluster name,
+// returning the name of the new node
+func (p *Provider) CreateNode(name string, options ...CreateNodeOption) (string, error) {
+	opts := &internalcreate.NodeOptions{
+		ClusterName: defaultName(name),
+	}
+	for _, o := range options {
+		if err := o.apply(opts); err != nil {
+			return "", err
+		}
+	}
+	return internalcreate.Node(p.logger, p.provider, opts)
+}
+
+// DeleteNode drains the worker node nodeName, removes it from the cluster name
+// and deletes it
+func (p *Provider) DeleteNode(name, nodeName string) error {
+	return internaldelete.Node(p.logger, p.provider, defaultName(name), nodeName)
+}
+
+// Stop stops the nodes of the cluster name without deleting them
+func (p *Provider) Stop(name string) error {
+	return lifecycle.Stop(p.logger, p.provider, defaultName(name))
+}
+
+// Start starts the nodes of the stopped cluster name, waiting up to
+// waitForReady for the control plane to be ready, and re-exports the
+// kubeconfig following the same rules as ExportKubeConfig
+func (p *Provider) Start(name, explicitKubeconfigPath string, waitForReady time.Duration) error {
+	return lifecycle.Start(p.logger, p.provider, defaultName(name), &lifecycle.StartOptions{
+		WaitForReady:   waitForReady,
+		KubeconfigPath: explicitKubeconfigPath,
+	})
+}
+
+// ReconcileLoadBalancers ensures a load balancer container publishing the
+// ports on the host for each Service of type LoadBalancer of the cluster
+// name, and writes its address to the status of the Service, once.
+// The load balancers of Services that no longer need one are deleted.
+func (p *Provider) ReconcileLoadBalancers(name string) error {
+	return servicelb.Reconcile(p.logger, p.provider, defaultName(name))
+}
+
+// ServeLoadBalancers reconciles the load balancers of the Services of the
+// cluster name like ReconcileLoadBalancers every interval until stop is
+// closed
+func (p *Provider) ServeLoadBalancers(name string, interval time.Duration, stop <-chan struct{}) error {
+	return servicelb.Serve(p.logger, p.provider, defaultName(name), interval, stop)
+}
+
+// Pause freezes all processes in the nodes of the cluster name
+func (p *Provider) Pause(name string) error {
+	return lifecycle.Pause(p.logger, p.provider, defaultName(name))
+}
+
+// Unpause resumes the nodes of the paused cluster name
+func (p *Provider) Unpause(name string) error {
+	return lifecycle.Unpause(p.logger, p.provider, defaultName(name))
+}
+
+// Upgrade upgrades the running cluster name in place to the Kubernetes
+// version of the node image image, waiting up to waitForReady for the
+// control plane to be ready afterwards.
+// Upgrades must follow the kubeadm version skew policy, one minor version
+// at a time.
+func (p *Provider) Upgrade(name, image string, waitForReady time.Duration) error {
+	return upgrade.Cluster(p.logger, p.provider, defaultName(name), &upgrade.Options{
+		Image:        image,
+		WaitForReady: waitForReady,
+	})
+}
+
+// CreateRegistry creates a shared local registry container on the node
+// network, or reuses an existing container with the same name.
+// Unset fields of registry are defaulted, and clusters configured with a
+// registry of the same name will use it.
+func (p *Provider) CreateRegistry(registry *v1alpha4.Registry) error {
+	if registry == nil {
+		registry = &v1alpha4.Registry{}
+	}
+	cfg := internalencoding.V1Alpha4ToInternal(&v1alpha4.Cluster{Registry: registry.DeepCopy()})
+	return internalcreate.Registry(p.logger, p.provider, cfg.Registry)
+}
+
+// Snapshot writes a snapshot of the nodes of the cluster name to path,
+// the cluster may be recreated from it with CreateWithSnapshot.
+// Kubernetes is briefly stopped on the nodes while the snapshot is taken.
+func (p *Provider) Snapshot(name, path string) error {
+	return snapshot.Create(p.logger, p.provider, defaultName(name), path)
+}
+
 // List returns a list of clusters for which nodes exist
 func (p *Provider) List() ([]string, error) {
 	return p.provider.ListClusters()
@@ -217,6 +319,52 @@ func (p *Provider) ExportKubeConfig(name string, explicitPath string, internal b
 	return kubeconfig.Export(p.provider, defaultName(name), explicitPath, !internal)
 }
 
+// GetConfig returns the effective config of the cluster name, as persisted
+// on its nodes when it was created and updated as nodes are added or
+// removed. For clusters created by older versions of kind the config is
+// reconstructed from the nodes as far as possible.
+func (p *Provider) GetConfig(name string) (*v1alpha4.Cluster, error) {
+	name = defaultName(name)
+	allNodes, err := p.provider.ListNodes(name)
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing nodes")
+	}
+	if len(allNodes) == 0 {
+		return nil, errors.Errorf("unknown cluster %q", name)
+	}
+	cfg, err := clusterconfig.Get(p.provider, name, allNodes)
+	if err != nil {
+		return nil, err
+	}
+	return internalconfig.ConvertToV1Alpha4(cfg), nil
+}
+
+// LoadBalancerConfig returns the haproxy config of the external load
+// balancer of the cluster name, as rendered on the load balancer node.
+// Only clusters with multiple control plane nodes have a load balancer.
+func (p *Provider) LoadBalancerConfig(name string) (string, error) {
+	name = defaultName(name)
+	allNodes, err := p.provider.ListNodes(name)
+	if err != nil {
+		return "", errors.Wrap(err, "error listing nodes")
+	}
+	if len(allNodes) == 0 {
+		return "", errors.Errorf("unknown cluster %q", name)
+	}
+	loadBalancerNode, err := nodeutils.ExternalLoadBalancerNode(allNodes)
+	if err != nil {
+		return "", err
+	}
+	if loadBalancerNode == nil {
+		return "", errors.Errorf("cluster %q has no external load balancer, it is only created with multiple control-plane nodes", name)
+	}
+	out, err := exec.Output(loadBalancerNode.Command("cat", loadbalancer.ConfigPath))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to read the load balancer config")
+	}
+	return string(out), nil
+}
+
 // ListNodes returns the list of container IDs for the "nodes" in the cluster
 func (p *Provider) ListNodes(name string) ([]nodes.Node, error) {
 	return p.provider.ListNodes(defaultName(name))
@@ -232,6 +380,12 @@ func (p *Provider) ListInternalNodes(name string) ([]nodes.Node, error) {
 	return nodeutils.InternalNodes(n)
 }
 
+// NodeStatus returns the image, state and published ports of node,
+// which should be from results previously returned by ListNodes
+func (p *Provider) NodeStatus(node nodes.Node) (*nodes.Status, error) {
+	return p.provider.NodeStatus(node)
+}
+
 // CollectLogs will populate dir with cluster logs and other debug files
 func (p *Provider) CollectLogs(name, dir string) error {
 	// TODO: should use ListNodes and Collect should handle nodes differently
@@ -17,11 +17,15 @@ limitations under the License.
 package nodeimage
 
 import (
+	"strings"
+
 	"github.com/spf13/cobra"
 
 	"sigs.k8s.io/kind/pkg/build/nodeimage"
 	"sigs.k8s.io/kind/pkg/cmd"
 	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/apis/config/encoding"
 	"sigs.k8s.io/kind/pkg/log"
 )
 
@@ -31,6 +35,20 @@ type flagpole struct {
 	Image     string
 	BaseImage string
 	Arch      string
+	Config    string
+	OCILayout string
+	Push      bool
+
+	DockerArchive string
+	Daemonless    bool
+
+	ExtraImages        []string
+	ExtraImageArchives []string
+	ExtraFiles         []string
+
+	Verify string
+
+	ArtifactBaseURL string
 }
 
 // NewCommand returns a new cobra.Command for building the node image
@@ -68,24 +86,120 @@ func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
 		&flags.Arch,
 		"arch",
 		"",
-		"architecture to build for, defaults to the host architecture",
+		"architecture to build for, defaults to the host architecture. "+
+			"Multiple comma separated architectures are assembled into an image index, which requires --oci-layout or --push",
+	)
+	cmd.Flags().StringVar(
+		&flags.OCILayout,
+		"oci-layout",
+		"",
+		"path to an OCI image layout directory to also write the image to",
+	)
+	cmd.Flags().StringVar(
+		&flags.DockerArchive,
+		"docker-archive",
+		"",
+		"path to write the image to as a docker save compatible archive, which can be loaded by docker, podman and nerdctl",
+	)
+	cmd.Flags().BoolVar(
+		&flags.Daemonless,
+		"daemonless",
+		false,
+		"build without docker from the base image in its registry, writing the image to --oci-layout or --docker-archive only. "+
+			"Requires building from a release, url or file",
+	)
+	cmd.Flags().BoolVar(
+		&flags.Push,
+		"push",
+		false,
+		"push the image to its registry",
+	)
+	cmd.Flags().StringSliceVar(
+		&flags.ExtraImages,
+		"extra-image",
+		nil,
+		"comma separated list of images to pre-pull into the node image in addition to the images kind requires",
+	)
+	cmd.Flags().StringSliceVar(
+		&flags.ExtraImageArchives,
+		"extra-image-archive",
+		nil,
+		"comma separated list of image archives to pre-load into the node image",
+	)
+	cmd.Flags().StringSliceVar(
+		&flags.ExtraFiles,
+		"extra-file",
+		nil,
+		"comma separated list of hostPath:nodePath files or directories to copy into the node image",
+	)
+	cmd.Flags().StringVar(
+		&flags.ArtifactBaseURL,
+		"artifact-base-url",
+		"",
+		"base url of the release and CI artifacts and version markers like latest-1.31 or ci/latest, defaults to https://dl.k8s.io",
+	)
+	cmd.Flags().StringVar(
+		&flags.Verify,
+		"verify",
+		"",
+		"instead of building, verify that the node image matches the build manifest recorded in it",
+	)
+	cmd.Flags().StringVar(
+		&flags.Config,
+		"config",
+		"",
+		"path to a kind config file, its registryMirrors and registryAuth are used to pull the included images",
 	)
 	return cmd
 }
 
 func runE(logger log.Logger, flags *flagpole, args []string) error {
+	if flags.Verify != "" {
+		if err := nodeimage.Verify(
+			nodeimage.WithImage(flags.Verify),
+			nodeimage.WithLogger(logger),
+			nodeimage.WithArch(flags.Arch),
+		); err != nil {
+			return errors.Wrap(err, "error verifying node image")
+		}
+		return nil
+	}
 	sourceSpec := ""
 	if len(args) > 0 {
 		sourceSpec = args[0]
 	}
-	if err := nodeimage.Build(
+	options := []nodeimage.Option{
 		nodeimage.WithImage(flags.Image),
 		nodeimage.WithBaseImage(flags.BaseImage),
 		nodeimage.WithKubeParam(sourceSpec),
 		nodeimage.WithLogger(logger),
-		nodeimage.WithArch(flags.Arch),
 		nodeimage.WithBuildType(flags.BuildType),
-	); err != nil {
+		nodeimage.WithOCILayout(flags.OCILayout),
+		nodeimage.WithDockerArchive(flags.DockerArchive),
+		nodeimage.WithPush(flags.Push),
+		nodeimage.WithDaemonless(flags.Daemonless),
+		nodeimage.WithExtraImages(flags.ExtraImages...),
+		nodeimage.WithExtraImageArchives(flags.ExtraImageArchives...),
+		nodeimage.WithExtraFiles(flags.ExtraFiles...),
+		nodeimage.WithArtifactBaseURL(flags.ArtifactBaseURL),
+	}
+	if archs := strings.Split(flags.Arch, ","); len(archs) > 1 {
+		options = append(options, nodeimage.WithArchitectures(archs...))
+	} else {
+		options = append(options, nodeimage.WithArch(flags.Arch))
+	}
+	if flags.Config != "" {
+		cfg, err := encoding.Load(flags.Config)
+		if err != nil {
+			return errors.Wrap(err, "error loading config")
+		}
+		public := config.ConvertToV1Alpha4(cfg)
+		options = append(options,
+			nodeimage.WithRegistryMirrors(public.RegistryMirrors),
+			nodeimage.WithRegistryAuth(public.RegistryAuth),
+		)
+	}
+	if err := nodeimage.Build(options...); err != nil {
 		return errors.Wrap(err, "error building node image")
 	}
 	return nil
@@ -39,6 +39,8 @@ type flagpole struct {
 	Retain     bool
 	Wait       time.Duration
 	Kubeconfig string
+	Snapshot   string
+	Output     string
 }
 
 // NewCommand returns a new cobra.Command for cluster creation
@@ -91,10 +93,34 @@ func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
 		"",
 		"sets kubeconfig path instead of $KUBECONFIG or $HOME/.kube/config",
 	)
+	cmd.Flags().StringVar(
+		&flags.Snapshot,
+		"from-snapshot",
+		"",
+		"path to a snapshot written by kind snapshot create to restore the cluster from",
+	)
+	cmd.Flags().StringVarP(
+		&flags.Output,
+		"output",
+		"o",
+		"",
+		"output format for progress, json writes one event per line to stdout",
+	)
+	cmd.MarkFlagsMutuallyExclusive("from-snapshot", "config")
+	cmd.MarkFlagsMutuallyExclusive("from-snapshot", "image")
 	return cmd
 }
 
 func runE(logger log.Logger, streams cmd.IOStreams, flags *flagpole) error {
+	if err := cli.ValidateOutputFormat(flags.Output, cli.OutputFormatJSON); err != nil {
+		return err
+	}
+	// machine readable output replaces all other output
+	humanOutput := flags.Output == ""
+	if !humanOutput {
+		logger = cli.NewEventLogger(streams.Out, logger)
+	}
+
 	provider := cluster.NewProvider(
 		cluster.ProviderWithLogger(logger),
 		runtime.GetDefault(logger),
@@ -106,17 +132,25 @@ func runE(logger log.Logger, streams cmd.IOStreams, flags *flagpole) error {
 		return err
 	}
 
+	// restoring a snapshot replaces the config
+	if flags.Snapshot != "" {
+		withConfig = cluster.CreateWithSnapshot(flags.Snapshot)
+	}
+
 	// create the cluster
-	if err = provider.Create(
+	end := cli.EventsForLogger(logger).Start("create")
+	err = provider.Create(
 		flags.Name,
 		withConfig,
 		cluster.CreateWithNodeImage(flags.ImageName),
 		cluster.CreateWithRetain(flags.Retain),
 		cluster.CreateWithWaitForReady(flags.Wait),
 		cluster.CreateWithKubeconfigPath(flags.Kubeconfig),
-		cluster.CreateWithDisplayUsage(true),
-		cluster.CreateWithDisplaySalutation(true),
-	); err != nil {
+		cluster.CreateWithDisplayUsage(humanOutput),
+		cluster.CreateWithDisplaySalutation(humanOutput),
+	)
+	end(err)
+	if err != nil {
 		return errors.Wrap(err, "failed to create cluster")
 	}
 
@@ -0,0 +1,132 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package node implements the `create node` command
+package node
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/cluster/constants"
+	"sigs.k8s.io/kind/pkg/cmd"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/log"
+
+	"sigs.k8s.io/kind/pkg/internal/cli"
+	"sigs.k8s.io/kind/pkg/internal/runtime"
+)
+
+type flagpole struct {
+	Name      string
+	NodeName  string
+	Role      string
+	ImageName string
+	Labels    map[string]string
+	Retain    bool
+	IPv4      string
+	IPv6      string
+}
+
+// NewCommand returns a new cobra.Command for adding a node to a cluster
+func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
+	flags := &flagpole{}
+	cmd := &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   "node",
+		Short: "Adds a node to an existing cluster",
+		Long:  "Provisions a new node container and joins it to an existing cluster",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cli.OverrideDefaultName(cmd.Flags())
+			return runE(logger, streams, flags)
+		},
+	}
+	cmd.Flags().StringVarP(
+		&flags.Name,
+		"name",
+		"n",
+		cluster.DefaultName,
+		"the cluster name",
+	)
+	cmd.Flags().StringVar(
+		&flags.NodeName,
+		"node-name",
+		"",
+		"the new node name (default the next free name for the role, e.g. kind-worker3)",
+	)
+	cmd.Flags().StringVar(
+		&flags.Role,
+		"role",
+		constants.WorkerNodeRoleValue,
+		"the new node role, currently only worker is supported",
+	)
+	cmd.Flags().StringVar(
+		&flags.ImageName,
+		"image",
+		"",
+		"node docker image to use for the new node (default the image of the existing nodes)",
+	)
+	cmd.Flags().StringToStringVar(
+		&flags.Labels,
+		"label",
+		nil,
+		"Kubernetes labels for the new node, e.g. --label foo=bar",
+	)
+	cmd.Flags().StringVar(
+		&flags.IPv4,
+		"ip",
+		"",
+		"static IPv4 address of the new node, the cluster must have a networking.nodeSubnet",
+	)
+	cmd.Flags().StringVar(
+		&flags.IPv6,
+		"ip6",
+		"",
+		"static IPv6 address of the new node, the cluster must have a networking.nodeSubnet",
+	)
+	cmd.Flags().BoolVar(
+		&flags.Retain,
+		"retain",
+		false,
+		"retain the new node for debugging when joining it fails",
+	)
+	return cmd
+}
+
+func runE(logger log.Logger, streams cmd.IOStreams, flags *flagpole) error {
+	provider := cluster.NewProvider(
+		cluster.ProviderWithLogger(logger),
+		runtime.GetDefault(logger),
+	)
+
+	name, err := provider.CreateNode(
+		flags.Name,
+		cluster.CreateNodeWithName(flags.NodeName),
+		cluster.CreateNodeWithRole(flags.Role),
+		cluster.CreateNodeWithNodeImage(flags.ImageName),
+		cluster.CreateNodeWithLabels(flags.Labels),
+		cluster.CreateNodeWithIPv4Address(flags.IPv4),
+		cluster.CreateNodeWithIPv6Address(flags.IPv6),
+		cluster.CreateNodeWithRetain(flags.Retain),
+	)
+	if err != nil {
+		return errors.Wrapf(err, "failed to add node to cluster %q", flags.Name)
+	}
+	fmt.Fprintln(streams.Out, name)
+	return nil
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package registry implements the `create registry` command
+package registry
+
+import (
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kind/pkg/apis/config/defaults"
+	"sigs.k8s.io/kind/pkg/apis/config/v1alpha4"
+	"sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/cmd"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/log"
+
+	"sigs.k8s.io/kind/pkg/internal/runtime"
+)
+
+type flagpole struct {
+	Name          string
+	Image         string
+	HostPort      int32
+	ListenAddress string
+}
+
+// NewCommand returns a new cobra.Command for registry creation
+func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
+	flags := &flagpole{}
+	cmd := &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   "registry",
+		Short: "Creates a local registry shared by clusters",
+		Long: "Creates a local registry container on the kind network, or starts an existing one with the same name.\n" +
+			"The registry is not deleted with any cluster, clusters use it by setting the same name in the registry section of their config.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runE(logger, flags)
+		},
+	}
+	cmd.Flags().StringVarP(
+		&flags.Name,
+		"name",
+		"n",
+		defaults.RegistryName,
+		"the registry container name",
+	)
+	cmd.Flags().StringVar(
+		&flags.Image,
+		"image",
+		defaults.RegistryImage,
+		"the registry image",
+	)
+	cmd.Flags().Int32Var(
+		&flags.HostPort,
+		"port",
+		defaults.RegistryHostPort,
+		"the port to publish the registry on the host",
+	)
+	cmd.Flags().StringVar(
+		&flags.ListenAddress,
+		"listen-address",
+		"127.0.0.1",
+		"the address to publish the registry on the host",
+	)
+	return cmd
+}
+
+func runE(logger log.Logger, flags *flagpole) error {
+	provider := cluster.NewProvider(
+		cluster.ProviderWithLogger(logger),
+		runtime.GetDefault(logger),
+	)
+	if err := provider.CreateRegistry(&v1alpha4.Registry{
+		Name:          flags.Name,
+		Image:         flags.Image,
+		HostPort:      flags.HostPort,
+		ListenAddress: flags.ListenAddress,
+		Shared:        true,
+	}); err != nil {
+		return errors.Wrapf(err, "failed to create registry %q", flags.Name)
+	}
+	return nil
+}
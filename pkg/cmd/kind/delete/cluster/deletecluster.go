@@ -32,6 +32,7 @@ import (
 type flagpole struct {
 	Name       string
 	Kubeconfig string
+	Output     string
 }
 
 // NewCommand returns a new cobra.Command for cluster deletion
@@ -51,7 +52,7 @@ Errors will only occur if the cluster resources exist and are not able to be del
 `,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			cli.OverrideDefaultName(cmd.Flags())
-			return deleteCluster(logger, flags)
+			return deleteCluster(logger, streams, flags)
 		},
 	}
 	cmd.Flags().StringVarP(
@@ -67,17 +68,33 @@ Errors will only occur if the cluster resources exist and are not able to be del
 		"",
 		"sets kubeconfig path instead of $KUBECONFIG or $HOME/.kube/config",
 	)
+	cmd.Flags().StringVarP(
+		&flags.Output,
+		"output",
+		"o",
+		"",
+		"output format for progress, json writes one event per line to stdout",
+	)
 	return cmd
 }
 
-func deleteCluster(logger log.Logger, flags *flagpole) error {
+func deleteCluster(logger log.Logger, streams cmd.IOStreams, flags *flagpole) error {
+	if err := cli.ValidateOutputFormat(flags.Output, cli.OutputFormatJSON); err != nil {
+		return err
+	}
+	if flags.Output != "" {
+		logger = cli.NewEventLogger(streams.Out, logger)
+	}
 	provider := cluster.NewProvider(
 		cluster.ProviderWithLogger(logger),
 		runtime.GetDefault(logger),
 	)
 	// Delete individual cluster
 	logger.V(0).Infof("Deleting cluster %q ...", flags.Name)
-	if err := provider.Delete(flags.Name, flags.Kubeconfig); err != nil {
+	end := cli.EventsForLogger(logger).Start("delete")
+	err := provider.Delete(flags.Name, flags.Kubeconfig)
+	end(err)
+	if err != nil {
 		return errors.Wrapf(err, "failed to delete cluster %q", flags.Name)
 	}
 	return nil
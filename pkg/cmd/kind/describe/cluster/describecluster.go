@@ -0,0 +1,149 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package cluster implements the `describe cluster` command
+package cluster
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+
+	"sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/cmd"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/log"
+
+	"sigs.k8s.io/kind/pkg/internal/cli"
+	"sigs.k8s.io/kind/pkg/internal/runtime"
+)
+
+type flagpole struct {
+	Name   string
+	Output string
+}
+
+// NewCommand returns a new cobra.Command for describing a cluster
+func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
+	flags := &flagpole{}
+	cmd := &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   "cluster",
+		Short: "Describes a cluster",
+		Long: `Describes a cluster: the provider, network, API server endpoints,
+Kubernetes version, CNI, kube-proxy mode, nodes and config.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cli.OverrideDefaultName(cmd.Flags())
+			return runE(logger, streams, flags)
+		},
+	}
+	cmd.Flags().StringVarP(
+		&flags.Name,
+		"name",
+		"n",
+		cluster.DefaultName,
+		"the cluster context name",
+	)
+	cmd.Flags().StringVarP(
+		&flags.Output,
+		"output",
+		"o",
+		"",
+		"output format, one of: json, yaml",
+	)
+	return cmd
+}
+
+func runE(logger log.Logger, streams cmd.IOStreams, flags *flagpole) error {
+	if err := cli.ValidateOutputFormat(flags.Output, cli.OutputFormatJSON, cli.OutputFormatYAML); err != nil {
+		return err
+	}
+	provider := cluster.NewProvider(
+		cluster.ProviderWithLogger(logger),
+		runtime.GetDefault(logger),
+	)
+	desc, err := provider.Describe(flags.Name)
+	if err != nil {
+		return errors.Wrapf(err, "failed to describe cluster %q", flags.Name)
+	}
+	if flags.Output != "" {
+		return cli.PrintOutput(streams.Out, flags.Output, desc)
+	}
+	return printDescription(streams.Out, desc)
+}
+
+// printDescription writes the human readable description desc to w
+func printDescription(w io.Writer, desc *cluster.ClusterDescription) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "Name:\t%s\n", desc.Name)
+	fmt.Fprintf(tw, "Provider:\t%s\n", desc.Provider)
+	fmt.Fprintf(tw, "Kubernetes Version:\t%s\n", valueOrUnknown(desc.KubernetesVersion))
+	fmt.Fprintf(tw, "CNI:\t%s\n", valueOrUnknown(desc.CNI))
+	fmt.Fprintf(tw, "Kube-Proxy Mode:\t%s\n", valueOrUnknown(desc.KubeProxyMode))
+	fmt.Fprintf(tw, "API Server (External):\t%s\n", valueOrUnknown(desc.APIServer.External))
+	fmt.Fprintf(tw, "API Server (Internal):\t%s\n", valueOrUnknown(desc.APIServer.Internal))
+	fmt.Fprintf(tw, "Network:\t%s %s\n", desc.Network.Name, strings.Join(desc.Network.Subnets, ","))
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w, "\nNodes:")
+	tw = tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "  NAME\tROLE\tSTATE\tVERSION\tIPV4\tIPV6\tIMAGE\tPORTS")
+	for _, n := range desc.Nodes {
+		ports := make([]string, 0, len(n.Ports))
+		for _, p := range n.Ports {
+			ports = append(ports, fmt.Sprintf("%s:%d->%d/%s", p.ListenAddress, p.HostPort, p.ContainerPort, p.Protocol))
+		}
+		fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			n.Name, n.Role, n.State, valueOrNone(n.KubernetesVersion),
+			valueOrNone(n.IPv4), valueOrNone(n.IPv6), n.Image, valueOrNone(strings.Join(ports, ",")),
+		)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if desc.Config != nil {
+		raw, err := yaml.Marshal(desc.Config)
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal cluster config")
+		}
+		fmt.Fprintln(w, "\nConfig:")
+		for _, line := range strings.Split(strings.TrimSuffix(string(raw), "\n"), "\n") {
+			fmt.Fprintf(w, "  %s\n", line)
+		}
+	}
+	return nil
+}
+
+func valueOrUnknown(v string) string {
+	if v == "" {
+		return "<unknown>"
+	}
+	return v
+}
+
+func valueOrNone(v string) string {
+	if v == "" {
+		return "<none>"
+	}
+	return v
+}
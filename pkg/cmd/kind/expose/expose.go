@@ -0,0 +1,125 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package expose implements the `expose` command
+package expose
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/cmd"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/log"
+
+	"sigs.k8s.io/kind/pkg/internal/cli"
+	"sigs.k8s.io/kind/pkg/internal/runtime"
+)
+
+type flagpole struct {
+	Name    string
+	Node    string
+	Address string
+}
+
+// NewCommand returns a new cobra.Command for exposing node ports on the host
+func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
+	flags := &flagpole{}
+	cmd := &cobra.Command{
+		Args:  cobra.MinimumNArgs(1),
+		Use:   "expose HOST_PORT:NODE_PORT[/PROTOCOL]...",
+		Short: "Forwards host ports to the nodes of a running cluster",
+		Long: "Forwards host ports to the nodes of a running cluster with a small forwarding container on the node network per port, " +
+			"until they are removed with kind unexpose or the cluster is deleted. Only tcp ports are supported.",
+		Example: "  kind expose --name c --node c-worker 8080:30080/tcp",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cli.OverrideDefaultName(cmd.Flags())
+			return runE(logger, flags, args)
+		},
+	}
+	cmd.Flags().StringVarP(
+		&flags.Name,
+		"name",
+		"n",
+		cluster.DefaultName,
+		"the cluster name",
+	)
+	cmd.Flags().StringVar(
+		&flags.Node,
+		"node",
+		"",
+		"the node to forward to, defaults to the control plane node",
+	)
+	cmd.Flags().StringVar(
+		&flags.Address,
+		"address",
+		"",
+		"the host address to listen on, defaults to 0.0.0.0",
+	)
+	return cmd
+}
+
+func runE(logger log.Logger, flags *flagpole, args []string) error {
+	forwards := []*cluster.PortForward{}
+	for _, arg := range args {
+		forward, err := parsePortForward(arg)
+		if err != nil {
+			return err
+		}
+		forward.Node = flags.Node
+		forward.ListenAddress = flags.Address
+		forwards = append(forwards, forward)
+	}
+
+	provider := cluster.NewProvider(
+		cluster.ProviderWithLogger(logger),
+		runtime.GetDefault(logger),
+	)
+	for _, forward := range forwards {
+		if err := provider.Expose(flags.Name, forward); err != nil {
+			return errors.Wrapf(err, "failed to expose host port %d", forward.HostPort)
+		}
+	}
+	return nil
+}
+
+// parsePortForward parses a HOST_PORT:NODE_PORT[/PROTOCOL] argument
+func parsePortForward(arg string) (*cluster.PortForward, error) {
+	ports, protocol := arg, "tcp"
+	if i := strings.LastIndex(arg, "/"); i >= 0 {
+		ports, protocol = arg[:i], strings.ToLower(arg[i+1:])
+	}
+	parts := strings.Split(ports, ":")
+	if len(parts) != 2 {
+		return nil, errors.Errorf("invalid port forward %q, expected HOST_PORT:NODE_PORT[/PROTOCOL]", arg)
+	}
+	hostPort, err := strconv.ParseUint(parts[0], 10, 16)
+	if err != nil || hostPort == 0 {
+		return nil, errors.Errorf("invalid host port in %q", arg)
+	}
+	nodePort, err := strconv.ParseUint(parts[1], 10, 16)
+	if err != nil || nodePort == 0 {
+		return nil, errors.Errorf("invalid node port in %q", arg)
+	}
+	return &cluster.PortForward{
+		HostPort: int32(hostPort),
+		NodePort: int32(nodePort),
+		Protocol: protocol,
+	}, nil
+}
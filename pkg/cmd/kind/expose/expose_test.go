@@ -0,0 +1,73 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package expose
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestParsePortForward(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Arg         string
+		Expected    *cluster.PortForward
+		ExpectError bool
+	}{
+		{
+			Arg:      "8080:30080/tcp",
+			Expected: &cluster.PortForward{HostPort: 8080, NodePort: 30080, Protocol: "tcp"},
+		},
+		{
+			Arg:      "8080:80",
+			Expected: &cluster.PortForward{HostPort: 8080, NodePort: 80, Protocol: "tcp"},
+		},
+		{
+			Arg:      "5353:53/UDP",
+			Expected: &cluster.PortForward{HostPort: 5353, NodePort: 53, Protocol: "udp"},
+		},
+		{
+			Arg:         "8080",
+			ExpectError: true,
+		},
+		{
+			Arg:         "8080:0",
+			ExpectError: true,
+		},
+		{
+			Arg:         "70000:80",
+			ExpectError: true,
+		},
+		{
+			Arg:         "http:80/tcp",
+			ExpectError: true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Arg, func(t *testing.T) {
+			t.Parallel()
+			forward, err := parsePortForward(tc.Arg)
+			assert.ExpectError(t, tc.ExpectError, err)
+			if !tc.ExpectError {
+				assert.DeepEqual(t, tc.Expected, forward)
+			}
+		})
+	}
+}
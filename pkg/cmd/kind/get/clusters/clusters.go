@@ -26,11 +26,22 @@ import (
 	"sigs.k8s.io/kind/pkg/cmd"
 	"sigs.k8s.io/kind/pkg/log"
 
+	"sigs.k8s.io/kind/pkg/internal/cli"
 	"sigs.k8s.io/kind/pkg/internal/runtime"
 )
 
+type flagpole struct {
+	Output string
+}
+
+// clusterInfo is the machine readable output for a cluster
+type clusterInfo struct {
+	Name string `json:"name"`
+}
+
 // NewCommand returns a new cobra.Command for getting the list of clusters
 func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
+	flags := &flagpole{}
 	cmd := &cobra.Command{
 		Args: cobra.NoArgs,
 		// TODO(bentheelder): more detailed usage
@@ -38,13 +49,23 @@ func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
 		Short: "Lists existing kind clusters by their name",
 		Long:  "Lists existing kind clusters by their name",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runE(logger, streams)
+			return runE(logger, streams, flags)
 		},
 	}
+	cmd.Flags().StringVarP(
+		&flags.Output,
+		"output",
+		"o",
+		"",
+		"output format, one of: json, yaml",
+	)
 	return cmd
 }
 
-func runE(logger log.Logger, streams cmd.IOStreams) error {
+func runE(logger log.Logger, streams cmd.IOStreams, flags *flagpole) error {
+	if err := cli.ValidateOutputFormat(flags.Output, cli.OutputFormatJSON, cli.OutputFormatYAML); err != nil {
+		return err
+	}
 	provider := cluster.NewProvider(
 		cluster.ProviderWithLogger(logger),
 		runtime.GetDefault(logger),
@@ -53,6 +74,13 @@ func runE(logger log.Logger, streams cmd.IOStreams) error {
 	if err != nil {
 		return err
 	}
+	if flags.Output != "" {
+		out := []clusterInfo{}
+		for _, name := range clusters {
+			out = append(out, clusterInfo{Name: name})
+		}
+		return cli.PrintOutput(streams.Out, flags.Output, out)
+	}
 	if len(clusters) == 0 {
 		logger.V(0).Info("No kind clusters found.")
 		return nil
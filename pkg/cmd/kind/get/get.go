@@ -24,8 +24,11 @@ import (
 
 	"sigs.k8s.io/kind/pkg/cmd"
 	"sigs.k8s.io/kind/pkg/cmd/kind/get/clusters"
+	"sigs.k8s.io/kind/pkg/cmd/kind/get/config"
 	"sigs.k8s.io/kind/pkg/cmd/kind/get/kubeconfig"
+	loadbalancerconfig "sigs.k8s.io/kind/pkg/cmd/kind/get/loadbalancer-config"
 	"sigs.k8s.io/kind/pkg/cmd/kind/get/nodes"
+	"sigs.k8s.io/kind/pkg/cmd/kind/get/ports"
 	"sigs.k8s.io/kind/pkg/log"
 )
 
@@ -34,8 +37,8 @@ func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
 	cmd := &cobra.Command{
 		// TODO(bentheelder): more detailed usage
 		Use:   "get",
-		Short: "Gets one of [clusters, nodes, kubeconfig]",
-		Long:  "Gets one of [clusters, nodes, kubeconfig]",
+		Short: "Gets one of [clusters, nodes, kubeconfig, config, ports, loadbalancer-config]",
+		Long:  "Gets one of [clusters, nodes, kubeconfig, config, ports, loadbalancer-config]",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			err := cmd.Help()
 			if err != nil {
@@ -48,5 +51,8 @@ func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
 	cmd.AddCommand(clusters.NewCommand(logger, streams))
 	cmd.AddCommand(nodes.NewCommand(logger, streams))
 	cmd.AddCommand(kubeconfig.NewCommand(logger, streams))
+	cmd.AddCommand(config.NewCommand(logger, streams))
+	cmd.AddCommand(ports.NewCommand(logger, streams))
+	cmd.AddCommand(loadbalancerconfig.NewCommand(logger, streams))
 	return cmd
 }
@@ -21,9 +21,11 @@ import (
 	"fmt"
 
 	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
 
 	"sigs.k8s.io/kind/pkg/cluster"
 	"sigs.k8s.io/kind/pkg/cmd"
+	"sigs.k8s.io/kind/pkg/errors"
 	"sigs.k8s.io/kind/pkg/log"
 
 	"sigs.k8s.io/kind/pkg/internal/cli"
@@ -33,6 +35,7 @@ import (
 type flagpole struct {
 	Name     string
 	Internal bool
+	Output   string
 }
 
 // NewCommand returns a new cobra.Command for getting the kubeconfig
@@ -61,10 +64,20 @@ func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
 		false,
 		"use internal address instead of external",
 	)
+	cmd.Flags().StringVarP(
+		&flags.Output,
+		"output",
+		"o",
+		"",
+		"output format, one of: json, yaml",
+	)
 	return cmd
 }
 
 func runE(logger log.Logger, streams cmd.IOStreams, flags *flagpole) error {
+	if err := cli.ValidateOutputFormat(flags.Output, cli.OutputFormatJSON, cli.OutputFormatYAML); err != nil {
+		return err
+	}
 	provider := cluster.NewProvider(
 		cluster.ProviderWithLogger(logger),
 		runtime.GetDefault(logger),
@@ -73,6 +86,14 @@ func runE(logger log.Logger, streams cmd.IOStreams, flags *flagpole) error {
 	if err != nil {
 		return err
 	}
+	// the kubeconfig is already YAML
+	if flags.Output == cli.OutputFormatJSON {
+		out, err := yaml.YAMLToJSON([]byte(cfg))
+		if err != nil {
+			return errors.Wrap(err, "failed to convert kubeconfig to JSON")
+		}
+		cfg = string(out)
+	}
 	fmt.Fprintln(streams.Out, cfg)
 	return nil
 }
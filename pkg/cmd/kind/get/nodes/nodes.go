@@ -34,6 +34,13 @@ import (
 type flagpole struct {
 	Name        string
 	AllClusters bool
+	Output      string
+}
+
+// node is the machine readable output for a node
+type node struct {
+	Cluster string `json:"cluster"`
+	cluster.NodeDescription
 }
 
 // NewCommand returns a new cobra.Command for getting the list of nodes for a given cluster
@@ -43,7 +50,7 @@ func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
 		Args:  cobra.NoArgs,
 		Use:   "nodes",
 		Short: "Lists existing kind nodes by their name",
-		Long:  "Lists existing kind nodes by their name",
+		Long:  "Lists existing kind nodes by their name, or with their details in a machine readable format",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			cli.OverrideDefaultName(cmd.Flags())
 			return runE(logger, streams, flags)
@@ -63,47 +70,72 @@ func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
 		false,
 		"If present, list all the available nodes across all cluster contexts. Current context is ignored even if specified with --name.",
 	)
+	cmd.Flags().StringVarP(
+		&flags.Output,
+		"output",
+		"o",
+		"",
+		"output format, one of: json, yaml",
+	)
 	return cmd
 }
 
 func runE(logger log.Logger, streams cmd.IOStreams, flags *flagpole) error {
+	if err := cli.ValidateOutputFormat(flags.Output, cli.OutputFormatJSON, cli.OutputFormatYAML); err != nil {
+		return err
+	}
+
 	// List nodes by cluster context name
 	provider := cluster.NewProvider(
 		cluster.ProviderWithLogger(logger),
 		runtime.GetDefault(logger),
 	)
 
-	var nodes []nodes.Node
-	var err error
+	clusters := []string{flags.Name}
 	if flags.AllClusters {
-		clusters, err := provider.List()
+		var err error
+		if clusters, err = provider.List(); err != nil {
+			return err
+		}
+	}
+	var allNodes []nodes.Node
+	nodeClusters := map[string]string{}
+	for _, clusterName := range clusters {
+		clusterNodes, err := provider.ListNodes(clusterName)
 		if err != nil {
 			return err
 		}
-		for _, clusterName := range clusters {
-			clusterNodes, err := provider.ListNodes(clusterName)
+		for _, n := range clusterNodes {
+			nodeClusters[n.String()] = clusterName
+		}
+		allNodes = append(allNodes, clusterNodes...)
+	}
+
+	if flags.Output != "" {
+		out := []node{}
+		for _, n := range allNodes {
+			desc, err := provider.DescribeNode(n)
 			if err != nil {
 				return err
 			}
-			nodes = append(nodes, clusterNodes...)
+			out = append(out, node{
+				Cluster:         nodeClusters[n.String()],
+				NodeDescription: *desc,
+			})
 		}
-		if len(nodes) == 0 {
+		return cli.PrintOutput(streams.Out, flags.Output, out)
+	}
+
+	if len(allNodes) == 0 {
+		if flags.AllClusters {
 			logger.V(0).Infof("No kind nodes for any cluster.")
-			return nil
-		}
-	} else {
-		nodes, err = provider.ListNodes(flags.Name)
-		if err != nil {
-			return err
-		}
-		if len(nodes) == 0 {
+		} else {
 			logger.V(0).Infof("No kind nodes found for cluster %q.", flags.Name)
-			return nil
 		}
+		return nil
 	}
-
-	for _, node := range nodes {
-		fmt.Fprintln(streams.Out, node.String())
+	for _, n := range allNodes {
+		fmt.Fprintln(streams.Out, n.String())
 	}
 	return nil
 }
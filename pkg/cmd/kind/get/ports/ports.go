@@ -0,0 +1,105 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package ports implements the `ports` command
+package ports
+
+import (
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/cmd"
+	"sigs.k8s.io/kind/pkg/log"
+
+	"sigs.k8s.io/kind/pkg/internal/cli"
+	"sigs.k8s.io/kind/pkg/internal/runtime"
+)
+
+type flagpole struct {
+	Name   string
+	Output string
+}
+
+// NewCommand returns a new cobra.Command for listing the forwarded ports of a cluster
+func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
+	flags := &flagpole{}
+	cmd := &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   "ports",
+		Short: "Lists the host ports forwarded to the nodes of a cluster",
+		Long:  "Lists the host ports forwarded to the nodes of a cluster, the extraPortMappings of the nodes, the ports exposed with kind expose and the ports of the Service load balancers of kind serve loadbalancers",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cli.OverrideDefaultName(cmd.Flags())
+			return runE(logger, streams, flags)
+		},
+	}
+	cmd.Flags().StringVarP(
+		&flags.Name,
+		"name",
+		"n",
+		cluster.DefaultName,
+		"the cluster name",
+	)
+	cmd.Flags().StringVarP(
+		&flags.Output,
+		"output",
+		"o",
+		"",
+		"output format, one of: json, yaml",
+	)
+	return cmd
+}
+
+func runE(logger log.Logger, streams cmd.IOStreams, flags *flagpole) error {
+	if err := cli.ValidateOutputFormat(flags.Output, cli.OutputFormatJSON, cli.OutputFormatYAML); err != nil {
+		return err
+	}
+	provider := cluster.NewProvider(
+		cluster.ProviderWithLogger(logger),
+		runtime.GetDefault(logger),
+	)
+	ports, err := provider.ListPorts(flags.Name)
+	if err != nil {
+		return err
+	}
+	if flags.Output != "" {
+		return cli.PrintOutput(streams.Out, flags.Output, ports)
+	}
+
+	if len(ports) == 0 {
+		logger.V(0).Infof("No forwarded ports found for cluster %q.", flags.Name)
+		return nil
+	}
+	tw := tabwriter.NewWriter(streams.Out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "HOST\tNODE\tNODE PORT\tTYPE")
+	for _, p := range ports {
+		kind := "static"
+		if p.Dynamic {
+			kind = "dynamic"
+		}
+		// Service load balancers forward to the Service port
+		target := p.Node
+		if p.Service != "" {
+			target = "service/" + p.Service
+			kind = "service"
+		}
+		fmt.Fprintf(tw, "%s:%d/%s\t%s\t%d\t%s\n", p.ListenAddress, p.HostPort, p.Protocol, target, p.NodePort, kind)
+	}
+	return tw.Flush()
+}
@@ -27,9 +27,19 @@ import (
 	"sigs.k8s.io/kind/pkg/cmd/kind/completion"
 	"sigs.k8s.io/kind/pkg/cmd/kind/create"
 	"sigs.k8s.io/kind/pkg/cmd/kind/delete"
+	"sigs.k8s.io/kind/pkg/cmd/kind/describe"
 	"sigs.k8s.io/kind/pkg/cmd/kind/export"
+	"sigs.k8s.io/kind/pkg/cmd/kind/expose"
 	"sigs.k8s.io/kind/pkg/cmd/kind/get"
 	"sigs.k8s.io/kind/pkg/cmd/kind/load"
+	"sigs.k8s.io/kind/pkg/cmd/kind/pause"
+	"sigs.k8s.io/kind/pkg/cmd/kind/serve"
+	"sigs.k8s.io/kind/pkg/cmd/kind/snapshot"
+	"sigs.k8s.io/kind/pkg/cmd/kind/start"
+	"sigs.k8s.io/kind/pkg/cmd/kind/stop"
+	"sigs.k8s.io/kind/pkg/cmd/kind/unexpose"
+	"sigs.k8s.io/kind/pkg/cmd/kind/unpause"
+	"sigs.k8s.io/kind/pkg/cmd/kind/upgrade"
 	"sigs.k8s.io/kind/pkg/cmd/kind/version"
 	"sigs.k8s.io/kind/pkg/log"
 )
@@ -74,10 +84,20 @@ func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
 	cmd.AddCommand(completion.NewCommand(logger, streams))
 	cmd.AddCommand(create.NewCommand(logger, streams))
 	cmd.AddCommand(delete.NewCommand(logger, streams))
+	cmd.AddCommand(describe.NewCommand(logger, streams))
 	cmd.AddCommand(export.NewCommand(logger, streams))
 	cmd.AddCommand(get.NewCommand(logger, streams))
 	cmd.AddCommand(version.NewCommand(logger, streams))
 	cmd.AddCommand(load.NewCommand(logger, streams))
+	cmd.AddCommand(stop.NewCommand(logger, streams))
+	cmd.AddCommand(start.NewCommand(logger, streams))
+	cmd.AddCommand(snapshot.NewCommand(logger, streams))
+	cmd.AddCommand(pause.NewCommand(logger, streams))
+	cmd.AddCommand(unpause.NewCommand(logger, streams))
+	cmd.AddCommand(upgrade.NewCommand(logger, streams))
+	cmd.AddCommand(serve.NewCommand(logger, streams))
+	cmd.AddCommand(expose.NewCommand(logger, streams))
+	cmd.AddCommand(unexpose.NewCommand(logger, streams))
 	return cmd
 }
 
@@ -0,0 +1,104 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package loadbalancers implements the `serve loadbalancers` command
+package loadbalancers
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/cmd"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/log"
+
+	"sigs.k8s.io/kind/pkg/internal/cli"
+	"sigs.k8s.io/kind/pkg/internal/runtime"
+)
+
+type flagpole struct {
+	Name     string
+	Interval time.Duration
+	Once     bool
+}
+
+// NewCommand returns a new cobra.Command for serving Service load balancers
+func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
+	flags := &flagpole{}
+	cmd := &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   "loadbalancers",
+		Short: "Serves the Services of type LoadBalancer of a cluster",
+		Long: "Watches the Services of type LoadBalancer of a cluster until interrupted. " +
+			"Each Service gets a load balancer container on the node network that publishes its ports on the host, " +
+			"and the address of the container is written to the status of the Service. " +
+			"The load balancers keep working after this command exits, and are deleted with the cluster.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cli.OverrideDefaultName(cmd.Flags())
+			return runE(logger, flags)
+		},
+	}
+	cmd.Flags().StringVarP(
+		&flags.Name,
+		"name",
+		"n",
+		cluster.DefaultName,
+		"the cluster name",
+	)
+	cmd.Flags().DurationVar(
+		&flags.Interval,
+		"interval",
+		5*time.Second,
+		"how often to reconcile the load balancers with the Services",
+	)
+	cmd.Flags().BoolVar(
+		&flags.Once,
+		"once",
+		false,
+		"reconcile the load balancers once and exit",
+	)
+	return cmd
+}
+
+func runE(logger log.Logger, flags *flagpole) error {
+	provider := cluster.NewProvider(
+		cluster.ProviderWithLogger(logger),
+		runtime.GetDefault(logger),
+	)
+	if flags.Interval <= 0 {
+		return errors.Errorf("invalid interval %v, must be positive", flags.Interval)
+	}
+	if flags.Once {
+		if err := provider.ReconcileLoadBalancers(flags.Name); err != nil {
+			return errors.Wrapf(err, "failed to reconcile load balancers of cluster %q", flags.Name)
+		}
+		return nil
+	}
+
+	stop := make(chan struct{})
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		close(stop)
+	}()
+	return provider.ServeLoadBalancers(flags.Name, flags.Interval, stop)
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package unexpose implements the `unexpose` command
+package unexpose
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/cmd"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/log"
+
+	"sigs.k8s.io/kind/pkg/internal/cli"
+	"sigs.k8s.io/kind/pkg/internal/runtime"
+)
+
+type flagpole struct {
+	Name string
+}
+
+// NewCommand returns a new cobra.Command for removing port forwards
+func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
+	flags := &flagpole{}
+	cmd := &cobra.Command{
+		Args:    cobra.MinimumNArgs(1),
+		Use:     "unexpose HOST_PORT[/PROTOCOL]...",
+		Short:   "Stops forwarding host ports exposed with kind expose",
+		Long:    "Stops forwarding host ports exposed with kind expose, and removes their forwarding containers",
+		Example: "  kind unexpose --name c 8080/tcp",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cli.OverrideDefaultName(cmd.Flags())
+			return runE(logger, flags, args)
+		},
+	}
+	cmd.Flags().StringVarP(
+		&flags.Name,
+		"name",
+		"n",
+		cluster.DefaultName,
+		"the cluster name",
+	)
+	return cmd
+}
+
+func runE(logger log.Logger, flags *flagpole, args []string) error {
+	hostPorts := []int32{}
+	for _, arg := range args {
+		// only tcp ports can be exposed, the protocol is accepted for symmetry
+		// with kind expose
+		port := arg
+		if i := strings.LastIndex(arg, "/"); i >= 0 {
+			port = arg[:i]
+		}
+		hostPort, err := strconv.ParseUint(port, 10, 16)
+		if err != nil || hostPort == 0 {
+			return errors.Errorf("invalid host port %q, expected HOST_PORT[/PROTOCOL]", arg)
+		}
+		hostPorts = append(hostPorts, int32(hostPort))
+	}
+
+	provider := cluster.NewProvider(
+		cluster.ProviderWithLogger(logger),
+		runtime.GetDefault(logger),
+	)
+	for _, hostPort := range hostPorts {
+		if err := provider.Unexpose(flags.Name, hostPort); err != nil {
+			return errors.Wrapf(err, "failed to unexpose host port %d", hostPort)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package cluster implements the `upgrade cluster` command
+package cluster
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/cmd"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/log"
+
+	"sigs.k8s.io/kind/pkg/internal/cli"
+	"sigs.k8s.io/kind/pkg/internal/runtime"
+)
+
+type flagpole struct {
+	Name  string
+	Image string
+	Wait  time.Duration
+}
+
+// NewCommand returns a new cobra.Command for upgrading a cluster
+func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
+	flags := &flagpole{}
+	cmd := &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   "cluster",
+		Short: "Upgrades a running cluster to a new Kubernetes version",
+		Long: `Upgrades a running cluster in place to the Kubernetes version of a node image.
+
+The Kubernetes binaries and images from the node image are loaded into each
+node, and the nodes are upgraded with kubeadm one at a time: the control-plane
+nodes first, then the worker nodes. The node containers keep running on their
+original image.
+
+Upgrades must follow the kubeadm version skew policy, one minor version at a
+time.
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cli.OverrideDefaultName(cmd.Flags())
+			return runE(logger, flags)
+		},
+	}
+	cmd.Flags().StringVarP(
+		&flags.Name,
+		"name",
+		"n",
+		cluster.DefaultName,
+		"the cluster name",
+	)
+	cmd.Flags().StringVar(
+		&flags.Image,
+		"image",
+		"",
+		"node docker image to upgrade to",
+	)
+	cmd.Flags().DurationVar(
+		&flags.Wait,
+		"wait",
+		time.Minute,
+		"wait for control plane node to be ready",
+	)
+	return cmd
+}
+
+func runE(logger log.Logger, flags *flagpole) error {
+	if flags.Image == "" {
+		return errors.New("--image is required")
+	}
+	provider := cluster.NewProvider(
+		cluster.ProviderWithLogger(logger),
+		runtime.GetDefault(logger),
+	)
+	if err := provider.Upgrade(flags.Name, flags.Image, flags.Wait); err != nil {
+		return errors.Wrapf(err, "failed to upgrade cluster %q", flags.Name)
+	}
+	return nil
+}
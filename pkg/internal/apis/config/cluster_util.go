@@ -16,6 +16,12 @@ limitations under the License.
 
 package config
 
+import (
+	"net"
+
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
 // ClusterHasIPv6 returns true if the cluster should have IPv6 enabled due to either
 // being IPv6 cluster family or Dual Stack
 func ClusterHasIPv6(c *Cluster) bool {
@@ -32,3 +38,33 @@ func ClusterHasImplicitLoadBalancer(c *Cluster) bool {
 	}
 	return controlPlanes > 1
 }
+
+// DynamicIPRange returns the range of the node or network subnet that the
+// container runtime assigns addresses from dynamically, which is the upper
+// half of subnet. Static addresses are taken from the lower half so that
+// containers started concurrently cannot be assigned them.
+func DynamicIPRange(subnet string) (string, error) {
+	_, cidr, err := net.ParseCIDR(subnet)
+	if err != nil {
+		return "", err
+	}
+	ipRange, err := dynamicIPRange(cidr)
+	if err != nil {
+		return "", err
+	}
+	return ipRange.String(), nil
+}
+
+func dynamicIPRange(subnet *net.IPNet) (*net.IPNet, error) {
+	ones, bits := subnet.Mask.Size()
+	// the lower half must have room for static addresses besides the
+	// subnet and gateway addresses
+	if bits-ones < 3 {
+		return nil, errors.Errorf("subnet %s is too small, it must have at least 8 addresses", subnet)
+	}
+	ip := make(net.IP, len(subnet.IP))
+	copy(ip, subnet.IP.Mask(subnet.Mask))
+	// set the first host bit
+	ip[ones/8] |= 0x80 >> uint(ones%8)
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(ones+1, bits)}, nil
+}
@@ -136,3 +136,53 @@ func TestClusterHasImplicitLoadBalancer(t *testing.T) {
 		})
 	}
 }
+
+func TestDynamicIPRange(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name        string
+		Subnet      string
+		Expected    string
+		ExpectError bool
+	}{
+		{
+			Name:     "IPv4",
+			Subnet:   "172.30.0.0/24",
+			Expected: "172.30.0.128/25",
+		},
+		{
+			Name:     "IPv4 not on a byte boundary",
+			Subnet:   "10.200.0.0/20",
+			Expected: "10.200.8.0/21",
+		},
+		{
+			Name:     "IPv6",
+			Subnet:   "fd00:30::/64",
+			Expected: "fd00:30:0:0:8000::/65",
+		},
+		{
+			Name:     "smallest subnet",
+			Subnet:   "172.30.0.8/29",
+			Expected: "172.30.0.12/30",
+		},
+		{
+			Name:        "too small subnet",
+			Subnet:      "172.30.0.0/30",
+			ExpectError: true,
+		},
+		{
+			Name:        "invalid subnet",
+			Subnet:      "172.30.0.0",
+			ExpectError: true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			ipRange, err := DynamicIPRange(tc.Subnet)
+			assert.ExpectError(t, tc.ExpectError, err)
+			assert.StringEqual(t, tc.Expected, ipRange)
+		})
+	}
+}
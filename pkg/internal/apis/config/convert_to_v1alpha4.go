@@ -0,0 +1,222 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	v1alpha4 "sigs.k8s.io/kind/pkg/apis/config/v1alpha4"
+)
+
+// ConvertToV1Alpha4 converts an internal cluster to a v1alpha4 cluster
+// This is the inverse of Convertv1alpha4 and is used to serialize the
+// effective config of a cluster
+func ConvertToV1Alpha4(in *Cluster) *v1alpha4.Cluster {
+	in = in.DeepCopy() // deep copy first to avoid touching the original
+	out := &v1alpha4.Cluster{
+		TypeMeta: v1alpha4.TypeMeta{
+			Kind:       "Cluster",
+			APIVersion: "kind.x-k8s.io/v1alpha4",
+		},
+		Name:                            in.Name,
+		Nodes:                           make([]v1alpha4.Node, len(in.Nodes)),
+		FeatureGates:                    in.FeatureGates,
+		RuntimeConfig:                   in.RuntimeConfig,
+		KubeadmConfigPatches:            in.KubeadmConfigPatches,
+		KubeadmConfigPatchesJSON6902:    make([]v1alpha4.PatchJSON6902, len(in.KubeadmConfigPatchesJSON6902)),
+		ContainerdConfigPatches:         in.ContainerdConfigPatches,
+		ContainerdConfigPatchesJSON6902: in.ContainerdConfigPatchesJSON6902,
+	}
+
+	for i := range in.Nodes {
+		convertToV1Alpha4Node(&in.Nodes[i], &out.Nodes[i])
+	}
+
+	convertToV1Alpha4Networking(&in.Networking, &out.Networking)
+
+	if in.Registry != nil {
+		out.Registry = &v1alpha4.Registry{}
+		convertToV1Alpha4Registry(in.Registry, out.Registry)
+	}
+
+	if in.RegistryMirrors != nil {
+		out.RegistryMirrors = make([]v1alpha4.RegistryMirror, len(in.RegistryMirrors))
+		for i := range in.RegistryMirrors {
+			convertToV1Alpha4RegistryMirror(&in.RegistryMirrors[i], &out.RegistryMirrors[i])
+		}
+	}
+
+	if in.RegistryAuth != nil {
+		out.RegistryAuth = make([]v1alpha4.RegistryAuth, len(in.RegistryAuth))
+		for i := range in.RegistryAuth {
+			convertToV1Alpha4RegistryAuth(&in.RegistryAuth[i], &out.RegistryAuth[i])
+		}
+	}
+
+	if in.Networks != nil {
+		out.Networks = make([]v1alpha4.Network, len(in.Networks))
+		for i := range in.Networks {
+			convertToV1Alpha4Network(&in.Networks[i], &out.Networks[i])
+		}
+	}
+
+	if in.LoadBalancer != nil {
+		out.LoadBalancer = &v1alpha4.LoadBalancer{}
+		convertToV1Alpha4LoadBalancer(in.LoadBalancer, out.LoadBalancer)
+	}
+
+	for i := range in.KubeadmConfigPatchesJSON6902 {
+		convertToV1Alpha4PatchJSON6902(&in.KubeadmConfigPatchesJSON6902[i], &out.KubeadmConfigPatchesJSON6902[i])
+	}
+
+	return out
+}
+
+func convertToV1Alpha4Node(in *Node, out *v1alpha4.Node) {
+	out.Role = v1alpha4.NodeRole(in.Role)
+	out.Image = in.Image
+	out.IPv4Address = in.IPv4Address
+	out.IPv6Address = in.IPv6Address
+
+	out.Labels = in.Labels
+	out.KubeadmConfigPatches = in.KubeadmConfigPatches
+	out.ExtraMounts = make([]v1alpha4.Mount, len(in.ExtraMounts))
+	out.ExtraPortMappings = make([]v1alpha4.PortMapping, len(in.ExtraPortMappings))
+	out.KubeadmConfigPatchesJSON6902 = make([]v1alpha4.PatchJSON6902, len(in.KubeadmConfigPatchesJSON6902))
+
+	for i := range in.ExtraMounts {
+		convertToV1Alpha4Mount(&in.ExtraMounts[i], &out.ExtraMounts[i])
+	}
+
+	for i := range in.ExtraPortMappings {
+		convertToV1Alpha4PortMapping(&in.ExtraPortMappings[i], &out.ExtraPortMappings[i])
+	}
+
+	for i := range in.KubeadmConfigPatchesJSON6902 {
+		convertToV1Alpha4PatchJSON6902(&in.KubeadmConfigPatchesJSON6902[i], &out.KubeadmConfigPatchesJSON6902[i])
+	}
+
+	if in.Networks != nil {
+		out.Networks = make([]v1alpha4.NodeNetwork, len(in.Networks))
+		for i := range in.Networks {
+			convertToV1Alpha4NodeNetwork(&in.Networks[i], &out.Networks[i])
+		}
+	}
+}
+
+func convertToV1Alpha4PatchJSON6902(in *PatchJSON6902, out *v1alpha4.PatchJSON6902) {
+	out.Group = in.Group
+	out.Version = in.Version
+	out.Kind = in.Kind
+	out.Patch = in.Patch
+}
+
+func convertToV1Alpha4Networking(in *Networking, out *v1alpha4.Networking) {
+	out.IPFamily = v1alpha4.ClusterIPFamily(in.IPFamily)
+	out.APIServerPort = in.APIServerPort
+	out.APIServerAddress = in.APIServerAddress
+	out.NodeSubnet = in.NodeSubnet
+	out.PodSubnet = in.PodSubnet
+	out.KubeProxyMode = v1alpha4.ProxyMode(in.KubeProxyMode)
+	out.ServiceSubnet = in.ServiceSubnet
+	out.DisableDefaultCNI = in.DisableDefaultCNI
+	out.DNSSearch = in.DNSSearch
+	if in.Kindnet != nil {
+		out.Kindnet = &v1alpha4.Kindnet{}
+		convertToV1Alpha4Kindnet(in.Kindnet, out.Kindnet)
+	}
+}
+
+func convertToV1Alpha4Kindnet(in *Kindnet, out *v1alpha4.Kindnet) {
+	out.MTU = in.MTU
+	out.NoMasqueradeCIDRs = in.NoMasqueradeCIDRs
+	out.ChainedPlugins = in.ChainedPlugins
+	out.Encapsulation = v1alpha4.EncapsulationMode(in.Encapsulation)
+}
+
+func convertToV1Alpha4Registry(in *Registry, out *v1alpha4.Registry) {
+	out.Name = in.Name
+	out.Image = in.Image
+	out.HostPort = in.HostPort
+	out.ListenAddress = in.ListenAddress
+	out.Shared = in.Shared
+}
+
+func convertToV1Alpha4RegistryMirror(in *RegistryMirror, out *v1alpha4.RegistryMirror) {
+	out.Registry = in.Registry
+	out.Endpoints = in.Endpoints
+}
+
+func convertToV1Alpha4RegistryAuth(in *RegistryAuth, out *v1alpha4.RegistryAuth) {
+	out.Host = in.Host
+	out.CAFile = in.CAFile
+	out.InsecureSkipVerify = in.InsecureSkipVerify
+	out.CredentialsFile = in.CredentialsFile
+}
+
+func convertToV1Alpha4Network(in *Network, out *v1alpha4.Network) {
+	out.Name = in.Name
+	out.Subnet = in.Subnet
+	out.MTU = in.MTU
+	out.Internal = in.Internal
+}
+
+func convertToV1Alpha4LoadBalancer(in *LoadBalancer, out *v1alpha4.LoadBalancer) {
+	out.Image = in.Image
+	out.Timeouts = v1alpha4.LoadBalancerTimeouts{
+		Connect:       in.Timeouts.Connect,
+		Client:        in.Timeouts.Client,
+		Server:        in.Timeouts.Server,
+		Check:         in.Timeouts.Check,
+		CheckInterval: in.Timeouts.CheckInterval,
+	}
+	out.VerifyAPIServer = in.VerifyAPIServer
+	if in.ExtraFrontends != nil {
+		out.ExtraFrontends = make([]v1alpha4.LoadBalancerFrontend, len(in.ExtraFrontends))
+		for i := range in.ExtraFrontends {
+			convertToV1Alpha4LoadBalancerFrontend(&in.ExtraFrontends[i], &out.ExtraFrontends[i])
+		}
+	}
+}
+
+func convertToV1Alpha4LoadBalancerFrontend(in *LoadBalancerFrontend, out *v1alpha4.LoadBalancerFrontend) {
+	out.Name = in.Name
+	out.Port = in.Port
+	out.HostPort = in.HostPort
+	out.ListenAddress = in.ListenAddress
+	out.Backend.NodeRole = v1alpha4.NodeRole(in.Backend.NodeRole)
+	out.Backend.Port = in.Backend.Port
+}
+
+func convertToV1Alpha4NodeNetwork(in *NodeNetwork, out *v1alpha4.NodeNetwork) {
+	out.Name = in.Name
+	out.IPv4Address = in.IPv4Address
+	out.IPv6Address = in.IPv6Address
+}
+
+func convertToV1Alpha4Mount(in *Mount, out *v1alpha4.Mount) {
+	out.ContainerPath = in.ContainerPath
+	out.HostPath = in.HostPath
+	out.Readonly = in.Readonly
+	out.SelinuxRelabel = in.SelinuxRelabel
+	out.Propagation = v1alpha4.MountPropagation(in.Propagation)
+}
+
+func convertToV1Alpha4PortMapping(in *PortMapping, out *v1alpha4.PortMapping) {
+	out.ContainerPort = in.ContainerPort
+	out.HostPort = in.HostPort
+	out.ListenAddress = in.ListenAddress
+	out.Protocol = v1alpha4.PortMappingProtocol(in.Protocol)
+}
@@ -40,6 +40,37 @@ func Convertv1alpha4(in *v1alpha4.Cluster) *Cluster {
 
 	convertv1alpha4Networking(&in.Networking, &out.Networking)
 
+	if in.Registry != nil {
+		out.Registry = &Registry{}
+		convertv1alpha4Registry(in.Registry, out.Registry)
+	}
+
+	if in.RegistryMirrors != nil {
+		out.RegistryMirrors = make([]RegistryMirror, len(in.RegistryMirrors))
+		for i := range in.RegistryMirrors {
+			convertv1alpha4RegistryMirror(&in.RegistryMirrors[i], &out.RegistryMirrors[i])
+		}
+	}
+
+	if in.RegistryAuth != nil {
+		out.RegistryAuth = make([]RegistryAuth, len(in.RegistryAuth))
+		for i := range in.RegistryAuth {
+			convertv1alpha4RegistryAuth(&in.RegistryAuth[i], &out.RegistryAuth[i])
+		}
+	}
+
+	if in.Networks != nil {
+		out.Networks = make([]Network, len(in.Networks))
+		for i := range in.Networks {
+			convertv1alpha4Network(&in.Networks[i], &out.Networks[i])
+		}
+	}
+
+	if in.LoadBalancer != nil {
+		out.LoadBalancer = &LoadBalancer{}
+		convertv1alpha4LoadBalancer(in.LoadBalancer, out.LoadBalancer)
+	}
+
 	for i := range in.KubeadmConfigPatchesJSON6902 {
 		convertv1alpha4PatchJSON6902(&in.KubeadmConfigPatchesJSON6902[i], &out.KubeadmConfigPatchesJSON6902[i])
 	}
@@ -50,6 +81,8 @@ func Convertv1alpha4(in *v1alpha4.Cluster) *Cluster {
 func convertv1alpha4Node(in *v1alpha4.Node, out *Node) {
 	out.Role = NodeRole(in.Role)
 	out.Image = in.Image
+	out.IPv4Address = in.IPv4Address
+	out.IPv6Address = in.IPv6Address
 
 	out.Labels = in.Labels
 	out.KubeadmConfigPatches = in.KubeadmConfigPatches
@@ -68,6 +101,13 @@ func convertv1alpha4Node(in *v1alpha4.Node, out *Node) {
 	for i := range in.KubeadmConfigPatchesJSON6902 {
 		convertv1alpha4PatchJSON6902(&in.KubeadmConfigPatchesJSON6902[i], &out.KubeadmConfigPatchesJSON6902[i])
 	}
+
+	if in.Networks != nil {
+		out.Networks = make([]NodeNetwork, len(in.Networks))
+		for i := range in.Networks {
+			convertv1alpha4NodeNetwork(&in.Networks[i], &out.Networks[i])
+		}
+	}
 }
 
 func convertv1alpha4PatchJSON6902(in *v1alpha4.PatchJSON6902, out *PatchJSON6902) {
@@ -81,11 +121,83 @@ func convertv1alpha4Networking(in *v1alpha4.Networking, out *Networking) {
 	out.IPFamily = ClusterIPFamily(in.IPFamily)
 	out.APIServerPort = in.APIServerPort
 	out.APIServerAddress = in.APIServerAddress
+	out.NodeSubnet = in.NodeSubnet
 	out.PodSubnet = in.PodSubnet
 	out.KubeProxyMode = ProxyMode(in.KubeProxyMode)
 	out.ServiceSubnet = in.ServiceSubnet
 	out.DisableDefaultCNI = in.DisableDefaultCNI
 	out.DNSSearch = in.DNSSearch
+	if in.Kindnet != nil {
+		out.Kindnet = &Kindnet{}
+		convertv1alpha4Kindnet(in.Kindnet, out.Kindnet)
+	}
+}
+
+func convertv1alpha4Kindnet(in *v1alpha4.Kindnet, out *Kindnet) {
+	out.MTU = in.MTU
+	out.NoMasqueradeCIDRs = in.NoMasqueradeCIDRs
+	out.ChainedPlugins = in.ChainedPlugins
+	out.Encapsulation = EncapsulationMode(in.Encapsulation)
+}
+
+func convertv1alpha4Registry(in *v1alpha4.Registry, out *Registry) {
+	out.Name = in.Name
+	out.Image = in.Image
+	out.HostPort = in.HostPort
+	out.ListenAddress = in.ListenAddress
+	out.Shared = in.Shared
+}
+
+func convertv1alpha4RegistryMirror(in *v1alpha4.RegistryMirror, out *RegistryMirror) {
+	out.Registry = in.Registry
+	out.Endpoints = in.Endpoints
+}
+
+func convertv1alpha4RegistryAuth(in *v1alpha4.RegistryAuth, out *RegistryAuth) {
+	out.Host = in.Host
+	out.CAFile = in.CAFile
+	out.InsecureSkipVerify = in.InsecureSkipVerify
+	out.CredentialsFile = in.CredentialsFile
+}
+
+func convertv1alpha4Network(in *v1alpha4.Network, out *Network) {
+	out.Name = in.Name
+	out.Subnet = in.Subnet
+	out.MTU = in.MTU
+	out.Internal = in.Internal
+}
+
+func convertv1alpha4LoadBalancer(in *v1alpha4.LoadBalancer, out *LoadBalancer) {
+	out.Image = in.Image
+	out.Timeouts = LoadBalancerTimeouts{
+		Connect:       in.Timeouts.Connect,
+		Client:        in.Timeouts.Client,
+		Server:        in.Timeouts.Server,
+		Check:         in.Timeouts.Check,
+		CheckInterval: in.Timeouts.CheckInterval,
+	}
+	out.VerifyAPIServer = in.VerifyAPIServer
+	if in.ExtraFrontends != nil {
+		out.ExtraFrontends = make([]LoadBalancerFrontend, len(in.ExtraFrontends))
+		for i := range in.ExtraFrontends {
+			convertv1alpha4LoadBalancerFrontend(&in.ExtraFrontends[i], &out.ExtraFrontends[i])
+		}
+	}
+}
+
+func convertv1alpha4LoadBalancerFrontend(in *v1alpha4.LoadBalancerFrontend, out *LoadBalancerFrontend) {
+	out.Name = in.Name
+	out.Port = in.Port
+	out.HostPort = in.HostPort
+	out.ListenAddress = in.ListenAddress
+	out.Backend.NodeRole = NodeRole(in.Backend.NodeRole)
+	out.Backend.Port = in.Backend.Port
+}
+
+func convertv1alpha4NodeNetwork(in *v1alpha4.NodeNetwork, out *NodeNetwork) {
+	out.Name = in.Name
+	out.IPv4Address = in.IPv4Address
+	out.IPv6Address = in.IPv6Address
 }
 
 func convertv1alpha4Mount(in *v1alpha4.Mount, out *Mount) {
@@ -90,6 +90,42 @@ func SetDefaultsCluster(obj *Cluster) {
 	if obj.Networking.KubeProxyMode == "" {
 		obj.Networking.KubeProxyMode = IPTablesProxyMode
 	}
+	// default the registry, if any
+	if obj.Registry != nil {
+		SetDefaultsRegistry(obj.Registry)
+	}
+	// default the load balancer, if configured
+	if obj.LoadBalancer != nil {
+		SetDefaultsLoadBalancer(obj.LoadBalancer)
+	}
+}
+
+// SetDefaultsRegistry sets uninitialized fields to their default value.
+func SetDefaultsRegistry(obj *Registry) {
+	if obj.Name == "" {
+		obj.Name = defaults.RegistryName
+	}
+	if obj.Image == "" {
+		obj.Image = defaults.RegistryImage
+	}
+	if obj.HostPort == 0 {
+		obj.HostPort = defaults.RegistryHostPort
+	}
+	if obj.ListenAddress == "" {
+		obj.ListenAddress = "127.0.0.1"
+	}
+}
+
+// SetDefaultsLoadBalancer sets uninitialized fields to their default value.
+func SetDefaultsLoadBalancer(obj *LoadBalancer) {
+	if obj.Image == "" {
+		obj.Image = defaults.LoadBalancerImage
+	}
+	for i := range obj.ExtraFrontends {
+		if obj.ExtraFrontends[i].Backend.NodeRole == "" {
+			obj.ExtraFrontends[i].Backend.NodeRole = WorkerRole
+		}
+	}
 }
 
 // SetDefaultsNode sets uninitialized fields to their default value.
@@ -0,0 +1,35 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package encoding
+
+import (
+	yaml "gopkg.in/yaml.v3"
+
+	"sigs.k8s.io/kind/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+// Marshal serializes an internal cluster config to yaml at the current
+// public API version, such that Parse(Marshal(cfg)) round-trips
+func Marshal(cfg *config.Cluster) ([]byte, error) {
+	raw, err := yaml.Marshal(config.ConvertToV1Alpha4(cfg))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal cluster config")
+	}
+	return raw, nil
+}
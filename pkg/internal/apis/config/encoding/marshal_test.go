@@ -0,0 +1,72 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package encoding
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMarshalRoundTrip(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		TestName string
+		Path     string
+	}{
+		{
+			TestName: "v1alpha4 minimal",
+			Path:     "./testdata/v1alpha4/valid-minimal.yaml",
+		},
+		{
+			TestName: "v1alpha4 full HA",
+			Path:     "./testdata/v1alpha4/valid-full-ha.yaml",
+		},
+		{
+			TestName: "v1alpha4 many fields set",
+			Path:     "./testdata/v1alpha4/valid-many-fields.yaml",
+		},
+		{
+			TestName: "v1alpha4 config with patches",
+			Path:     "./testdata/v1alpha4/valid-kind-patches.yaml",
+		},
+		{
+			TestName: "v1alpha4 config with port mapping and mount",
+			Path:     "./testdata/v1alpha4/valid-port-and-mount.yaml",
+		},
+	}
+	for _, c := range cases {
+		c := c // capture loop variable
+		t.Run(c.TestName, func(t *testing.T) {
+			t.Parallel()
+			cfg, err := Load(c.Path)
+			if err != nil {
+				t.Fatalf("unexpected error loading config: %v", err)
+			}
+			raw, err := Marshal(cfg)
+			if err != nil {
+				t.Fatalf("unexpected error marshalling config: %v", err)
+			}
+			roundTripped, err := Parse(raw)
+			if err != nil {
+				t.Fatalf("unexpected error parsing marshalled config: %v\n%s", err, raw)
+			}
+			if !reflect.DeepEqual(cfg, roundTripped) {
+				t.Errorf("config did not round trip\nexpected: %+v\nactual: %+v", cfg, roundTripped)
+			}
+		})
+	}
+}
@@ -69,12 +69,127 @@ type Cluster struct {
 	// in the order listed.
 	// These should be YAML or JSON formatting RFC 6902 JSON patches
 	ContainerdConfigPatchesJSON6902 []string
+
+	// Registry configures a local registry container for the cluster
+	Registry *Registry
+
+	// RegistryMirrors configures mirrors for registries on every node
+	RegistryMirrors []RegistryMirror
+
+	// RegistryAuth configures TLS verification and credentials for
+	// registry or mirror hosts on every node
+	RegistryAuth []RegistryAuth
+
+	// Networks are additional networks for the cluster, which nodes are
+	// attached to with their own networks field
+	Networks []Network
+
+	// LoadBalancer configures the external load balancer in front of the
+	// control plane nodes
+	LoadBalancer *LoadBalancer
+}
+
+// LoadBalancer configures the external haproxy load balancer
+type LoadBalancer struct {
+	// Image is the haproxy image of the load balancer
+	Image string
+	// Timeouts are the haproxy timeouts
+	Timeouts LoadBalancerTimeouts
+	// VerifyAPIServer verifies the API servers against the cluster CA
+	VerifyAPIServer bool
+	// ExtraFrontends are additional ports of the load balancer
+	ExtraFrontends []LoadBalancerFrontend
+}
+
+// LoadBalancerTimeouts are haproxy timeouts, as durations such as "5s"
+type LoadBalancerTimeouts struct {
+	Connect       string
+	Client        string
+	Server        string
+	Check         string
+	CheckInterval string
+}
+
+// LoadBalancerFrontend is an additional port of the load balancer
+type LoadBalancerFrontend struct {
+	// Name is the name of the frontend
+	Name string
+	// Port is the port the load balancer listens on
+	Port int32
+	// HostPort is the port on the host, a random port if unset
+	HostPort int32
+	// ListenAddress is the host address the frontend is published on
+	ListenAddress string
+	// Backend is what the frontend balances to
+	Backend LoadBalancerBackend
+}
+
+// LoadBalancerBackend is the nodes a frontend balances to
+type LoadBalancerBackend struct {
+	// NodeRole selects the nodes to balance to
+	NodeRole NodeRole
+	// Port is the port on the nodes
+	Port int32
+}
+
+// Network is an additional network nodes can be attached to
+type Network struct {
+	// Name is the name of the network
+	Name string
+	// Subnet is the CIDR of the network, or an IPv4 and an IPv6 CIDR
+	// separated by a comma for dual-stack
+	Subnet string
+	// MTU is the MTU of the network
+	MTU int32
+	// Internal networks have no external connectivity
+	Internal bool
+}
+
+// RegistryMirror configures mirrors for a registry
+type RegistryMirror struct {
+	// Registry is the registry host to mirror, or "_default"
+	Registry string
+	// Endpoints are the mirror URLs, including the scheme
+	Endpoints []string
+}
+
+// RegistryAuth configures TLS verification and credentials for a registry
+// or mirror host
+type RegistryAuth struct {
+	// Host is the registry or mirror host
+	Host string
+	// CAFile is the path on the host to a PEM encoded CA bundle
+	CAFile string
+	// InsecureSkipVerify disables verifying the host's certificate
+	InsecureSkipVerify bool
+	// CredentialsFile is the path on the host to a docker config.json
+	// style file with credentials for Host
+	CredentialsFile string
+}
+
+// Registry contains settings for a local registry container
+type Registry struct {
+	// Name is the name of the registry container
+	Name string
+	// Image is the registry image
+	Image string
+	// HostPort is the port the registry is published on the host
+	HostPort int32
+	// ListenAddress is the address the registry is published on the host
+	ListenAddress string
+	// Shared registries are not deleted with the cluster
+	Shared bool
 }
 
 // Node contains settings for a node in the `kind` Cluster.
 // A node in kind config represent a container that will be provisioned with all the components
 // required for the assigned role in the Kubernetes cluster
 type Node struct {
+	// Name is the name of the node container, if unset the provider names
+	// it after the cluster and role. It is not part of the config API, kind
+	// records it next to the persisted cluster config.
+	Name string
+
 	// Role defines the role of the node in the in the Kubernetes cluster
 	// created by kind
 	//
@@ -107,6 +222,25 @@ type Node struct {
 	// KubeadmConfigPatchesJSON6902 are applied to the generated kubeadm config
 	// as patchesJson6902 to `kustomize build`
 	KubeadmConfigPatchesJSON6902 []PatchJSON6902
+
+	// Networks are the additional networks from the cluster networks this
+	// node is attached to
+	Networks []NodeNetwork
+
+	// IPv4Address is the static IPv4 address of the node on the node network
+	IPv4Address string
+	// IPv6Address is the static IPv6 address of the node on the node network
+	IPv6Address string
+}
+
+// NodeNetwork attaches a node to an additional network
+type NodeNetwork struct {
+	// Name is the name of a network in the cluster networks
+	Name string
+	// IPv4Address is a static IPv4 address for the node on the network
+	IPv4Address string
+	// IPv6Address is a static IPv6 address for the node on the network
+	IPv6Address string
 }
 
 // NodeRole defines possible role for nodes in a Kubernetes cluster managed by `kind`
@@ -139,6 +273,9 @@ type Networking struct {
 	//
 	// Defaults to 127.0.0.1
 	APIServerAddress string
+	// NodeSubnet is the CIDR of the network the nodes are attached to
+	// The container runtime selects the subnet if unspecified
+	NodeSubnet string
 	// PodSubnet is the CIDR used for pod IPs
 	// kind will select a default if unspecified
 	PodSubnet string
@@ -152,6 +289,29 @@ type Networking struct {
 	KubeProxyMode ProxyMode
 	// DNSSearch defines the DNS search domain to use for nodes. If not set, this will be inherited from the host.
 	DNSSearch *[]string
+	// Kindnet configures the default CNI, it is ignored when DisableDefaultCNI
+	// is true
+	Kindnet *Kindnet
+}
+
+// Kindnet contains options for kindnetd, the default CNI
+type Kindnet struct {
+	// MTU is the MTU of the pod interfaces
+	// Defaults to the MTU of the node's eth0 interface
+	MTU int32
+	// NoMasqueradeCIDRs are destinations that traffic from pods is not
+	// masqueraded to, in addition to the pod subnets
+	NoMasqueradeCIDRs []string
+	// ChainedPlugins are CNI plugin configurations appended to the kindnet
+	// plugin chain, after the ptp and portmap plugins.
+	// Each entry should be an inline JSON object blob-string
+	ChainedPlugins []string
+	// Encapsulation is how traffic to the pods of other nodes is sent, one of
+	// none, vxlan or wireguard. With vxlan or wireguard the node IPs need to be
+	// reachable from each other, but the nodes need not share an L2 network.
+	// wireguard requires the wireguard kernel module on the host.
+	// Defaults to none, routing the traffic directly via the node IPs
+	Encapsulation EncapsulationMode
 }
 
 // ClusterIPFamily defines cluster network IP family
@@ -180,6 +340,19 @@ const (
 	NoneProxyMode ProxyMode = "none"
 )
 
+// EncapsulationMode defines how kindnetd sends traffic to the pods of other
+// nodes
+type EncapsulationMode string
+
+const (
+	// NoEncapsulation sets EncapsulationMode to none
+	NoEncapsulation EncapsulationMode = "none"
+	// VXLANEncapsulation sets EncapsulationMode to vxlan
+	VXLANEncapsulation EncapsulationMode = "vxlan"
+	// WireGuardEncapsulation sets EncapsulationMode to wireguard
+	WireGuardEncapsulation EncapsulationMode = "wireguard"
+)
+
 // PatchJSON6902 represents an inline kustomize json 6902 patch
 // https://tools.ietf.org/html/rfc6902
 type PatchJSON6902 struct {
@@ -17,10 +17,13 @@ limitations under the License.
 package config
 
 import (
+	"encoding/json"
 	"fmt"
 	"net"
+	"net/url"
 	"regexp"
 	"strings"
+	"time"
 
 	"sigs.k8s.io/kind/pkg/errors"
 	"sigs.k8s.io/kind/pkg/internal/sets"
@@ -32,6 +35,9 @@ import (
 // https://godoc.org/github.com/docker/docker/daemon/names#pkg-constants
 var validNameRE = regexp.MustCompile(`^[a-z0-9.-]+$`)
 
+// same as valid docker network names
+var validNetworkNameRE = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
 // Validate returns a ConfigErrors with an entry for each problem
 // with the config, or nil if there are none
 func (c *Cluster) Validate() error {
@@ -67,12 +73,45 @@ func (c *Cluster) Validate() error {
 		errs = append(errs, errors.Errorf("invalid service subnet %v", err))
 	}
 
+	// nodeSubnet should be a valid CIDR that does not overlap the pod and
+	// service subnets, if set
+	var nodeSubnets []*net.IPNet
+	if c.Networking.NodeSubnet != "" {
+		if err := validateSubnets(c.Networking.NodeSubnet, c.Networking.IPFamily); err != nil {
+			errs = append(errs, errors.Errorf("invalid node subnet %v", err))
+		} else {
+			nodeSubnets, _ = parseNetworkSubnets(c.Networking.NodeSubnet)
+			for _, subnet := range nodeSubnets {
+				if _, err := dynamicIPRange(subnet); err != nil {
+					errs = append(errs, errors.Errorf("invalid node subnet %v", err))
+				}
+			}
+			for _, other := range []struct{ name, subnet string }{
+				{"pod", c.Networking.PodSubnet},
+				{"service", c.Networking.ServiceSubnet},
+			} {
+				// invalid subnets are reported above
+				otherSubnets, _ := parseNetworkSubnets(other.subnet)
+				if subnetsOverlap(nodeSubnets, otherSubnets) {
+					errs = append(errs, errors.Errorf("node subnet %s overlaps the %s subnet %s", c.Networking.NodeSubnet, other.name, other.subnet))
+				}
+			}
+		}
+	}
+
 	// KubeProxyMode should be iptables or ipvs
 	if c.Networking.KubeProxyMode != IPTablesProxyMode && c.Networking.KubeProxyMode != IPVSProxyMode &&
 		c.Networking.KubeProxyMode != NoneProxyMode && c.Networking.KubeProxyMode != NFTablesProxyMode {
 		errs = append(errs, errors.Errorf("invalid kubeProxyMode: %s", c.Networking.KubeProxyMode))
 	}
 
+	// validate the kindnet options, if any
+	if c.Networking.Kindnet != nil {
+		if err := c.Networking.Kindnet.Validate(); err != nil {
+			errs = append(errs, errors.Errorf("invalid kindnet configuration: %v", err))
+		}
+	}
+
 	// validate nodes
 	numByRole := make(map[NodeRole]int32)
 	// All nodes in the config should be valid
@@ -89,18 +128,468 @@ func (c *Cluster) Validate() error {
 		}
 	}
 
+	// validate the static node addresses, which must be unique and in the
+	// node subnet
+	nodeAddresses := sets.NewString()
+	for i := range c.Nodes {
+		for _, address := range []struct {
+			field, value string
+			ipv6         bool
+		}{
+			{"ipv4Address", c.Nodes[i].IPv4Address, false},
+			{"ipv6Address", c.Nodes[i].IPv6Address, true},
+		} {
+			if address.value == "" {
+				continue
+			}
+			ip := net.ParseIP(address.value)
+			if ip == nil || (ip.To4() == nil) != address.ipv6 {
+				errs = append(errs, errors.Errorf("invalid configuration for node %d: invalid %s %q", i, address.field, address.value))
+				continue
+			}
+			if c.Networking.NodeSubnet == "" {
+				errs = append(errs, errors.Errorf("invalid configuration for node %d: %s requires the node subnet to be set", i, address.field))
+				continue
+			}
+			if err := validateStaticAddress(address.field, ip, nodeSubnets); err != nil {
+				errs = append(errs, errors.Errorf("invalid configuration for node %d: %v %s", i, err, c.Networking.NodeSubnet))
+				continue
+			}
+			if nodeAddresses.Has(ip.String()) {
+				errs = append(errs, errors.Errorf("duplicate node address %s", address.value))
+			}
+			nodeAddresses.Insert(ip.String())
+		}
+	}
+
 	// there must be at least one control plane node
 	numControlPlane, anyControlPlane := numByRole[ControlPlaneRole]
 	if !anyControlPlane || numControlPlane < 1 {
 		errs = append(errs, errors.Errorf("must have at least one %s node", string(ControlPlaneRole)))
 	}
 
+	// validate the registry, if any
+	if c.Registry != nil {
+		if err := c.Registry.Validate(); err != nil {
+			errs = append(errs, errors.Errorf("invalid registry configuration: %v", err))
+		}
+	}
+
+	// validate registry mirrors and auth, each registry or host may only
+	// be configured once
+	mirrored := sets.NewString()
+	for i := range c.RegistryMirrors {
+		m := &c.RegistryMirrors[i]
+		if err := m.Validate(); err != nil {
+			errs = append(errs, errors.Errorf("invalid registry mirror configuration %d: %v", i, err))
+		} else if mirrored.Has(m.Registry) {
+			errs = append(errs, errors.Errorf("duplicate registry mirror configuration for %q", m.Registry))
+		}
+		mirrored.Insert(m.Registry)
+	}
+	authed := sets.NewString()
+	for i := range c.RegistryAuth {
+		a := &c.RegistryAuth[i]
+		if err := a.Validate(); err != nil {
+			errs = append(errs, errors.Errorf("invalid registry auth configuration %d: %v", i, err))
+		} else if authed.Has(a.Host) {
+			errs = append(errs, errors.Errorf("duplicate registry auth configuration for %q", a.Host))
+		}
+		authed.Insert(a.Host)
+	}
+
+	// validate the additional networks, each network may only be
+	// configured once
+	networks := map[string]*Network{}
+	for i := range c.Networks {
+		n := &c.Networks[i]
+		if err := n.Validate(); err != nil {
+			errs = append(errs, errors.Errorf("invalid network configuration %d: %v", i, err))
+		} else if _, ok := networks[n.Name]; ok {
+			errs = append(errs, errors.Errorf("duplicate network configuration for %q", n.Name))
+		}
+		networks[n.Name] = n
+	}
+
+	// validate the load balancer, which only exists with multiple control
+	// plane nodes
+	if c.LoadBalancer != nil {
+		if err := c.LoadBalancer.Validate(); err != nil {
+			errs = append(errs, errors.Errorf("invalid loadBalancer configuration: %v", err))
+		}
+		if len(c.LoadBalancer.ExtraFrontends) > 0 && !ClusterHasImplicitLoadBalancer(c) {
+			errs = append(errs, errors.New("loadBalancer.extraFrontends requires multiple control-plane nodes"))
+		}
+	}
+
+	// validate the node attachments to the additional networks, static
+	// addresses may only be used once per network
+	addresses := sets.NewString()
+	for i := range c.Nodes {
+		attached := sets.NewString()
+		for _, nn := range c.Nodes[i].Networks {
+			if err := nn.validate(networks[nn.Name]); err != nil {
+				errs = append(errs, errors.Errorf("invalid network configuration for node %d: %v", i, err))
+				continue
+			}
+			if attached.Has(nn.Name) {
+				errs = append(errs, errors.Errorf("node %d is attached to network %q more than once", i, nn.Name))
+			}
+			attached.Insert(nn.Name)
+			for _, address := range []string{nn.IPv4Address, nn.IPv6Address} {
+				if address == "" {
+					continue
+				}
+				key := nn.Name + "/" + net.ParseIP(address).String()
+				if addresses.Has(key) {
+					errs = append(errs, errors.Errorf("duplicate address %s on network %q", address, nn.Name))
+				}
+				addresses.Insert(key)
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.NewAggregate(errs)
+	}
+	return nil
+}
+
+// Validate returns a ConfigErrors with an entry for each problem
+// with the Registry, or nil if there are none
+func (r *Registry) Validate() error {
+	errs := []error{}
+
+	if !validNameRE.MatchString(r.Name) {
+		errs = append(errs, errors.Errorf("'%s' is not a valid registry name, registry names must match `%s`",
+			r.Name, validNameRE.String()))
+	}
+
+	// image should be defined
+	if r.Image == "" {
+		errs = append(errs, errors.New("image is a required field"))
+	}
+
+	if err := validatePort(r.HostPort); err != nil {
+		errs = append(errs, errors.Wrapf(err, "invalid hostPort"))
+	}
+
+	if net.ParseIP(r.ListenAddress) == nil {
+		errs = append(errs, errors.Errorf("invalid listenAddress: %s", r.ListenAddress))
+	}
+
+	if len(errs) > 0 {
+		return errors.NewAggregate(errs)
+	}
+	return nil
+}
+
+// Validate returns a ConfigErrors with an entry for each problem
+// with the RegistryMirror, or nil if there are none
+func (m *RegistryMirror) Validate() error {
+	errs := []error{}
+
+	if err := validateRegistryHost(m.Registry); err != nil {
+		errs = append(errs, errors.Wrapf(err, "invalid registry"))
+	}
+
+	if len(m.Endpoints) == 0 {
+		errs = append(errs, errors.New("must have at least one endpoint"))
+	}
+	for _, endpoint := range m.Endpoints {
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "invalid endpoint %q", endpoint))
+			continue
+		}
+		if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, errors.Errorf("invalid endpoint %q, endpoints must be http:// or https:// URLs", endpoint))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.NewAggregate(errs)
+	}
+	return nil
+}
+
+// Validate returns a ConfigErrors with an entry for each problem
+// with the RegistryAuth, or nil if there are none
+func (a *RegistryAuth) Validate() error {
+	errs := []error{}
+
+	if err := validateRegistryHost(a.Host); err != nil {
+		errs = append(errs, errors.Wrapf(err, "invalid host"))
+	}
+
+	if a.CAFile == "" && !a.InsecureSkipVerify && a.CredentialsFile == "" {
+		errs = append(errs, errors.New("at least one of caFile, insecureSkipVerify or credentialsFile must be set"))
+	}
+
+	if len(errs) > 0 {
+		return errors.NewAggregate(errs)
+	}
+	return nil
+}
+
+// Validate returns a ConfigErrors with an entry for each problem
+// with the Kindnet options, or nil if there are none
+func (k *Kindnet) Validate() error {
+	errs := []error{}
+
+	if err := validateMTU(k.MTU); err != nil {
+		errs = append(errs, err)
+	}
+
+	for _, cidr := range k.NoMasqueradeCIDRs {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			errs = append(errs, errors.Errorf("invalid noMasqueradeCIDRs entry %q: %v", cidr, err))
+		}
+	}
+
+	switch k.Encapsulation {
+	case "", NoEncapsulation, VXLANEncapsulation, WireGuardEncapsulation:
+	default:
+		errs = append(errs, errors.Errorf("invalid encapsulation: %s", k.Encapsulation))
+	}
+
+	for i, plugin := range k.ChainedPlugins {
+		conf := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(plugin), &conf); err != nil {
+			errs = append(errs, errors.Errorf("invalid chainedPlugins entry %d, expected a JSON object: %v", i, err))
+			continue
+		}
+		if pluginType, ok := conf["type"].(string); !ok || pluginType == "" {
+			errs = append(errs, errors.Errorf("invalid chainedPlugins entry %d, type is a required field", i))
+		}
+	}
+
+	// TODO: accept these once the node images ship a kindnetd implementing
+	// them, the kindnetd release they pin ignores them
+	if k.Encapsulation == VXLANEncapsulation || k.Encapsulation == WireGuardEncapsulation {
+		errs = append(errs, errors.Errorf("encapsulation %s is not supported by the kindnetd in the node images yet", k.Encapsulation))
+	}
+	if k.MTU != 0 {
+		errs = append(errs, errors.New("mtu is not supported by the kindnetd in the node images yet"))
+	}
+	if len(k.NoMasqueradeCIDRs) > 0 {
+		errs = append(errs, errors.New("noMasqueradeCIDRs is not supported by the kindnetd in the node images yet"))
+	}
+	if len(k.ChainedPlugins) > 0 {
+		errs = append(errs, errors.New("chainedPlugins is not supported by the kindnetd in the node images yet"))
+	}
+
+	if len(errs) > 0 {
+		return errors.NewAggregate(errs)
+	}
+	return nil
+}
+
+// Validate returns a ConfigErrors with an entry for each problem
+// with the LoadBalancer, or nil if there are none
+func (l *LoadBalancer) Validate() error {
+	errs := []error{}
+
+	// image should be defined
+	if l.Image == "" {
+		errs = append(errs, errors.New("image is a required field"))
+	}
+
+	for _, timeout := range []struct {
+		field string
+		value string
+	}{
+		{"connect", l.Timeouts.Connect},
+		{"client", l.Timeouts.Client},
+		{"server", l.Timeouts.Server},
+		{"check", l.Timeouts.Check},
+		{"checkInterval", l.Timeouts.CheckInterval},
+	} {
+		if timeout.value == "" {
+			continue
+		}
+		// haproxy timeouts have millisecond resolution
+		if d, err := time.ParseDuration(timeout.value); err != nil || d < time.Millisecond {
+			errs = append(errs, errors.Errorf("invalid timeouts.%s %q, must be a duration of at least 1ms", timeout.field, timeout.value))
+		}
+	}
+
+	names := sets.NewString()
+	ports := sets.NewString()
+	for i := range l.ExtraFrontends {
+		f := &l.ExtraFrontends[i]
+		if !validNameRE.MatchString(f.Name) || f.Name == "control-plane" {
+			errs = append(errs, errors.Errorf("'%s' is not a valid frontend name, frontend names must match `%s` and may not be control-plane",
+				f.Name, validNameRE.String()))
+		} else if names.Has(f.Name) {
+			errs = append(errs, errors.Errorf("duplicate frontend %q", f.Name))
+		}
+		names.Insert(f.Name)
+		// the load balancer listens for the API server on 6443
+		if f.Port < 1 || f.Port > 65535 || f.Port == 6443 {
+			errs = append(errs, errors.Errorf("invalid port %d for frontend %q, must be between 1 and 65535 and not 6443", f.Port, f.Name))
+		} else if ports.Has(fmt.Sprint(f.Port)) {
+			errs = append(errs, errors.Errorf("duplicate port %d for frontend %q", f.Port, f.Name))
+		}
+		ports.Insert(fmt.Sprint(f.Port))
+		if err := validatePort(f.HostPort); err != nil {
+			errs = append(errs, errors.Wrapf(err, "invalid hostPort for frontend %q", f.Name))
+		}
+		if f.ListenAddress != "" && net.ParseIP(f.ListenAddress) == nil {
+			errs = append(errs, errors.Errorf("invalid listenAddress for frontend %q: %s", f.Name, f.ListenAddress))
+		}
+		if f.Backend.NodeRole != ControlPlaneRole && f.Backend.NodeRole != WorkerRole {
+			errs = append(errs, errors.Errorf("invalid backend nodeRole %q for frontend %q, must be one of: %s, %s",
+				f.Backend.NodeRole, f.Name, ControlPlaneRole, WorkerRole))
+		}
+		if f.Backend.Port < 1 || f.Backend.Port > 65535 {
+			errs = append(errs, errors.Errorf("invalid backend port %d for frontend %q", f.Backend.Port, f.Name))
+		}
+	}
+
 	if len(errs) > 0 {
 		return errors.NewAggregate(errs)
 	}
 	return nil
 }
 
+// Validate returns a ConfigErrors with an entry for each problem
+// with the Network, or nil if there are none
+func (n *Network) Validate() error {
+	errs := []error{}
+
+	if !validNetworkNameRE.MatchString(n.Name) {
+		errs = append(errs, errors.Errorf("'%s' is not a valid network name, network names must match `%s`",
+			n.Name, validNetworkNameRE.String()))
+	}
+
+	if n.Subnet != "" {
+		subnets, err := parseNetworkSubnets(n.Subnet)
+		if err != nil {
+			errs = append(errs, errors.Errorf("invalid subnet %v", err))
+		}
+		for _, subnet := range subnets {
+			if _, err := dynamicIPRange(subnet); err != nil {
+				errs = append(errs, errors.Errorf("invalid subnet %v", err))
+			}
+		}
+	}
+
+	if err := validateMTU(n.MTU); err != nil {
+		errs = append(errs, err)
+	}
+
+	if len(errs) > 0 {
+		return errors.NewAggregate(errs)
+	}
+	return nil
+}
+
+// validate checks the attachment to network, which is nil if there is
+// no such network in the cluster
+func (nn *NodeNetwork) validate(network *Network) error {
+	if network == nil {
+		return errors.Errorf("unknown network %q, networks must be configured in the cluster networks", nn.Name)
+	}
+	errs := []error{}
+	// the subnets are validated with the network
+	subnets, _ := parseNetworkSubnets(network.Subnet)
+	for _, address := range []struct {
+		field, value string
+		ipv6         bool
+	}{
+		{"ipv4Address", nn.IPv4Address, false},
+		{"ipv6Address", nn.IPv6Address, true},
+	} {
+		if address.value == "" {
+			continue
+		}
+		ip := net.ParseIP(address.value)
+		if ip == nil || (ip.To4() == nil) != address.ipv6 {
+			errs = append(errs, errors.Errorf("invalid %s %q", address.field, address.value))
+			continue
+		}
+		if network.Subnet == "" {
+			errs = append(errs, errors.Errorf("%s requires the subnet of network %q to be set", address.field, nn.Name))
+			continue
+		}
+		if err := validateStaticAddress(address.field, ip, subnets); err != nil {
+			errs = append(errs, errors.Errorf("%v of network %q", err, nn.Name))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.NewAggregate(errs)
+	}
+	return nil
+}
+
+// validateStaticAddress checks that ip, the address in field, is in one of
+// subnets and is not reserved for the subnet itself or its gateway, or in
+// the range the container runtime assigns dynamically
+func validateStaticAddress(field string, ip net.IP, subnets []*net.IPNet) error {
+	for _, subnet := range subnets {
+		if !subnet.Contains(ip) {
+			continue
+		}
+		// the first address of the subnet is used by the gateway
+		gateway := subnet.IP.Mask(subnet.Mask)
+		gateway[len(gateway)-1]++
+		if ip.Equal(subnet.IP.Mask(subnet.Mask)) || ip.Equal(gateway) {
+			return errors.Errorf("%s %s is reserved in the subnet", field, ip)
+		}
+		// too small subnets are reported with the subnet
+		if ipRange, err := dynamicIPRange(subnet); err == nil && ipRange.Contains(ip) {
+			return errors.Errorf("%s %s is in %s, the upper half of the subnet that is assigned dynamically", field, ip, ipRange)
+		}
+		return nil
+	}
+	return errors.Errorf("%s %s is not in the subnet", field, ip)
+}
+
+// parseNetworkSubnets parses the comma separated CIDRs of a network, at most
+// one per IP family
+func parseNetworkSubnets(subnetStr string) ([]*net.IPNet, error) {
+	subnets := []*net.IPNet{}
+	for _, cidrString := range strings.Split(subnetStr, ",") {
+		_, cidr, err := net.ParseCIDR(cidrString)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse cidr value:%q with error: %v", cidrString, err)
+		}
+		subnets = append(subnets, cidr)
+	}
+	if len(subnets) > 2 {
+		return nil, errors.New("expected one (IPv4 or IPv6) CIDR or two CIDRs from each family for dual-stack networking")
+	}
+	if len(subnets) == 2 {
+		if dual, _ := isDualStackCIDRs(subnets); !dual {
+			return nil, errors.New("expected one (IPv4 or IPv6) CIDR or two CIDRs from each family for dual-stack networking")
+		}
+	}
+	return subnets, nil
+}
+
+// validateMTU checks that mtu is unset or a usable MTU
+func validateMTU(mtu int32) error {
+	// 576 is the smallest MTU every IPv4 host must support
+	if mtu != 0 && (mtu < 576 || mtu > 65535) {
+		return errors.Errorf("invalid mtu %d, must be between 576 and 65535", mtu)
+	}
+	return nil
+}
+
+// validateRegistryHost checks that host is a registry host with an
+// optional port, like "docker.io" or "localhost:5000", and not a URL
+func validateRegistryHost(host string) error {
+	if host == "" {
+		return errors.New("registry host must not be empty")
+	}
+	if strings.ContainsAny(host, "/ ") {
+		return errors.Errorf("%q is not a registry host, expected a host with an optional port", host)
+	}
+	return nil
+}
+
 // Validate returns a ConfigErrors with an entry for each problem
 // with the Node, or nil if there are none
 func (n *Node) Validate() error {
@@ -255,6 +744,18 @@ func validateSubnets(subnetStr string, ipFamily ClusterIPFamily) error {
 	return nil
 }
 
+// subnetsOverlap returns true if any subnet in a overlaps any subnet in b
+func subnetsOverlap(a, b []*net.IPNet) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if x.Contains(y.IP) || y.Contains(x.IP) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // isDualStackCIDRs returns if
 // - all are valid cidrs
 // - at least one cidr from each family (v4 or v6)
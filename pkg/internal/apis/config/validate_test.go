@@ -258,6 +258,316 @@ func TestClusterValidate(t *testing.T) {
 			}(),
 			ExpectErrors: 1,
 		},
+		{
+			Name: "defaulted registry",
+			Cluster: func() Cluster {
+				c := Cluster{Registry: &Registry{}}
+				SetDefaultsCluster(&c)
+				return c
+			}(),
+		},
+		{
+			Name: "bogus registry",
+			Cluster: func() Cluster {
+				c := Cluster{Registry: &Registry{}}
+				SetDefaultsCluster(&c)
+				c.Registry.HostPort = 9999999
+				return c
+			}(),
+			ExpectErrors: 1,
+		},
+		{
+			Name: "registry mirrors and auth",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				SetDefaultsCluster(&c)
+				c.RegistryMirrors = []RegistryMirror{{
+					Registry:  "docker.io",
+					Endpoints: []string{"https://mirror.example.com:5000"},
+				}}
+				c.RegistryAuth = []RegistryAuth{{
+					Host:   "mirror.example.com:5000",
+					CAFile: "/etc/ssl/certs/mirror-ca.pem",
+				}}
+				return c
+			}(),
+		},
+		{
+			Name: "bogus registry mirror endpoint",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				SetDefaultsCluster(&c)
+				c.RegistryMirrors = []RegistryMirror{{
+					Registry:  "docker.io",
+					Endpoints: []string{"mirror.example.com:5000"},
+				}}
+				return c
+			}(),
+			ExpectErrors: 1,
+		},
+		{
+			Name: "duplicate registry mirror",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				SetDefaultsCluster(&c)
+				m := RegistryMirror{
+					Registry:  "docker.io",
+					Endpoints: []string{"https://mirror.example.com"},
+				}
+				c.RegistryMirrors = []RegistryMirror{m, m}
+				return c
+			}(),
+			ExpectErrors: 1,
+		},
+		{
+			Name: "empty registry auth",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				SetDefaultsCluster(&c)
+				c.RegistryAuth = []RegistryAuth{{Host: "https://mirror.example.com"}}
+				return c
+			}(),
+			ExpectErrors: 1,
+		},
+		{
+			Name: "kindnet options",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				SetDefaultsCluster(&c)
+				c.Networking.Kindnet = &Kindnet{
+					Encapsulation: NoEncapsulation,
+				}
+				return c
+			}(),
+		},
+		{
+			Name: "kindnet encapsulation unsupported by the node images",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				SetDefaultsCluster(&c)
+				c.Networking.Kindnet = &Kindnet{
+					Encapsulation: VXLANEncapsulation,
+				}
+				return c
+			}(),
+			ExpectErrors: 1,
+		},
+		{
+			Name: "kindnet options unsupported by the node images",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				SetDefaultsCluster(&c)
+				c.Networking.Kindnet = &Kindnet{
+					MTU:               1400,
+					NoMasqueradeCIDRs: []string{"172.18.0.0/16", "fc00:f853:ccd:e793::/64"},
+					ChainedPlugins: []string{
+						`{"type": "bandwidth", "capabilities": {"bandwidth": true}}`,
+						`{"type": "tuning", "sysctl": {"net.core.somaxconn": "500"}}`,
+					},
+				}
+				return c
+			}(),
+			ExpectErrors: 1,
+		},
+		{
+			Name: "bogus kindnet options",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				SetDefaultsCluster(&c)
+				c.Networking.Kindnet = &Kindnet{
+					MTU:               100,
+					NoMasqueradeCIDRs: []string{"172.18.0.0"},
+					ChainedPlugins:    []string{`{"capabilities": {"bandwidth": true}}`, "bandwidth"},
+					Encapsulation:     "ipip",
+				}
+				return c
+			}(),
+			ExpectErrors: 1,
+		},
+		{
+			Name: "networks",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				SetDefaultsCluster(&c)
+				c.Networks = []Network{
+					{Name: "storage", Subnet: "192.168.100.0/24,fd00:100::/64", MTU: 9000, Internal: true},
+					{Name: "management"},
+				}
+				c.Nodes[0].Networks = []NodeNetwork{
+					{Name: "storage", IPv4Address: "192.168.100.10", IPv6Address: "fd00:100::10"},
+					{Name: "management"},
+				}
+				return c
+			}(),
+		},
+		{
+			Name: "bogus networks",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				SetDefaultsCluster(&c)
+				c.Networks = []Network{
+					{Name: "storage", Subnet: "192.168.100.0/24"},
+					{Name: "storage"},
+					{Name: "bad/name", Subnet: "10.0.0.0/8,10.1.0.0/16"},
+				}
+				return c
+			}(),
+			ExpectErrors: 2,
+		},
+		{
+			Name: "bogus node networks",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				SetDefaultsCluster(&c)
+				c.Networks = []Network{
+					{Name: "storage", Subnet: "192.168.100.0/24"},
+					{Name: "management"},
+				}
+				n := newDefaultedNode(WorkerRole)
+				n.Networks = []NodeNetwork{
+					{Name: "storage", IPv4Address: "192.168.100.10"},
+				}
+				c.Nodes = append(c.Nodes, n, n)
+				c.Nodes[0].Networks = []NodeNetwork{
+					{Name: "unknown"},
+					{Name: "storage", IPv4Address: "10.0.0.1", IPv6Address: "192.168.100.11"},
+					{Name: "management", IPv4Address: "172.20.0.10"},
+				}
+				return c
+			}(),
+			// unknown network, bogus storage attachment, management without
+			// a subnet and the duplicate storage address
+			ExpectErrors: 4,
+		},
+		{
+			Name: "node subnet",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				SetDefaultsCluster(&c)
+				c.Networking.IPFamily = DualStackFamily
+				c.Networking.NodeSubnet = "172.30.0.0/24,fd00:30::/64"
+				c.Networking.PodSubnet = "10.244.0.0/16,fd00:10:244::/56"
+				c.Networking.ServiceSubnet = "10.96.0.0/16,fd00:10:96::/112"
+				c.Nodes[0].IPv4Address = "172.30.0.10"
+				c.Nodes[0].IPv6Address = "fd00:30::10"
+				c.Nodes = append(c.Nodes, newDefaultedNode(WorkerRole))
+				return c
+			}(),
+		},
+		{
+			Name: "bogus node subnet",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				SetDefaultsCluster(&c)
+				c.Networking.NodeSubnet = "10.244.128.0/24"
+				return c
+			}(),
+			ExpectErrors: 1,
+		},
+		{
+			Name: "bogus node addresses",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				SetDefaultsCluster(&c)
+				c.Networking.NodeSubnet = "172.30.0.0/24"
+				n := newDefaultedNode(WorkerRole)
+				n.IPv4Address = "172.30.0.10"
+				c.Nodes = append(c.Nodes, n, n)
+				c.Nodes[0].IPv4Address = "172.30.0.1"
+				c.Nodes[0].IPv6Address = "fd00:30::10"
+				c.Nodes[1].IPv6Address = "172.30.0.11"
+				return c
+			}(),
+			// gateway address, address outside of the subnet, IPv4 address
+			// as ipv6Address and the duplicate address
+			ExpectErrors: 4,
+		},
+		{
+			Name: "node addresses in the dynamic range",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				SetDefaultsCluster(&c)
+				c.Networking.NodeSubnet = "172.30.0.0/24"
+				c.Nodes[0].IPv4Address = "172.30.0.200"
+				return c
+			}(),
+			ExpectErrors: 1,
+		},
+		{
+			Name: "node subnet too small for static addresses",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				SetDefaultsCluster(&c)
+				c.Networking.NodeSubnet = "172.30.0.0/30"
+				return c
+			}(),
+			ExpectErrors: 1,
+		},
+		{
+			Name: "load balancer",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				SetDefaultsCluster(&c)
+				c.Nodes = append(c.Nodes, newDefaultedNode(ControlPlaneRole), newDefaultedNode(WorkerRole))
+				c.LoadBalancer = &LoadBalancer{
+					VerifyAPIServer: true,
+					Timeouts: LoadBalancerTimeouts{
+						Connect:       "1s",
+						CheckInterval: "500ms",
+					},
+					ExtraFrontends: []LoadBalancerFrontend{{
+						Name:     "ingress",
+						Port:     80,
+						HostPort: 8080,
+						Backend:  LoadBalancerBackend{Port: 30080},
+					}},
+				}
+				SetDefaultsLoadBalancer(c.LoadBalancer)
+				return c
+			}(),
+			ExpectErrors: 0,
+		},
+		{
+			Name: "load balancer frontends without load balancer",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				SetDefaultsCluster(&c)
+				c.LoadBalancer = &LoadBalancer{
+					ExtraFrontends: []LoadBalancerFrontend{{
+						Name:    "ingress",
+						Port:    80,
+						Backend: LoadBalancerBackend{Port: 30080},
+					}},
+				}
+				SetDefaultsLoadBalancer(c.LoadBalancer)
+				return c
+			}(),
+			ExpectErrors: 1,
+		},
+		{
+			Name: "bogus load balancer",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				SetDefaultsCluster(&c)
+				c.Nodes = append(c.Nodes, newDefaultedNode(ControlPlaneRole))
+				c.LoadBalancer = &LoadBalancer{
+					Timeouts: LoadBalancerTimeouts{
+						Server: "50",
+					},
+					ExtraFrontends: []LoadBalancerFrontend{
+						{
+							Name:    "control-plane",
+							Port:    6443,
+							Backend: LoadBalancerBackend{Port: 30080},
+						},
+					},
+				}
+				SetDefaultsLoadBalancer(c.LoadBalancer)
+				return c
+			}(),
+			// the errors of the load balancer are aggregated
+			ExpectErrors: 1,
+		},
 	}
 
 	for _, tc := range cases {
@@ -0,0 +1,181 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/log"
+)
+
+// EventType is the type of an Event
+type EventType string
+
+const (
+	// EventTypeStart is recorded when an action starts
+	EventTypeStart EventType = "start"
+	// EventTypeEnd is recorded when an action ends, successfully or not
+	EventTypeEnd EventType = "end"
+	// EventTypeLog is recorded for each log message
+	EventTypeLog EventType = "log"
+)
+
+// Event is a machine readable CLI event
+type Event struct {
+	Time time.Time `json:"time"`
+	Type EventType `json:"type"`
+	// Action is the name of the action for start and end events
+	Action string `json:"action,omitempty"`
+	// DurationSeconds is how long the action took, for end events
+	DurationSeconds float64 `json:"durationSeconds,omitempty"`
+	// Error is the error the action failed with, for end events
+	Error string `json:"error,omitempty"`
+	// Level is the log level for log events, one of info, warning or error
+	Level string `json:"level,omitempty"`
+	// Message is the message for log events
+	Message string `json:"message,omitempty"`
+}
+
+// EventLogger is a log.Logger that writes every message as a JSON Event,
+// one per line. Verbosity is delegated to the wrapped logger.
+type EventLogger struct {
+	logger log.Logger
+	mu     sync.Mutex
+	writer io.Writer
+	now    func() time.Time
+}
+
+var _ log.Logger = &EventLogger{}
+
+// NewEventLogger returns a new EventLogger writing to writer, with the
+// same verbosity as logger
+func NewEventLogger(writer io.Writer, logger log.Logger) *EventLogger {
+	return &EventLogger{
+		logger: logger,
+		writer: writer,
+		now:    time.Now,
+	}
+}
+
+func (l *EventLogger) write(e Event) {
+	e.Time = l.now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	// an Event can always be encoded, and there is nowhere to report a
+	// failure to write a log message
+	_ = json.NewEncoder(l.writer).Encode(e)
+}
+
+func (l *EventLogger) log(level, message string) {
+	l.write(Event{
+		Type:    EventTypeLog,
+		Level:   level,
+		Message: strings.TrimSpace(message),
+	})
+}
+
+// Warn is part of the log.Logger interface
+func (l *EventLogger) Warn(message string) {
+	l.log("warning", message)
+}
+
+// Warnf is part of the log.Logger interface
+func (l *EventLogger) Warnf(format string, args ...interface{}) {
+	l.log("warning", fmt.Sprintf(format, args...))
+}
+
+// Error is part of the log.Logger interface
+func (l *EventLogger) Error(message string) {
+	l.log("error", message)
+}
+
+// Errorf is part of the log.Logger interface
+func (l *EventLogger) Errorf(format string, args ...interface{}) {
+	l.log("error", fmt.Sprintf(format, args...))
+}
+
+// V is part of the log.Logger interface
+func (l *EventLogger) V(level log.Level) log.InfoLogger {
+	return eventInfoLogger{
+		logger:  l,
+		enabled: l.logger.V(level).Enabled(),
+	}
+}
+
+// eventInfoLogger implements log.InfoLogger for EventLogger
+type eventInfoLogger struct {
+	logger  *EventLogger
+	enabled bool
+}
+
+func (i eventInfoLogger) Enabled() bool {
+	return i.enabled
+}
+
+func (i eventInfoLogger) Info(message string) {
+	if i.enabled {
+		i.logger.log("info", message)
+	}
+}
+
+func (i eventInfoLogger) Infof(format string, args ...interface{}) {
+	if i.enabled {
+		i.logger.log("info", fmt.Sprintf(format, args...))
+	}
+}
+
+// Events records the start and end of actions, it only records anything
+// when obtained from an EventLogger
+type Events struct {
+	logger *EventLogger
+}
+
+// EventsForLogger returns a new Events for the logger l, events are only
+// written if l is an EventLogger
+func EventsForLogger(l log.Logger) *Events {
+	v, _ := l.(*EventLogger)
+	return &Events{logger: v}
+}
+
+// Start records the start of action and returns a function that records
+// the end of action with err, which may be nil
+func (e *Events) Start(action string) func(err error) {
+	if e.logger == nil {
+		return func(error) {}
+	}
+	start := e.logger.now()
+	e.logger.write(Event{
+		Type:   EventTypeStart,
+		Action: action,
+	})
+	return func(err error) {
+		end := Event{
+			Type:            EventTypeEnd,
+			Action:          action,
+			DurationSeconds: e.logger.now().Sub(start).Seconds(),
+		}
+		if err != nil {
+			end.Error = err.Error()
+		}
+		e.logger.write(end)
+	}
+}
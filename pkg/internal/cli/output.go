@@ -0,0 +1,64 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"encoding/json"
+	"io"
+
+	"sigs.k8s.io/yaml"
+
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+// Machine readable output formats
+const (
+	OutputFormatJSON = "json"
+	OutputFormatYAML = "yaml"
+)
+
+// ValidateOutputFormat returns an error if format is neither empty, for
+// the default human readable output, nor one of allowed
+func ValidateOutputFormat(format string, allowed ...string) error {
+	if format == "" {
+		return nil
+	}
+	for _, a := range allowed {
+		if format == a {
+			return nil
+		}
+	}
+	return errors.Errorf("unsupported output format %q, must be one of %v", format, allowed)
+}
+
+// PrintOutput writes v to w in the machine readable output format
+func PrintOutput(w io.Writer, format string, v interface{}) error {
+	switch format {
+	case OutputFormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return errors.Wrap(enc.Encode(v), "failed to write output")
+	case OutputFormatYAML:
+		out, err := yaml.Marshal(v)
+		if err != nil {
+			return errors.Wrap(err, "failed to write output")
+		}
+		_, err = w.Write(out)
+		return errors.Wrap(err, "failed to write output")
+	}
+	return errors.Errorf("unsupported output format %q", format)
+}
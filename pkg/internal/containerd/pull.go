@@ -0,0 +1,190 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package containerd
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+// PullConfig returns the registry hosts config in dir for pulling image
+// with ctr, which only takes credentials on the command line.
+// Instead the credentials for the registry of image and its mirrors are
+// exchanged for an Authorization header here, with registries using token
+// authentication this is a short lived token scoped to pulling image.
+// The files must only be readable by root.
+func (r *RegistryConfig) PullConfig(dir, image string) (*RegistryConfig, error) {
+	registry, repository := splitImage(image)
+	// the endpoints image may be pulled from, by the host of each
+	endpoints := map[string]string{
+		credentialsHost(registry): "https://" + credentialsHost(registry),
+	}
+	mirrored := false
+	for _, m := range r.mirrors {
+		if m.Registry != registry {
+			continue
+		}
+		mirrored = true
+		for _, endpoint := range m.Endpoints {
+			if u, err := url.Parse(endpoint); err == nil {
+				endpoints[credentialsHost(u.Host)] = strings.TrimSuffix(endpoint, "/")
+			}
+		}
+	}
+	for _, m := range r.mirrors {
+		if m.Registry != "_default" || mirrored {
+			continue
+		}
+		for _, endpoint := range m.Endpoints {
+			if u, err := url.Parse(endpoint); err == nil {
+				endpoints[credentialsHost(u.Host)] = strings.TrimSuffix(endpoint, "/")
+			}
+		}
+	}
+
+	headers := map[string]string{}
+	for host, endpoint := range endpoints {
+		creds, ok := r.credentials[host]
+		if !ok {
+			continue
+		}
+		client, err := r.httpClient(host)
+		if err != nil {
+			return nil, err
+		}
+		header, err := pullAuthorization(client, endpoint, repository, creds)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to authenticate to %s", endpoint)
+		}
+		headers[host] = header
+	}
+	files, err := r.render(dir, headers)
+	if err != nil {
+		return nil, err
+	}
+	return &RegistryConfig{
+		Dir:   dir,
+		Files: files,
+	}, nil
+}
+
+// httpClient returns a client with the TLS config for host
+func (r *RegistryConfig) httpClient(host string) (*http.Client, error) {
+	tlsConfig := &tls.Config{
+		// the user opted out of verifying host
+		InsecureSkipVerify: r.skipVerify[host],
+	}
+	if ca, ok := r.cas[host]; ok {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		pool.AppendCertsFromPEM([]byte(ca))
+		tlsConfig.RootCAs = pool
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	return &http.Client{Transport: transport}, nil
+}
+
+// pullAuthorization returns the Authorization header for pulling
+// repository from the registry at endpoint with creds, following the
+// authentication challenge of the registry
+func pullAuthorization(client *http.Client, endpoint, repository string, creds Credentials) (string, error) {
+	basic := "Basic " + base64.StdEncoding.EncodeToString([]byte(creds.Username+":"+creds.Password))
+	resp, err := client.Get(endpoint + "/v2/")
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		return basic, nil
+	}
+	challenge := resp.Header.Get("Www-Authenticate")
+	if strings.HasPrefix(strings.ToLower(challenge), "basic") {
+		return basic, nil
+	}
+	if !strings.HasPrefix(strings.ToLower(challenge), "bearer ") {
+		return "", errors.Errorf("unsupported authentication challenge %q", challenge)
+	}
+	params := map[string]string{}
+	for _, param := range strings.Split(challenge[len("bearer "):], ",") {
+		kv := strings.SplitN(strings.TrimSpace(param), "=", 2)
+		if len(kv) == 2 {
+			params[strings.ToLower(kv[0])] = strings.Trim(kv[1], `"`)
+		}
+	}
+	if params["realm"] == "" {
+		return "", errors.Errorf("authentication challenge %q has no realm", challenge)
+	}
+	query := url.Values{"scope": []string{"repository:" + repository + ":pull"}}
+	if params["service"] != "" {
+		query.Set("service", params["service"])
+	}
+	req, err := http.NewRequest(http.MethodGet, params["realm"]+"?"+query.Encode(), nil)
+	if err != nil {
+		return "", err
+	}
+	req.SetBasicAuth(creds.Username, creds.Password)
+	resp, err = client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", errors.Errorf("failed to fetch token: %s", resp.Status)
+	}
+	token := struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", errors.Wrap(err, "failed to parse token")
+	}
+	if token.Token == "" {
+		token.Token = token.AccessToken
+	}
+	if token.Token == "" {
+		return "", errors.New("failed to fetch token: no token in response")
+	}
+	return "Bearer " + token.Token, nil
+}
+
+// splitImage returns the registry and repository of image, e.g.
+// docker.io and library/debian for debian:bookworm
+func splitImage(image string) (string, string) {
+	name := strings.SplitN(image, "@", 2)[0]
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name = name[:i]
+	}
+	parts := strings.SplitN(name, "/", 2)
+	if len(parts) == 2 && (strings.ContainsAny(parts[0], ".:") || parts[0] == "localhost") {
+		return parts[0], parts[1]
+	}
+	if !strings.Contains(name, "/") {
+		name = path.Join("library", name)
+	}
+	return "docker.io", name
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package containerd
+
+import (
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestPullConfig(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name string
+		// TokenAuth is true if the registry uses token authentication,
+		// otherwise it accepts basic auth
+		TokenAuth bool
+	}{
+		{
+			Name:      "token authentication",
+			TokenAuth: true,
+		},
+		{
+			Name: "basic authentication",
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			s := newTestRegistry(t, tc.TokenAuth)
+			host := strings.TrimPrefix(s.URL, "https://")
+
+			dir := t.TempDir()
+			caFile := filepath.Join(dir, "ca.pem")
+			if err := os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: s.Certificate().Raw}), 0600); err != nil {
+				t.Fatal(err)
+			}
+			credentialsFile := filepath.Join(dir, "config.json")
+			if err := os.WriteFile(credentialsFile, []byte(fmt.Sprintf(`{"auths": {%q: {"username": "user", "password": "pass"}}}`, host)), 0600); err != nil {
+				t.Fatal(err)
+			}
+			r, err := NewRegistryConfig("/kind/build/certs.d", nil, []config.RegistryAuth{
+				{
+					Host:            host,
+					CAFile:          caFile,
+					CredentialsFile: credentialsFile,
+				},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			pull, err := r.PullConfig("/kind/build/certs.d/pull/test", host+"/team/app:v1")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			hostsFile := pull.Files[path.Join("/kind/build/certs.d/pull/test", host, "hosts.toml")]
+
+			// the rendered header must authenticate the pull
+			match := regexp.MustCompile(`\[header\]\n  Authorization = "(.*)"\n`).FindStringSubmatch(hostsFile)
+			if match == nil {
+				t.Fatalf("expected an Authorization header in hosts config, got: %s", hostsFile)
+			}
+			req, err := http.NewRequest(http.MethodGet, s.URL+"/v2/team/app/manifests/v1", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Authorization", match[1])
+			resp, err := s.Client().Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			assert.DeepEqual(t, http.StatusOK, resp.StatusCode)
+			assert.BoolEqual(t, tc.TokenAuth, strings.HasPrefix(match[1], "Bearer "))
+		})
+	}
+}
+
+func TestSplitImage(t *testing.T) {
+	t.Parallel()
+	cases := map[string][2]string{
+		"debian:bookworm":                         {"docker.io", "library/debian"},
+		"kindest/node:v1.30.0":                    {"docker.io", "kindest/node"},
+		"docker.io/kindest/kindnetd:v20250214":    {"docker.io", "kindest/kindnetd"},
+		"registry.k8s.io/pause:3.10":              {"registry.k8s.io", "pause"},
+		"localhost:5000/app@sha256:0123456789abc": {"localhost:5000", "app"},
+	}
+	for image, expected := range cases {
+		registry, repository := splitImage(image)
+		assert.DeepEqual(t, expected, [2]string{registry, repository})
+	}
+}
+
+// newTestRegistry returns a registry allowing user:pass to pull team/app,
+// with token authentication if tokenAuth is set
+func newTestRegistry(t *testing.T, tokenAuth bool) *httptest.Server {
+	t.Helper()
+	var s *httptest.Server
+	s = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		basicAuthorized := func() bool {
+			username, password, ok := req.BasicAuth()
+			return ok && username == "user" && password == "pass"
+		}
+		switch {
+		case req.URL.Path == "/token" && tokenAuth:
+			if !basicAuthorized() || req.URL.Query().Get("scope") != "repository:team/app:pull" || req.URL.Query().Get("service") != "test-registry" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			fmt.Fprint(w, `{"token": "pull-token"}`)
+		case req.URL.Path == "/v2/team/app/manifests/v1" && tokenAuth && req.Header.Get("Authorization") == "Bearer pull-token":
+			w.WriteHeader(http.StatusOK)
+		case req.URL.Path == "/v2/team/app/manifests/v1" && !tokenAuth && basicAuthorized():
+			w.WriteHeader(http.StatusOK)
+		case tokenAuth:
+			w.Header().Set("Www-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="test-registry"`, s.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+		default:
+			w.Header().Set("Www-Authenticate", `Basic realm="test-registry"`)
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
@@ -0,0 +1,311 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package containerd implements rendering containerd registry host
+// configuration for registry mirrors and auth
+package containerd
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+// HostsDir is where containerd looks up registry host config on the nodes
+// see: https://github.com/containerd/containerd/blob/main/docs/hosts.md
+const HostsDir = "/etc/containerd/certs.d"
+
+// AuthConfigPath is where the containerd config with the registry
+// credentials is written on the nodes, only root may read it
+const AuthConfigPath = "/etc/containerd/registry-auth.toml"
+
+// dockerHubHost is the host containerd actually talks to for docker.io
+const dockerHubHost = "registry-1.docker.io"
+
+// Credentials are the credentials for a registry host
+type Credentials struct {
+	Username string
+	Password string
+}
+
+// RegistryConfig is the rendered containerd configuration for a set of
+// registry mirrors and auth
+type RegistryConfig struct {
+	// Dir is the registry hosts config directory
+	Dir string
+	// Files maps the absolute path of each file to write under Dir to
+	// its contents, these are the hosts.toml and CA bundle files
+	Files map[string]string
+
+	mirrors []config.RegistryMirror
+	// cas are the CA bundles by host
+	cas map[string]string
+	// skipVerify is true for the hosts to skip TLS verification for
+	skipVerify map[string]bool
+	// credentials for each host containerd connects to, they are not
+	// part of Files, see AuthConfigPatch and PullConfig
+	credentials map[string]Credentials
+}
+
+// NewRegistryConfig renders the registry hosts config for mirrors and auth
+// for containerd looking up host config in dir.
+// CA bundles and credentials files referenced by auth are read from the
+// local filesystem.
+func NewRegistryConfig(dir string, mirrors []config.RegistryMirror, auth []config.RegistryAuth) (*RegistryConfig, error) {
+	r := &RegistryConfig{
+		Dir:         dir,
+		mirrors:     mirrors,
+		cas:         map[string]string{},
+		skipVerify:  map[string]bool{},
+		credentials: map[string]Credentials{},
+	}
+	for _, a := range auth {
+		if a.CAFile != "" {
+			ca, err := readCAFile(a.CAFile)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to read CA bundle for %s", a.Host)
+			}
+			r.cas[a.Host] = ca
+		}
+		r.skipVerify[a.Host] = a.InsecureSkipVerify
+		if a.CredentialsFile != "" {
+			creds, err := readCredentialsFile(a.CredentialsFile, a.Host)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to read credentials for %s", a.Host)
+			}
+			r.credentials[credentialsHost(a.Host)] = creds
+		}
+	}
+	files, err := r.render(dir, nil)
+	if err != nil {
+		return nil, err
+	}
+	r.Files = files
+	return r, nil
+}
+
+// render renders the hosts config files in dir, with the Authorization
+// header value in headers for each host containerd connects to, if any
+func (r *RegistryConfig) render(dir string, headers map[string]string) (map[string]string, error) {
+	files := map[string]string{}
+	for host, ca := range r.cas {
+		files[path.Join(dir, host, "ca.crt")] = ca
+	}
+
+	// hosts.toml for every mirrored registry, plus every host with TLS
+	// config or a header so that they also apply when pulling from it
+	// directly
+	hosts := map[string]*strings.Builder{}
+	hostsFile := func(host string) *strings.Builder {
+		if _, ok := hosts[host]; !ok {
+			hosts[host] = &strings.Builder{}
+			hosts[host].WriteString("# generated by kind\n")
+		}
+		return hosts[host]
+	}
+	writeTLS := func(b *strings.Builder, host, indent string) {
+		if _, ok := r.cas[host]; ok {
+			fmt.Fprintf(b, "%sca = %q\n", indent, path.Join(dir, host, "ca.crt"))
+		}
+		if r.skipVerify[host] {
+			fmt.Fprintf(b, "%sskip_verify = true\n", indent)
+		}
+	}
+	writeHeader := func(b *strings.Builder, host, table string) {
+		if header, ok := headers[credentialsHost(host)]; ok {
+			fmt.Fprintf(b, "\n[%s]\n  Authorization = %q\n", table, header)
+		}
+	}
+	for host, skip := range r.skipVerify {
+		if _, ok := r.cas[host]; ok || skip {
+			writeTLS(hostsFile(hostsDirName(host)), host, "")
+		}
+	}
+	for host := range headers {
+		writeHeader(hostsFile(hostsDirName(host)), host, "header")
+	}
+	for _, m := range r.mirrors {
+		b := hostsFile(m.Registry)
+		for _, endpoint := range m.Endpoints {
+			u, err := url.Parse(endpoint)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid endpoint for %s", m.Registry)
+			}
+			fmt.Fprintf(b, "\n[host.%q]\n", endpoint)
+			b.WriteString("  capabilities = [\"pull\", \"resolve\"]\n")
+			writeTLS(b, u.Host, "  ")
+			writeHeader(b, u.Host, fmt.Sprintf("host.%q.header", endpoint))
+		}
+	}
+	for host, b := range hosts {
+		files[path.Join(dir, host, "hosts.toml")] = b.String()
+	}
+	return files, nil
+}
+
+// Empty returns true if there is no registry config to apply
+func (r *RegistryConfig) Empty() bool {
+	return len(r.Files) == 0 && len(r.credentials) == 0
+}
+
+// Paths returns the paths of Files in sorted order
+func (r *RegistryConfig) Paths() []string {
+	paths := make([]string, 0, len(r.Files))
+	for p := range r.Files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// Write writes Files with cmder, only root may read Dir and the files
+func (r *RegistryConfig) Write(cmder exec.Cmder) error {
+	if len(r.Files) == 0 {
+		return nil
+	}
+	if err := cmder.Command("install", "-d", "-m", "0700", r.Dir).Run(); err != nil {
+		return errors.Wrapf(err, "failed to create %s", r.Dir)
+	}
+	for _, p := range r.Paths() {
+		if err := WritePrivateFile(cmder, p, r.Files[p]); err != nil {
+			return errors.Wrapf(err, "failed to write registry config %s", p)
+		}
+	}
+	return nil
+}
+
+// ConfigPatch returns a containerd config patch that enables Dir for the
+// CRI plugin
+func (r *RegistryConfig) ConfigPatch() string {
+	return fmt.Sprintf("[plugins.\"io.containerd.grpc.v1.cri\".registry]\n  config_path = %q\n", r.Dir)
+}
+
+// AuthConfigPatch returns a containerd config patch configuring the
+// registry credentials for the CRI plugin, or "" if there are none.
+// Unlike a static header in the hosts config, the CRI plugin exchanges the
+// credentials for a token with registries using token authentication.
+// The patched config must only be readable by root, see AuthConfigPath.
+func (r *RegistryConfig) AuthConfigPatch() string {
+	hosts := make([]string, 0, len(r.credentials))
+	for host := range r.credentials {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	b := &strings.Builder{}
+	for _, host := range hosts {
+		creds := r.credentials[host]
+		fmt.Fprintf(b, "[plugins.\"io.containerd.grpc.v1.cri\".registry.configs.%q.auth]\n", host)
+		fmt.Fprintf(b, "  username = %q\n  password = %q\n", creds.Username, creds.Password)
+	}
+	return b.String()
+}
+
+// WritePrivateFile writes contents to path with cmder, creating the
+// parent directories as needed, only root may read the file
+func WritePrivateFile(cmder exec.Cmder, path, contents string) error {
+	return cmder.Command(
+		"install", "-D", "-m", "0600", "/dev/stdin", path,
+	).SetStdin(strings.NewReader(contents)).Run()
+}
+
+// hostsDirName returns the name of the hosts config directory for host,
+// containerd looks up docker hub's config by its registry name
+func hostsDirName(host string) string {
+	if credentialsHost(host) == dockerHubHost {
+		return "docker.io"
+	}
+	return host
+}
+
+// credentialsHost returns the host containerd connects to for registry
+func credentialsHost(registry string) string {
+	if registry == "docker.io" || registry == "index.docker.io" {
+		return dockerHubHost
+	}
+	return registry
+}
+
+// readCAFile reads and checks the PEM encoded CA bundle at path
+func readCAFile(path string) (string, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	if !x509.NewCertPool().AppendCertsFromPEM(raw) {
+		return "", errors.Errorf("%s does not contain any PEM encoded certificates", path)
+	}
+	return string(raw), nil
+}
+
+// dockerConfig is the subset of the docker config.json format used for
+// registry credentials
+type dockerConfig struct {
+	Auths map[string]struct {
+		Auth     string `json:"auth"`
+		Username string `json:"username"`
+		Password string `json:"password"`
+	} `json:"auths"`
+}
+
+// readCredentialsFile reads the credentials for host from the docker
+// config.json style file at path
+func readCredentialsFile(path, host string) (Credentials, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return Credentials{}, err
+	}
+	cfg := dockerConfig{}
+	if err := json.Unmarshal(raw, &cfg); err != nil {
+		return Credentials{}, errors.Wrapf(err, "failed to parse %s", path)
+	}
+	for key, entry := range cfg.Auths {
+		if credentialsHost(authKeyHost(key)) != credentialsHost(host) {
+			continue
+		}
+		if entry.Auth == "" {
+			return Credentials{Username: entry.Username, Password: entry.Password}, nil
+		}
+		decoded, err := base64.StdEncoding.DecodeString(entry.Auth)
+		if err != nil {
+			return Credentials{}, errors.Wrapf(err, "invalid auth for %s in %s", key, path)
+		}
+		parts := strings.SplitN(string(decoded), ":", 2)
+		if len(parts) != 2 {
+			return Credentials{}, errors.Errorf("invalid auth for %s in %s", key, path)
+		}
+		return Credentials{Username: parts[0], Password: parts[1]}, nil
+	}
+	return Credentials{}, errors.Errorf("no credentials for %s in %s, credential helpers are not supported", host, path)
+}
+
+// authKeyHost returns the host of a docker config.json auths key, which
+// may be a bare host or a URL like https://index.docker.io/v1/
+func authKeyHost(key string) string {
+	if u, err := url.Parse(key); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return strings.SplitN(key, "/", 2)[0]
+}
@@ -0,0 +1,185 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package containerd
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestNewRegistryConfig(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca.pem")
+	writeTestCA(t, caFile)
+	credentialsFile := filepath.Join(dir, "config.json")
+	// "user:pass" and "hub:secret"
+	if err := os.WriteFile(credentialsFile, []byte(`{"auths": {
+		"mirror.example.com:5000": {"auth": "dXNlcjpwYXNz"},
+		"https://index.docker.io/v1/": {"auth": "aHViOnNlY3JldA=="}
+	}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewRegistryConfig(HostsDir, []config.RegistryMirror{
+		{
+			Registry:  "docker.io",
+			Endpoints: []string{"https://mirror.example.com:5000", "http://fallback.example.com"},
+		},
+	}, []config.RegistryAuth{
+		{
+			Host:            "mirror.example.com:5000",
+			CAFile:          caFile,
+			CredentialsFile: credentialsFile,
+		},
+		{
+			Host:               "insecure.example.com",
+			InsecureSkipVerify: true,
+		},
+		{
+			Host:            "docker.io",
+			CredentialsFile: credentialsFile,
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	assert.StringEqual(t, `# generated by kind
+
+[host."https://mirror.example.com:5000"]
+  capabilities = ["pull", "resolve"]
+  ca = "/etc/containerd/certs.d/mirror.example.com:5000/ca.crt"
+
+[host."http://fallback.example.com"]
+  capabilities = ["pull", "resolve"]
+`, r.Files["/etc/containerd/certs.d/docker.io/hosts.toml"])
+	assert.StringEqual(t, `# generated by kind
+ca = "/etc/containerd/certs.d/mirror.example.com:5000/ca.crt"
+`, r.Files["/etc/containerd/certs.d/mirror.example.com:5000/hosts.toml"])
+	assert.StringEqual(t, `# generated by kind
+skip_verify = true
+`, r.Files["/etc/containerd/certs.d/insecure.example.com/hosts.toml"])
+	if _, ok := r.Files["/etc/containerd/certs.d/mirror.example.com:5000/ca.crt"]; !ok {
+		t.Errorf("expected CA bundle to be written, got files %v", r.Paths())
+	}
+	if len(r.Files) != 4 {
+		t.Errorf("expected 4 files, got %v", r.Paths())
+	}
+
+	// credentials must not end up in the world readable containerd config
+	assert.StringEqual(t, `[plugins."io.containerd.grpc.v1.cri".registry]
+  config_path = "/etc/containerd/certs.d"
+`, r.ConfigPatch())
+	// the CRI plugin exchanges the credentials for tokens as needed
+	assert.StringEqual(t, `[plugins."io.containerd.grpc.v1.cri".registry.configs."mirror.example.com:5000".auth]
+  username = "user"
+  password = "pass"
+[plugins."io.containerd.grpc.v1.cri".registry.configs."registry-1.docker.io".auth]
+  username = "hub"
+  password = "secret"
+`, r.AuthConfigPatch())
+}
+
+func TestNewRegistryConfigErrors(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	notCA := filepath.Join(dir, "not-ca.pem")
+	if err := os.WriteFile(notCA, []byte("bogus"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	noCreds := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(noCreds, []byte(`{"credsStore": "desktop"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		Name string
+		Auth config.RegistryAuth
+	}{
+		{
+			Name: "missing CA file",
+			Auth: config.RegistryAuth{Host: "example.com", CAFile: filepath.Join(dir, "missing.pem")},
+		},
+		{
+			Name: "invalid CA file",
+			Auth: config.RegistryAuth{Host: "example.com", CAFile: notCA},
+		},
+		{
+			Name: "no credentials for host",
+			Auth: config.RegistryAuth{Host: "example.com", CredentialsFile: noCreds},
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			if _, err := NewRegistryConfig(HostsDir, nil, []config.RegistryAuth{tc.Auth}); err == nil {
+				t.Errorf("expected error but got none")
+			}
+		})
+	}
+}
+
+func TestHostsDirName(t *testing.T) {
+	t.Parallel()
+	cases := map[string]string{
+		"docker.io":            "docker.io",
+		"index.docker.io":      "docker.io",
+		"registry-1.docker.io": "docker.io",
+		"registry.k8s.io":      "registry.k8s.io",
+		"localhost:5001":       "localhost:5001",
+	}
+	for host, expected := range cases {
+		assert.StringEqual(t, expected, hostsDirName(host))
+	}
+}
+
+// writeTestCA writes a self-signed PEM encoded certificate to path
+func writeTestCA(t *testing.T, path string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "kind-test-ca"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+}